@@ -14,6 +14,10 @@ func (a *ArrayLiteral) TokenLiteral() string {
 	return a.Token.Literal
 }
 
+func (a *ArrayLiteral) Position() token.Position {
+	return a.Token.Position
+}
+
 func (a *ArrayLiteral) String() string {
 	var out strings.Builder
 
@@ -8,18 +8,24 @@ import (
 type AssignmentStatement struct {
 	Token token.Token
 	Name  *Identifier
-	Value Expression
+	// Operator is set for compound assignments, e.g. + for +=
+	Operator string
+	Value    Expression
 }
 
 func (a *AssignmentStatement) TokenLiteral() string {
 	return a.Token.Literal
 }
 
+func (a *AssignmentStatement) Position() token.Position {
+	return a.Token.Position
+}
+
 func (a *AssignmentStatement) String() string {
 	var out strings.Builder
 
 	out.WriteString(a.Name.String())
-	out.WriteString(" = ")
+	out.WriteString(" " + a.Operator + "= ")
 	out.WriteString(a.Value.String())
 	out.WriteString(";")
 
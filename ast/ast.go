@@ -1,8 +1,13 @@
 package ast
 
+import "taulang/token"
+
 type Node interface {
 	TokenLiteral() string
 	String() string
+
+	// Position is the location in the source of the token the node was built from
+	Position() token.Position
 }
 
 type Statement interface {
@@ -14,6 +14,10 @@ func (b *BlockStatement) TokenLiteral() string {
 	return b.Token.Literal
 }
 
+func (b *BlockStatement) Position() token.Position {
+	return b.Token.Position
+}
+
 func (b *BlockStatement) String() string {
 	var out strings.Builder
 
@@ -11,6 +11,10 @@ func (b *Boolean) TokenLiteral() string {
 	return b.Token.Literal
 }
 
+func (b *Boolean) Position() token.Position {
+	return b.Token.Position
+}
+
 func (b *Boolean) String() string {
 	return b.Token.Literal
 }
@@ -10,6 +10,10 @@ func (b *BreakStatement) TokenLiteral() string {
 	return b.Token.Literal
 }
 
+func (b *BreakStatement) Position() token.Position {
+	return b.Token.Position
+}
+
 func (b *BreakStatement) String() string {
 	return b.TokenLiteral() + ";"
 }
@@ -15,6 +15,10 @@ func (c *CallExpression) TokenLiteral() string {
 	return c.Token.Literal
 }
 
+func (c *CallExpression) Position() token.Position {
+	return c.Token.Position
+}
+
 func (c *CallExpression) String() string {
 	var out strings.Builder
 
@@ -16,6 +16,10 @@ func (c *ConditionalExpression) TokenLiteral() string {
 	return c.Token.Literal
 }
 
+func (c *ConditionalExpression) Position() token.Position {
+	return c.Token.Position
+}
+
 func (c *ConditionalExpression) String() string {
 	var out strings.Builder
 
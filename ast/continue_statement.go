@@ -10,6 +10,10 @@ func (c *ContinueStatement) TokenLiteral() string {
 	return c.Token.Literal
 }
 
+func (c *ContinueStatement) Position() token.Position {
+	return c.Token.Position
+}
+
 func (c *ContinueStatement) String() string {
 	return c.TokenLiteral() + ";"
 }
@@ -0,0 +1,22 @@
+package ast
+
+import "taulang/token"
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (f *FloatLiteral) expressionNode() {}
+
+func (f *FloatLiteral) TokenLiteral() string {
+	return f.Token.Literal
+}
+
+func (f *FloatLiteral) Position() token.Position {
+	return f.Token.Position
+}
+
+func (f *FloatLiteral) String() string {
+	return f.Token.Literal
+}
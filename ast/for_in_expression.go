@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"strings"
+	"taulang/token"
+)
+
+// ForInExpression loops over the elements of Iterable. Variables holds one
+// name, or two when the loop also binds the index or key of every element.
+type ForInExpression struct {
+	Token     token.Token
+	Variables []*Identifier
+	Iterable  Expression
+	Body      *BlockStatement
+}
+
+func (f *ForInExpression) TokenLiteral() string {
+	return f.Token.Literal
+}
+
+func (f *ForInExpression) Position() token.Position {
+	return f.Token.Position
+}
+
+func (f *ForInExpression) String() string {
+	var out strings.Builder
+
+	var variables []string
+	for _, variable := range f.Variables {
+		variables = append(variables, variable.String())
+	}
+
+	out.WriteString("for (")
+	out.WriteString(strings.Join(variables, ", "))
+	out.WriteString(" in ")
+	out.WriteString(f.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(f.Body.String())
+
+	return out.String()
+}
+
+func (f *ForInExpression) expressionNode() {}
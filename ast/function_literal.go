@@ -8,19 +8,38 @@ import (
 type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
-	Body       *BlockStatement
+	// Defaults holds the default value of each parameter in Parameters, with nil
+	// for required ones. It is nil when no parameter has a default.
+	Defaults []Expression
+	// Rest collects the arguments beyond Parameters into an array, e.g. ...rest
+	Rest *Identifier
+	Body *BlockStatement
+	// Name is the identifier a let statement binds the function to, empty for
+	// anonymous functions. It is only used to name the function in stack traces.
+	Name string
 }
 
 func (f *FunctionLiteral) TokenLiteral() string {
 	return f.Token.Literal
 }
 
+func (f *FunctionLiteral) Position() token.Position {
+	return f.Token.Position
+}
+
 func (f *FunctionLiteral) String() string {
 	var out strings.Builder
 
 	var params []string
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
+	for idx, p := range f.Parameters {
+		if f.Defaults != nil && f.Defaults[idx] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[idx].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
 	}
 
 	out.WriteString(f.TokenLiteral())
@@ -19,6 +19,10 @@ func (h *HashLiteral) TokenLiteral() string {
 	return h.Token.Literal
 }
 
+func (h *HashLiteral) Position() token.Position {
+	return h.Token.Position
+}
+
 func (h *HashLiteral) String() string {
 	var out strings.Builder
 
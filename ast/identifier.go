@@ -15,6 +15,10 @@ func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *Identifier) Position() token.Position {
+	return i.Token.Position
+}
+
 func (i *Identifier) String() string {
 	return i.Value
 }
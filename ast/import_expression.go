@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"strings"
+	"taulang/token"
+)
+
+// ImportExpression loads another file and evaluates to its module, e.g.
+// mangwa_lo "./math.tau"
+type ImportExpression struct {
+	Token token.Token
+	Path  Expression
+}
+
+func (i *ImportExpression) TokenLiteral() string {
+	return i.Token.Literal
+}
+
+func (i *ImportExpression) Position() token.Position {
+	return i.Token.Position
+}
+
+func (i *ImportExpression) String() string {
+	var out strings.Builder
+
+	out.WriteString(i.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(i.Path.String())
+
+	return out.String()
+}
+
+func (i *ImportExpression) expressionNode() {}
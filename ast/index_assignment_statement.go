@@ -9,13 +9,19 @@ type IndexAssignmentStatement struct {
 	Token             token.Token
 	IndexedExpression Expression
 	Index             Expression
-	Value             Expression
+	// Operator is set for compound assignments, e.g. + for +=
+	Operator string
+	Value    Expression
 }
 
 func (a *IndexAssignmentStatement) TokenLiteral() string {
 	return a.Token.Literal
 }
 
+func (a *IndexAssignmentStatement) Position() token.Position {
+	return a.Token.Position
+}
+
 func (a *IndexAssignmentStatement) String() string {
 	var out strings.Builder
 
@@ -23,7 +29,7 @@ func (a *IndexAssignmentStatement) String() string {
 	out.WriteString("[")
 	out.WriteString(a.Index.String())
 	out.WriteString("]")
-	out.WriteString(" = ")
+	out.WriteString(" " + a.Operator + "= ")
 	out.WriteString(a.Value.String())
 	out.WriteString(";")
 
@@ -15,6 +15,10 @@ func (i *IndexExpression) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *IndexExpression) Position() token.Position {
+	return i.Token.Position
+}
+
 func (i *IndexExpression) String() string {
 	var out strings.Builder
 
@@ -16,6 +16,10 @@ func (i *InfixExpression) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *InfixExpression) Position() token.Position {
+	return i.Token.Position
+}
+
 func (i *InfixExpression) String() string {
 	var out strings.Builder
 
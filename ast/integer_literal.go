@@ -1,10 +1,15 @@
 package ast
 
-import "taulang/token"
+import (
+	"math/big"
+	"taulang/token"
+)
 
+// IntegerLiteral holds literals too large for an int64 in Big instead of Value
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int
 }
 
 func (i *IntegerLiteral) expressionNode() {}
@@ -13,6 +18,10 @@ func (i *IntegerLiteral) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *IntegerLiteral) Position() token.Position {
+	return i.Token.Position
+}
+
 func (i *IntegerLiteral) String() string {
 	return i.Token.Literal
 }
@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"strings"
+	"taulang/token"
+)
+
+// InterpolatedString is a string with embedded expressions, e.g.
+// $"Namaste {name}, {age + 1} saal ke ho gaye"
+type InterpolatedString struct {
+	Token token.Token
+	// Strings is the text around the expressions, there is always one more
+	// of them than Expressions and they may be empty
+	Strings     []string
+	Expressions []Expression
+}
+
+func (i *InterpolatedString) TokenLiteral() string {
+	return i.Token.Literal
+}
+
+func (i *InterpolatedString) Position() token.Position {
+	return i.Token.Position
+}
+
+func (i *InterpolatedString) String() string {
+	var out strings.Builder
+
+	out.WriteString("$\"")
+	for idx, str := range i.Strings {
+		out.WriteString(str)
+		if idx < len(i.Expressions) {
+			out.WriteString("{")
+			out.WriteString(i.Expressions[idx].String())
+			out.WriteString("}")
+		}
+	}
+	out.WriteString("\"")
+
+	return out.String()
+}
+
+func (i *InterpolatedString) expressionNode() {}
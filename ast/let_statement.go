@@ -18,6 +18,10 @@ func (l *LetStatement) TokenLiteral() string {
 	return l.Token.Literal
 }
 
+func (l *LetStatement) Position() token.Position {
+	return l.Token.Position
+}
+
 func (l *LetStatement) String() string {
 	var out strings.Builder
 
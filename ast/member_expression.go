@@ -0,0 +1,36 @@
+package ast
+
+import (
+	"strings"
+	"taulang/token"
+)
+
+// MemberExpression is the dot access object.member, it is shorthand for
+// indexing with the member name as a string
+type MemberExpression struct {
+	Token  token.Token
+	Object Expression
+	Member *Identifier
+}
+
+func (m *MemberExpression) TokenLiteral() string {
+	return m.Token.Literal
+}
+
+func (m *MemberExpression) Position() token.Position {
+	return m.Token.Position
+}
+
+func (m *MemberExpression) String() string {
+	var out strings.Builder
+
+	out.WriteString("(")
+	out.WriteString(m.Object.String())
+	out.WriteString(".")
+	out.WriteString(m.Member.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (m *MemberExpression) expressionNode() {}
@@ -15,6 +15,10 @@ func (p *PrefixExpression) TokenLiteral() string {
 	return p.Token.Literal
 }
 
+func (p *PrefixExpression) Position() token.Position {
+	return p.Token.Position
+}
+
 func (p *PrefixExpression) String() string {
 	var out strings.Builder
 
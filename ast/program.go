@@ -2,6 +2,7 @@ package ast
 
 import (
 	"strings"
+	"taulang/token"
 )
 
 type Program struct {
@@ -15,6 +16,13 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Position() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Position()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out strings.Builder
 
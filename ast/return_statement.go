@@ -16,6 +16,10 @@ func (r *ReturnStatement) TokenLiteral() string {
 	return r.Token.Literal
 }
 
+func (r *ReturnStatement) Position() token.Position {
+	return r.Token.Position
+}
+
 func (r *ReturnStatement) String() string {
 	var out strings.Builder
 
@@ -0,0 +1,44 @@
+package ast
+
+import (
+	"strings"
+	"taulang/token"
+)
+
+// SliceExpression takes the part of a value between two indexes, e.g. s[1:3],
+// either of which may be left out
+type SliceExpression struct {
+	Token  token.Token
+	Sliced Expression
+	Start  Expression
+	End    Expression
+}
+
+func (s *SliceExpression) TokenLiteral() string {
+	return s.Token.Literal
+}
+
+func (s *SliceExpression) Position() token.Position {
+	return s.Token.Position
+}
+
+func (s *SliceExpression) String() string {
+	var out strings.Builder
+
+	out.WriteString("(")
+	out.WriteString(s.Sliced.String())
+	out.WriteString("[")
+	if s.Start != nil {
+		out.WriteString(s.Start.String())
+	}
+	out.WriteString(":")
+	if s.End != nil {
+		out.WriteString(s.End.String())
+	}
+	out.WriteString("]")
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (s *SliceExpression) expressionNode() {}
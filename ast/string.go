@@ -11,6 +11,10 @@ func (s *String) TokenLiteral() string {
 	return s.Token.Literal
 }
 
+func (s *String) Position() token.Position {
+	return s.Token.Position
+}
+
 func (s *String) String() string {
 	return s.Value
 }
@@ -0,0 +1,31 @@
+package ast
+
+import (
+	"strings"
+	"taulang/token"
+)
+
+type ThrowStatement struct {
+	Token token.Token // the token.THROW token
+	Value Expression
+}
+
+func (t *ThrowStatement) statementNode() {}
+
+func (t *ThrowStatement) TokenLiteral() string {
+	return t.Token.Literal
+}
+
+func (t *ThrowStatement) Position() token.Position {
+	return t.Token.Position
+}
+
+func (t *ThrowStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString("throw ")
+	out.WriteString(t.Value.String())
+	out.WriteString(";")
+
+	return out.String()
+}
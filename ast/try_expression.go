@@ -0,0 +1,48 @@
+package ast
+
+import (
+	"strings"
+	"taulang/token"
+)
+
+// TryExpression evaluates Block and, if it fails, CatchBlock with the error
+// bound to CatchParameter. Finally runs in all cases. At least one of
+// CatchBlock and Finally is set.
+type TryExpression struct {
+	Token          token.Token
+	Block          *BlockStatement
+	CatchParameter *Identifier
+	CatchBlock     *BlockStatement
+	Finally        *BlockStatement
+}
+
+func (t *TryExpression) TokenLiteral() string {
+	return t.Token.Literal
+}
+
+func (t *TryExpression) Position() token.Position {
+	return t.Token.Position
+}
+
+func (t *TryExpression) String() string {
+	var out strings.Builder
+
+	out.WriteString("try ")
+	out.WriteString(t.Block.String())
+
+	if t.CatchBlock != nil {
+		out.WriteString(" catch (")
+		out.WriteString(t.CatchParameter.String())
+		out.WriteString(") ")
+		out.WriteString(t.CatchBlock.String())
+	}
+
+	if t.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(t.Finally.String())
+	}
+
+	return out.String()
+}
+
+func (t *TryExpression) expressionNode() {}
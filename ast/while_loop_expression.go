@@ -15,6 +15,10 @@ func (w *WhileLoopExpression) TokenLiteral() string {
 	return w.Token.Literal
 }
 
+func (w *WhileLoopExpression) Position() token.Position {
+	return w.Token.Position
+}
+
 func (w *WhileLoopExpression) String() string {
 	var out strings.Builder
 
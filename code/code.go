@@ -0,0 +1,289 @@
+package code
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpPop
+	OpTrue
+	OpFalse
+	OpNull
+	// OpDuplicate pushes copies of the values on top of the stack, used by
+	// compound index assignments to read the element they update
+	OpDuplicate
+
+	// operators
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpPow
+	OpBitAnd
+	OpBitOr
+	OpBitXor
+	OpShiftLeft
+	OpShiftRight
+	OpEqual
+	OpNotEqual
+	OpGreaterThan
+	OpGreaterEqual
+	OpLessThan
+	OpLessEqual
+	OpMinus
+	OpBang
+	OpBitNot
+
+	// control flow
+	OpJump
+	OpJumpNotTruthy
+	// OpJumpIfBound jumps when the local has a value, used to skip the default
+	// of a parameter that received an argument
+	OpJumpIfBound
+	// OpIter replaces the value on the stack with an iterator over it, then
+	// OpIterNext pushes the loop variables for the next element, or removes
+	// the iterator and jumps once there are no more elements
+	OpIter
+	OpIterNext
+
+	// bindings
+	OpGetGlobal
+	OpSetGlobal
+	OpGetLocal
+	OpSetLocal
+	OpGetFree
+	OpGetBuiltin
+	// OpAssignGlobal, OpAssignLocal and OpAssignFree update a variable that
+	// must already be bound, unlike the Set variants used for declarations
+	OpAssignGlobal
+	OpAssignLocal
+	OpAssignFree
+	// OpCaptureLocal and OpCaptureFree push the cell holding a variable instead
+	// of its value, so that closures share the variable with their creator
+	OpCaptureLocal
+	OpCaptureFree
+
+	// data structures
+	OpArray
+	OpHash
+	// OpInterpolate joins the string forms of the values on the stack
+	OpInterpolate
+	OpIndex
+	OpSetIndex
+	OpSlice
+
+	// functions
+	OpCall
+	OpReturnValue
+	OpClosure
+
+	// modules
+	OpImport
+
+	// errors
+	// OpSetupCatch and OpSetupFinally register a handler that the vm jumps to
+	// when an error is raised, until it is removed by OpPopHandler
+	OpSetupCatch
+	OpSetupFinally
+	OpPopHandler
+	OpThrow
+)
+
+type Definition struct {
+	Name string
+	// Width in bytes of each operand
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpPop:      {"OpPop", []int{}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+	OpNull:     {"OpNull", []int{}},
+	// number of values to copy
+	OpDuplicate: {"OpDuplicate", []int{1}},
+
+	OpAdd:          {"OpAdd", []int{}},
+	OpSub:          {"OpSub", []int{}},
+	OpMul:          {"OpMul", []int{}},
+	OpDiv:          {"OpDiv", []int{}},
+	OpMod:          {"OpMod", []int{}},
+	OpPow:          {"OpPow", []int{}},
+	OpBitAnd:       {"OpBitAnd", []int{}},
+	OpBitOr:        {"OpBitOr", []int{}},
+	OpBitXor:       {"OpBitXor", []int{}},
+	OpShiftLeft:    {"OpShiftLeft", []int{}},
+	OpShiftRight:   {"OpShiftRight", []int{}},
+	OpEqual:        {"OpEqual", []int{}},
+	OpNotEqual:     {"OpNotEqual", []int{}},
+	OpGreaterThan:  {"OpGreaterThan", []int{}},
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+	OpLessThan:     {"OpLessThan", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+	OpMinus:        {"OpMinus", []int{}},
+	OpBang:         {"OpBang", []int{}},
+	OpBitNot:       {"OpBitNot", []int{}},
+
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+	// local index and jump target
+	OpJumpIfBound: {"OpJumpIfBound", []int{1, 2}},
+	OpIter:        {"OpIter", []int{}},
+	// jump target and number of loop variables
+	OpIterNext: {"OpIterNext", []int{2, 1}},
+
+	OpGetGlobal:    {"OpGetGlobal", []int{2}},
+	OpSetGlobal:    {"OpSetGlobal", []int{2}},
+	OpGetLocal:     {"OpGetLocal", []int{1}},
+	OpSetLocal:     {"OpSetLocal", []int{1}},
+	OpGetFree:      {"OpGetFree", []int{1}},
+	OpGetBuiltin:   {"OpGetBuiltin", []int{1}},
+	OpAssignGlobal: {"OpAssignGlobal", []int{2}},
+	OpAssignLocal:  {"OpAssignLocal", []int{1}},
+	OpAssignFree:   {"OpAssignFree", []int{1}},
+	OpCaptureLocal: {"OpCaptureLocal", []int{1}},
+	OpCaptureFree:  {"OpCaptureFree", []int{1}},
+
+	OpArray:       {"OpArray", []int{2}},
+	OpHash:        {"OpHash", []int{2}},
+	OpInterpolate: {"OpInterpolate", []int{2}},
+	OpIndex:       {"OpIndex", []int{}},
+	OpSetIndex:    {"OpSetIndex", []int{}},
+	OpSlice:       {"OpSlice", []int{}},
+
+	OpCall:        {"OpCall", []int{1}},
+	OpReturnValue: {"OpReturnValue", []int{}},
+	// constant index of the compiled function and number of free variables
+	OpClosure: {"OpClosure", []int{2, 1}},
+
+	OpImport: {"OpImport", []int{}},
+
+	// address of the handler code
+	OpSetupCatch:   {"OpSetupCatch", []int{2}},
+	OpSetupFinally: {"OpSetupFinally", []int{2}},
+	OpPopHandler:   {"OpPopHandler", []int{}},
+	OpThrow:        {"OpThrow", []int{}},
+}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make encodes an instruction, operands are stored big endian
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, w := range def.OperandWidths {
+		instructionLen += w
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for idx, operand := range operands {
+		width := def.OperandWidths[idx]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// OperandFits reports whether the value can be encoded as the operand at the
+// index of the instruction, Make truncates the values that don't fit
+func OperandFits(op Opcode, index int, operand int) bool {
+	def, ok := definitions[op]
+	if !ok || index >= len(def.OperandWidths) {
+		return false
+	}
+	return operand >= 0 && operand < 1<<(8*def.OperandWidths[index])
+}
+
+// ReadOperands decodes the operands of an instruction and returns them along
+// with the number of bytes read
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for idx, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[idx] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[idx] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func ReadUint8(ins Instructions) uint8 {
+	return ins[0]
+}
+
+// String disassembles the instructions, one per line prefixed with its offset
+func (ins Instructions) String() string {
+	var out strings.Builder
+
+	idx := 0
+	for idx < len(ins) {
+		def, err := Lookup(ins[idx])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			idx++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[idx+1:])
+		fmt.Fprintf(&out, "%04d %s\n", idx, ins.formatInstruction(def, operands))
+
+		idx += 1 + read
+	}
+
+	return out.String()
+}
+
+func (ins Instructions) formatInstruction(def *Definition, operands []int) string {
+	if len(operands) != len(def.OperandWidths) {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n", len(operands), len(def.OperandWidths))
+	}
+
+	switch len(operands) {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	}
+
+	return fmt.Sprintf("ERROR: unhandled operand count for %s\n", def.Name)
+}
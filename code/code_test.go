@@ -0,0 +1,112 @@
+package code_test
+
+import (
+	"taulang/code"
+	"taulang/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       code.Opcode
+		operands []int
+		expected []byte
+	}{
+		{
+			name:     "no operands",
+			op:       code.OpAdd,
+			operands: []int{},
+			expected: []byte{byte(code.OpAdd)},
+		},
+		{
+			name:     "two byte operand",
+			op:       code.OpConstant,
+			operands: []int{65534},
+			expected: []byte{byte(code.OpConstant), 255, 254},
+		},
+		{
+			name:     "one byte operand",
+			op:       code.OpGetLocal,
+			operands: []int{255},
+			expected: []byte{byte(code.OpGetLocal), 255},
+		},
+		{
+			name:     "multiple operands",
+			op:       code.OpClosure,
+			operands: []int{65534, 255},
+			expected: []byte{byte(code.OpClosure), 255, 254, 255},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			instruction := code.Make(tc.op, tc.operands...)
+			assert.Equal(t, tc.expected, instruction)
+
+			def, err := code.Lookup(byte(tc.op))
+			assert.NoError(t, err)
+
+			operands, read := code.ReadOperands(def, instruction[1:])
+			assert.Equal(t, len(tc.expected)-1, read)
+			assert.Equal(t, tc.operands, operands)
+		})
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	t.Parallel()
+
+	instructions := code.Instructions{}
+	for _, ins := range [][]byte{
+		code.Make(code.OpAdd),
+		code.Make(code.OpGetLocal, 1),
+		code.Make(code.OpConstant, 2),
+		code.Make(code.OpConstant, 65535),
+		code.Make(code.OpClosure, 65535, 255),
+	} {
+		instructions = append(instructions, ins...)
+	}
+
+	expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpConstant 65535
+0009 OpClosure 65535 255
+`
+	assert.Equal(t, expected, instructions.String())
+}
+
+func TestSourceMapLookup(t *testing.T) {
+	t.Parallel()
+
+	first := token.Position{Line: 1, Column: 1, Offset: 0}
+	second := token.Position{Line: 2, Column: 5, Offset: 12}
+
+	sourceMap := code.SourceMap{}
+	sourceMap = sourceMap.Add(0, first)
+	sourceMap = sourceMap.Add(3, token.Position{})
+	sourceMap = sourceMap.Add(4, second)
+
+	assert.Len(t, sourceMap, 2)
+	assert.Equal(t, first, sourceMap.Lookup(0))
+	assert.Equal(t, first, sourceMap.Lookup(3))
+	assert.Equal(t, second, sourceMap.Lookup(4))
+	assert.Equal(t, second, sourceMap.Lookup(100))
+	assert.Equal(t, token.Position{}, code.SourceMap{}.Lookup(0))
+}
+
+func TestOperandFits(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, code.OperandFits(code.OpConstant, 0, 65535))
+	assert.False(t, code.OperandFits(code.OpConstant, 0, 65536))
+	assert.True(t, code.OperandFits(code.OpClosure, 1, 255))
+	assert.False(t, code.OperandFits(code.OpClosure, 1, 256))
+	assert.False(t, code.OperandFits(code.OpGetLocal, 0, -1))
+	assert.False(t, code.OperandFits(code.OpAdd, 0, 0))
+}
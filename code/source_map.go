@@ -0,0 +1,33 @@
+package code
+
+import (
+	"sort"
+	"taulang/token"
+)
+
+type SourceMapEntry struct {
+	Offset   int
+	Position token.Position
+}
+
+// SourceMap records the source position of the node each instruction was
+// compiled from, entries are sorted by offset
+type SourceMap []SourceMapEntry
+
+func (s SourceMap) Add(offset int, position token.Position) SourceMap {
+	if !position.IsValid() {
+		return s
+	}
+	return append(s, SourceMapEntry{Offset: offset, Position: position})
+}
+
+// Lookup returns the position of the instruction at offset
+func (s SourceMap) Lookup(offset int) token.Position {
+	idx := sort.Search(len(s), func(i int) bool {
+		return s[i].Offset > offset
+	})
+	if idx == 0 {
+		return token.Position{}
+	}
+	return s[idx-1].Position
+}
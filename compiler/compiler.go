@@ -0,0 +1,1034 @@
+package compiler
+
+import (
+	"math"
+	"taulang/ast"
+	"taulang/code"
+	"taulang/diagnostic"
+	"taulang/evaluator"
+	"taulang/object"
+)
+
+// Bytecode is the output of the compiler, the main program is a function that
+// takes no arguments
+type Bytecode struct {
+	MainFunction *object.CompiledFunction
+	Constants    []object.Object
+	// Names of the globals by index, used in error messages
+	GlobalNames []string
+}
+
+type Compiler interface {
+	Compile(program *ast.Program) error
+	Bytecode() *Bytecode
+}
+
+type compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+
+	scopes []*compilationScope
+
+	// err is the first operand that didn't fit in its instruction, reported
+	// once compilation ends since emitting can't fail
+	err error
+}
+
+// compilationScope holds the state of the function currently being compiled
+type compilationScope struct {
+	instructions code.Instructions
+	sourceMap    code.SourceMap
+
+	// Number of values the current statement has pushed on the stack so far,
+	// loops use it to clear the stack before jumping out of the middle of an
+	// expression
+	pending int
+	loops   []*loop
+	// handlers has an entry for every error handler the vm has registered at
+	// the current instruction, innermost last. The entry is the finally block
+	// of a try expression, or nil for the handler of a catch block.
+	handlers []*ast.BlockStatement
+}
+
+type loop struct {
+	start int
+	// stack depth when the loop body starts
+	pending int
+	// stack depth after the loop, below pending when the loop keeps an
+	// iterator on the stack
+	exitPending int
+	// number of error handlers when the loop body starts
+	handlers   int
+	breakJumps []int
+}
+
+var infixOperators = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	"%":  code.OpMod,
+	"**": code.OpPow,
+	"&":  code.OpBitAnd,
+	"|":  code.OpBitOr,
+	"^":  code.OpBitXor,
+	"<<": code.OpShiftLeft,
+	">>": code.OpShiftRight,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+	">":  code.OpGreaterThan,
+	">=": code.OpGreaterEqual,
+	"<":  code.OpLessThan,
+	"<=": code.OpLessEqual,
+}
+
+// operandNames describe what the operands of instructions count or address,
+// for the errors about operands that don't fit
+var operandNames = map[code.Opcode][]string{
+	code.OpConstant:      {"constants"},
+	code.OpDuplicate:     {"values"},
+	code.OpJump:          {"instructions in function"},
+	code.OpJumpNotTruthy: {"instructions in function"},
+	code.OpJumpIfBound:   {"local variables in function", "instructions in function"},
+	code.OpIterNext:      {"instructions in function", "loop variables"},
+	code.OpGetGlobal:     {"global variables"},
+	code.OpSetGlobal:     {"global variables"},
+	code.OpGetLocal:      {"local variables in function"},
+	code.OpSetLocal:      {"local variables in function"},
+	code.OpGetFree:       {"free variables in function"},
+	code.OpGetBuiltin:    {"builtins"},
+	code.OpAssignGlobal:  {"global variables"},
+	code.OpAssignLocal:   {"local variables in function"},
+	code.OpAssignFree:    {"free variables in function"},
+	code.OpCaptureLocal:  {"local variables in function"},
+	code.OpCaptureFree:   {"free variables in function"},
+	code.OpArray:         {"elements in array literal"},
+	code.OpHash:          {"pairs in hash literal"},
+	code.OpInterpolate:   {"parts in string"},
+	code.OpCall:          {"arguments in call"},
+	code.OpClosure:       {"constants", "free variables in function"},
+	code.OpSetupCatch:    {"instructions in function"},
+	code.OpSetupFinally:  {"instructions in function"},
+}
+
+var prefixOperators = map[string]code.Opcode{
+	"-": code.OpMinus,
+	"!": code.OpBang,
+	"~": code.OpBitNot,
+}
+
+func NewCompiler() Compiler {
+	symbolTable := NewSymbolTable()
+	for idx, name := range evaluator.BuiltinNames() {
+		symbolTable.DefineBuiltin(idx, name)
+	}
+
+	return NewCompilerWithState(symbolTable, []object.Object{})
+}
+
+// NewCompilerWithState creates a compiler that continues from the symbols and
+// constants of a previous compilation, as done by the REPL for every input
+func NewCompilerWithState(symbolTable *SymbolTable, constants []object.Object) Compiler {
+	return &compiler{
+		constants:   constants,
+		symbolTable: symbolTable,
+		scopes:      []*compilationScope{{}},
+	}
+}
+
+func (c *compiler) Compile(program *ast.Program) error {
+	if err := c.compileBlock(program.Statements); err != nil {
+		return err
+	}
+	c.emit(program, code.OpReturnValue)
+	return c.err
+}
+
+func (c *compiler) Bytecode() *Bytecode {
+	scope := c.scope()
+	return &Bytecode{
+		MainFunction: &object.CompiledFunction{
+			Instructions: scope.instructions,
+			SourceMap:    scope.sourceMap,
+			NumLocals:    0,
+		},
+		Constants:   c.constants,
+		GlobalNames: c.symbolTable.Global().Names(),
+	}
+}
+
+func (c *compiler) compile(node ast.Node) error {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		return c.compile(node.Expression)
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			c.emit(node, code.OpConstant, c.addConstant(&object.BigInteger{Value: node.Big}))
+			break
+		}
+		c.emit(node, code.OpConstant, c.addConstant(&object.Integer{Value: node.Value}))
+	case *ast.FloatLiteral:
+		c.emit(node, code.OpConstant, c.addConstant(&object.Float{Value: node.Value}))
+	case *ast.String:
+		c.emit(node, code.OpConstant, c.addConstant(&object.String{Value: node.Value}))
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(node, code.OpTrue)
+		} else {
+			c.emit(node, code.OpFalse)
+		}
+	case *ast.PrefixExpression:
+		return c.compilePrefixExpression(node)
+	case *ast.InfixExpression:
+		return c.compileInfixExpression(node)
+	case *ast.ConditionalExpression:
+		return c.compileConditionalExpression(node)
+	case *ast.WhileLoopExpression:
+		return c.compileWhileLoopExpression(node)
+	case *ast.ForInExpression:
+		return c.compileForInExpression(node)
+	case *ast.BreakStatement:
+		return c.compileBreakStatement(node)
+	case *ast.ContinueStatement:
+		return c.compileContinueStatement(node)
+	case *ast.LetStatement:
+		return c.compileLetStatement(node)
+	case *ast.AssignmentStatement:
+		return c.compileAssignmentStatement(node)
+	case *ast.IndexAssignmentStatement:
+		return c.compileIndexAssignmentStatement(node)
+	case *ast.Identifier:
+		c.loadSymbol(node, c.resolve(node.Value))
+	case *ast.ArrayLiteral:
+		return c.compileArrayLiteral(node)
+	case *ast.HashLiteral:
+		return c.compileHashLiteral(node)
+	case *ast.InterpolatedString:
+		return c.compileInterpolatedString(node)
+	case *ast.IndexExpression:
+		return c.compileIndexExpression(node)
+	case *ast.SliceExpression:
+		return c.compileSliceExpression(node)
+	case *ast.MemberExpression:
+		return c.compileMemberExpression(node)
+	case *ast.ImportExpression:
+		if err := c.compile(node.Path); err != nil {
+			return err
+		}
+		c.emit(node, code.OpImport)
+	case *ast.FunctionLiteral:
+		return c.compileFunctionLiteral(node)
+	case *ast.CallExpression:
+		return c.compileCallExpression(node)
+	case *ast.ReturnStatement:
+		if err := c.compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.scope().pending++
+		if err := c.exitHandlers(node, 0); err != nil {
+			return err
+		}
+		c.scope().pending--
+		c.emit(node, code.OpReturnValue)
+	case *ast.TryExpression:
+		return c.compileTryExpression(node)
+	case *ast.ThrowStatement:
+		if err := c.compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(node, code.OpThrow)
+	default:
+		return diagnostic.New(node.Position(), "no defined compilation for input: %s", node.String())
+	}
+
+	return nil
+}
+
+// compileBlock compiles the statements so that they leave exactly one value on
+// the stack, the value of the last statement like the tree walker returns
+func (c *compiler) compileBlock(statements []ast.Statement) error {
+	if len(statements) == 0 {
+		c.emit(nil, code.OpNull)
+		return nil
+	}
+
+	for idx, statement := range statements {
+		if err := c.compile(statement); err != nil {
+			return err
+		}
+
+		isLast := idx == len(statements)-1
+		producesValue := producesValue(statement)
+
+		switch {
+		case isLast && !producesValue:
+			c.emit(statement, code.OpNull)
+		case !isLast && producesValue:
+			c.emit(statement, code.OpPop)
+		}
+	}
+
+	return nil
+}
+
+// producesValue reports whether the compiled statement leaves a value on the stack
+func producesValue(statement ast.Statement) bool {
+	switch statement.(type) {
+	case *ast.LetStatement, *ast.AssignmentStatement, *ast.IndexAssignmentStatement:
+		return false
+	case *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement, *ast.ThrowStatement:
+		// these jump away, whatever follows them in the block is unreachable
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *compiler) compilePrefixExpression(node *ast.PrefixExpression) error {
+	op, ok := prefixOperators[node.Operator]
+	if !ok {
+		return diagnostic.New(node.Position(), "unknown prefix operator: %s", node.Operator)
+	}
+
+	if err := c.compile(node.Operand); err != nil {
+		return err
+	}
+
+	c.emit(node, op)
+	return nil
+}
+
+func (c *compiler) compileInfixExpression(node *ast.InfixExpression) error {
+	if node.Operator == "&&" || node.Operator == "||" {
+		return c.compileLogicalExpression(node)
+	}
+
+	op, ok := infixOperators[node.Operator]
+	if !ok {
+		return diagnostic.New(node.Position(), "unknown operator: %s", node.Operator)
+	}
+
+	if err := c.compile(node.Left); err != nil {
+		return err
+	}
+
+	c.scope().pending++
+	if err := c.compile(node.Right); err != nil {
+		return err
+	}
+	c.scope().pending--
+
+	c.emit(node, op)
+	return nil
+}
+
+// compileLogicalExpression short-circuits like the tree walker, the result is
+// converted to a boolean with a double negation
+func (c *compiler) compileLogicalExpression(node *ast.InfixExpression) error {
+	if err := c.compile(node.Left); err != nil {
+		return err
+	}
+
+	jumpNotTruthyPos := c.emit(node, code.OpJumpNotTruthy, 0)
+
+	var jumpPos int
+	if node.Operator == "&&" {
+		if err := c.compile(node.Right); err != nil {
+			return err
+		}
+		c.emit(node, code.OpBang)
+		c.emit(node, code.OpBang)
+		jumpPos = c.emit(node, code.OpJump, 0)
+
+		c.changeOperands(jumpNotTruthyPos, len(c.scope().instructions))
+		c.emit(node, code.OpFalse)
+	} else {
+		c.emit(node, code.OpTrue)
+		jumpPos = c.emit(node, code.OpJump, 0)
+
+		c.changeOperands(jumpNotTruthyPos, len(c.scope().instructions))
+		if err := c.compile(node.Right); err != nil {
+			return err
+		}
+		c.emit(node, code.OpBang)
+		c.emit(node, code.OpBang)
+	}
+
+	c.changeOperands(jumpPos, len(c.scope().instructions))
+	return nil
+}
+
+func (c *compiler) compileConditionalExpression(node *ast.ConditionalExpression) error {
+	if err := c.compile(node.Condition); err != nil {
+		return err
+	}
+
+	jumpNotTruthyPos := c.emit(node, code.OpJumpNotTruthy, 0)
+
+	if err := c.compileBlock(node.Consequence.Statements); err != nil {
+		return err
+	}
+
+	jumpPos := c.emit(node, code.OpJump, 0)
+	c.changeOperands(jumpNotTruthyPos, len(c.scope().instructions))
+
+	if node.Alternative == nil {
+		c.emit(node, code.OpNull)
+	} else if err := c.compileBlock(node.Alternative.Statements); err != nil {
+		return err
+	}
+
+	c.changeOperands(jumpPos, len(c.scope().instructions))
+	return nil
+}
+
+// compileWhileLoopExpression keeps the value of the loop on the stack below the
+// condition, it is null until the body completes and after break / continue
+func (c *compiler) compileWhileLoopExpression(node *ast.WhileLoopExpression) error {
+	scope := c.scope()
+
+	c.emit(node, code.OpNull)
+	scope.pending++
+
+	start := len(scope.instructions)
+	if err := c.compile(node.Condition); err != nil {
+		return err
+	}
+	jumpNotTruthyPos := c.emit(node, code.OpJumpNotTruthy, 0)
+
+	c.emit(node, code.OpPop)
+	scope.pending--
+
+	l := &loop{start: start, pending: scope.pending, exitPending: scope.pending, handlers: len(scope.handlers)}
+	scope.loops = append(scope.loops, l)
+
+	if err := c.compileBlock(node.Body.Statements); err != nil {
+		return err
+	}
+
+	scope.loops = scope.loops[:len(scope.loops)-1]
+
+	c.emit(node, code.OpJump, start)
+
+	end := len(scope.instructions)
+	c.changeOperands(jumpNotTruthyPos, end)
+	for _, pos := range l.breakJumps {
+		c.changeOperands(pos, end)
+	}
+
+	return nil
+}
+
+// compileForInExpression keeps the iterator on the stack below the value of
+// the loop. OpIterNext replaces the value with the loop variables, which are
+// stored like sun_liyo_tau stores them, and break removes the iterator too.
+func (c *compiler) compileForInExpression(node *ast.ForInExpression) error {
+	scope := c.scope()
+
+	if err := c.compile(node.Iterable); err != nil {
+		return err
+	}
+	c.emit(node, code.OpIter)
+	scope.pending++
+	c.emit(node, code.OpNull)
+
+	start := len(scope.instructions)
+	iterNextPos := c.emit(node, code.OpIterNext, 0, len(node.Variables))
+
+	// the last variable is on top of the stack
+	symbols := make([]Symbol, len(node.Variables))
+	for idx, variable := range node.Variables {
+		symbols[idx] = c.symbolTable.Define(variable.Value)
+	}
+	for idx := len(symbols) - 1; idx >= 0; idx-- {
+		c.storeSymbol(node.Variables[idx], symbols[idx])
+	}
+
+	l := &loop{start: start, pending: scope.pending, exitPending: scope.pending - 1, handlers: len(scope.handlers)}
+	scope.loops = append(scope.loops, l)
+
+	if err := c.compileBlock(node.Body.Statements); err != nil {
+		return err
+	}
+
+	scope.loops = scope.loops[:len(scope.loops)-1]
+	scope.pending--
+
+	c.emit(node, code.OpJump, start)
+
+	end := len(scope.instructions)
+	c.changeOperands(iterNextPos, end, len(node.Variables))
+	for _, pos := range l.breakJumps {
+		c.changeOperands(pos, end)
+	}
+
+	return nil
+}
+
+func (c *compiler) compileBreakStatement(node *ast.BreakStatement) error {
+	l, err := c.currentLoop(node, "break")
+	if err != nil {
+		return err
+	}
+
+	if err := c.exitHandlers(node, l.handlers); err != nil {
+		return err
+	}
+	c.unwindTo(node, l.exitPending)
+	l.breakJumps = append(l.breakJumps, c.emit(node, code.OpJump, 0))
+	return nil
+}
+
+func (c *compiler) compileContinueStatement(node *ast.ContinueStatement) error {
+	l, err := c.currentLoop(node, "continue")
+	if err != nil {
+		return err
+	}
+
+	if err := c.exitHandlers(node, l.handlers); err != nil {
+		return err
+	}
+	c.unwindTo(node, l.pending)
+	c.emit(node, code.OpJump, l.start)
+	return nil
+}
+
+func (c *compiler) currentLoop(node ast.Node, statement string) (*loop, error) {
+	loops := c.scope().loops
+	if len(loops) == 0 {
+		return nil, diagnostic.New(node.Position(), "found %s statement outside of loop", statement)
+	}
+	return loops[len(loops)-1], nil
+}
+
+// unwindTo pops the values pushed since the stack had the depth and pushes the
+// null value of the loop in their place
+func (c *compiler) unwindTo(node ast.Node, depth int) {
+	for range c.scope().pending - depth {
+		c.emit(node, code.OpPop)
+	}
+	c.emit(node, code.OpNull)
+}
+
+// compileTryExpression lays out the handlers so that the value of the try or
+// catch block is the value of the expression:
+//
+//	OpSetupFinally F     (with a finally block)
+//	OpSetupCatch C       (with a catch block)
+//	try block
+//	OpPopHandler, OpJump E
+//	C: catch block       (the vm pushes the exception)
+//	E: OpPopHandler, finally block, OpJump END
+//	F: finally block, OpThrow    (the vm pushes the error)
+//	END:
+func (c *compiler) compileTryExpression(node *ast.TryExpression) error {
+	scope := c.scope()
+
+	var setupFinallyPos int
+	if node.Finally != nil {
+		setupFinallyPos = c.emit(node, code.OpSetupFinally, 0)
+		scope.handlers = append(scope.handlers, node.Finally)
+	}
+
+	if node.CatchBlock == nil {
+		if err := c.compileBlock(node.Block.Statements); err != nil {
+			return err
+		}
+	} else {
+		setupCatchPos := c.emit(node, code.OpSetupCatch, 0)
+		scope.handlers = append(scope.handlers, nil)
+
+		if err := c.compileBlock(node.Block.Statements); err != nil {
+			return err
+		}
+
+		scope.handlers = scope.handlers[:len(scope.handlers)-1]
+		c.emit(node, code.OpPopHandler)
+		jumpPos := c.emit(node, code.OpJump, 0)
+
+		// the catch parameter is only bound in the catch block
+		c.changeOperands(setupCatchPos, len(scope.instructions))
+		c.symbolTable = NewBlockSymbolTable(c.symbolTable)
+		c.storeSymbol(node.CatchParameter, c.symbolTable.Define(node.CatchParameter.Value))
+		err := c.compileBlock(node.CatchBlock.Statements)
+		c.symbolTable = c.symbolTable.Outer
+		if err != nil {
+			return err
+		}
+
+		c.changeOperands(jumpPos, len(scope.instructions))
+	}
+
+	if node.Finally == nil {
+		return nil
+	}
+
+	scope.handlers = scope.handlers[:len(scope.handlers)-1]
+	c.emit(node, code.OpPopHandler)
+	scope.pending++
+	if err := c.compileFinally(node.Finally); err != nil {
+		return err
+	}
+	scope.pending--
+	endJumpPos := c.emit(node, code.OpJump, 0)
+
+	// the finally block of the error path raises the error again when done
+	c.changeOperands(setupFinallyPos, len(scope.instructions))
+	scope.pending++
+	if err := c.compileFinally(node.Finally); err != nil {
+		return err
+	}
+	scope.pending--
+	c.emit(node, code.OpThrow)
+
+	c.changeOperands(endJumpPos, len(scope.instructions))
+	return nil
+}
+
+// compileFinally compiles a finally block, which has no value
+func (c *compiler) compileFinally(finally *ast.BlockStatement) error {
+	if err := c.compileBlock(finally.Statements); err != nil {
+		return err
+	}
+	c.emit(finally, code.OpPop)
+	return nil
+}
+
+// exitHandlers removes the error handlers registered after the first depth
+// ones before jumping out of them, running the finally blocks on the way
+func (c *compiler) exitHandlers(node ast.Node, depth int) error {
+	scope := c.scope()
+	handlers := scope.handlers
+	defer func() {
+		scope.handlers = handlers
+	}()
+
+	for idx := len(handlers) - 1; idx >= depth; idx-- {
+		c.emit(node, code.OpPopHandler)
+		if handlers[idx] == nil {
+			continue
+		}
+
+		// a return in the finally block leaves through the outer handlers only
+		scope.handlers = handlers[:idx]
+		if err := c.compileFinally(handlers[idx]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *compiler) compileLetStatement(node *ast.LetStatement) error {
+	// Functions are bound before compiling them so that they can call themselves
+	if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+		symbol := c.symbolTable.Define(node.Name.Value)
+		if err := c.compile(node.Value); err != nil {
+			return err
+		}
+		c.storeSymbol(node, symbol)
+		return nil
+	}
+
+	if err := c.compile(node.Value); err != nil {
+		return err
+	}
+	c.storeSymbol(node, c.symbolTable.Define(node.Name.Value))
+	return nil
+}
+
+// compileAssignmentStatement updates the variable where it was declared, which
+// makes assignments to variables of enclosing functions go through their cell
+func (c *compiler) compileAssignmentStatement(node *ast.AssignmentStatement) error {
+	symbol := c.resolve(node.Name.Value)
+	if symbol.Scope == BuiltinScope {
+		return diagnostic.New(node.Position(), "cannot assign to undeclared identifier: %s", node.Name.Value)
+	}
+
+	// the variable of a compound assignment is read before the value
+	if node.Operator != "" {
+		c.loadSymbol(node, symbol)
+		c.scope().pending++
+	}
+
+	if err := c.compile(node.Value); err != nil {
+		return err
+	}
+
+	if node.Operator != "" {
+		c.scope().pending--
+		if err := c.emitCompoundOperator(node, node.Operator); err != nil {
+			return err
+		}
+	}
+
+	c.assignSymbol(node, symbol)
+	return nil
+}
+
+// emitCompoundOperator emits the operator of a compound assignment
+func (c *compiler) emitCompoundOperator(node ast.Node, operator string) error {
+	op, ok := infixOperators[operator]
+	if !ok {
+		return diagnostic.New(node.Position(), "unknown operator: %s", operator)
+	}
+	c.emit(node, op)
+	return nil
+}
+
+func (c *compiler) compileIndexAssignmentStatement(node *ast.IndexAssignmentStatement) error {
+	if _, ok := node.IndexedExpression.(*ast.Identifier); !ok {
+		return diagnostic.New(node.Position(), "index assignment only supported for identifiers, got: %s", node.IndexedExpression.String())
+	}
+
+	scope := c.scope()
+
+	if err := c.compile(node.IndexedExpression); err != nil {
+		return err
+	}
+	scope.pending++
+
+	if err := c.compile(node.Index); err != nil {
+		return err
+	}
+	scope.pending++
+
+	// the element of a compound assignment is read before the value
+	if node.Operator != "" {
+		c.emit(node, code.OpDuplicate, 2)
+		c.emit(node, code.OpIndex)
+		scope.pending++
+	}
+
+	if err := c.compile(node.Value); err != nil {
+		return err
+	}
+
+	if node.Operator != "" {
+		scope.pending--
+		if err := c.emitCompoundOperator(node, node.Operator); err != nil {
+			return err
+		}
+	}
+	scope.pending -= 2
+
+	c.emit(node, code.OpSetIndex)
+	return nil
+}
+
+func (c *compiler) compileArrayLiteral(node *ast.ArrayLiteral) error {
+	if err := c.compileExpressions(node.Elements); err != nil {
+		return err
+	}
+
+	c.emit(node, code.OpArray, len(node.Elements))
+	return nil
+}
+
+// compileInterpolatedString pushes the text and the values of the expressions
+// in order, leaving out empty text, and joins them with OpInterpolate
+func (c *compiler) compileInterpolatedString(node *ast.InterpolatedString) error {
+	parts := 0
+	for idx, str := range node.Strings {
+		if str != "" {
+			c.emit(node, code.OpConstant, c.addConstant(&object.String{Value: str}))
+			parts++
+		}
+		if idx < len(node.Expressions) {
+			if err := c.compile(node.Expressions[idx]); err != nil {
+				return err
+			}
+			parts++
+		}
+	}
+
+	c.emit(node, code.OpInterpolate, parts)
+	return nil
+}
+
+func (c *compiler) compileHashLiteral(node *ast.HashLiteral) error {
+	var expressions []ast.Expression
+	for _, pair := range node.Pairs {
+		expressions = append(expressions, pair.Key, pair.Value)
+	}
+
+	if err := c.compileExpressions(expressions); err != nil {
+		return err
+	}
+
+	c.emit(node, code.OpHash, len(node.Pairs))
+	return nil
+}
+
+func (c *compiler) compileIndexExpression(node *ast.IndexExpression) error {
+	if err := c.compile(node.IndexedExpression); err != nil {
+		return err
+	}
+
+	c.scope().pending++
+	if err := c.compile(node.Index); err != nil {
+		return err
+	}
+	c.scope().pending--
+
+	c.emit(node, code.OpIndex)
+	return nil
+}
+
+// compileSliceExpression pushes the sliced value and both bounds, null for a
+// bound that is left out
+func (c *compiler) compileSliceExpression(node *ast.SliceExpression) error {
+	if err := c.compile(node.Sliced); err != nil {
+		return err
+	}
+
+	scope := c.scope()
+	for _, bound := range []ast.Expression{node.Start, node.End} {
+		scope.pending++
+		if bound == nil {
+			c.emit(node, code.OpNull)
+			continue
+		}
+		if err := c.compile(bound); err != nil {
+			return err
+		}
+	}
+	scope.pending -= 2
+
+	c.emit(node, code.OpSlice)
+	return nil
+}
+
+// compileParameterDefaults emits the prologue that evaluates the defaults of
+// parameters without an argument and returns the number of required parameters
+func (c *compiler) compileParameterDefaults(node *ast.FunctionLiteral) (int, error) {
+	numRequired := len(node.Parameters)
+	if node.Defaults == nil {
+		return numRequired, nil
+	}
+
+	for idx, defaultValue := range node.Defaults {
+		if defaultValue == nil {
+			continue
+		}
+		numRequired = min(numRequired, idx)
+
+		jumpIfBoundPos := c.emit(defaultValue, code.OpJumpIfBound, idx, 9999)
+		if err := c.compile(defaultValue); err != nil {
+			return 0, err
+		}
+		c.emit(defaultValue, code.OpSetLocal, idx)
+		c.changeOperands(jumpIfBoundPos, idx, len(c.scope().instructions))
+	}
+
+	return numRequired, nil
+}
+
+// compileMemberExpression compiles object.member as object["member"]
+func (c *compiler) compileMemberExpression(node *ast.MemberExpression) error {
+	if err := c.compile(node.Object); err != nil {
+		return err
+	}
+
+	c.emit(node.Member, code.OpConstant, c.addConstant(&object.String{Value: node.Member.Value}))
+	c.emit(node, code.OpIndex)
+	return nil
+}
+
+func (c *compiler) compileFunctionLiteral(node *ast.FunctionLiteral) error {
+	c.enterScope()
+
+	for _, param := range node.Parameters {
+		c.symbolTable.Define(param.Value)
+	}
+	if node.Rest != nil {
+		c.symbolTable.Define(node.Rest.Value)
+	}
+
+	numRequired, err := c.compileParameterDefaults(node)
+	if err != nil {
+		return err
+	}
+
+	if err := c.compileBlock(node.Body.Statements); err != nil {
+		return err
+	}
+	c.emit(node.Body, code.OpReturnValue)
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.numDefinitions
+	localNames := c.symbolTable.Names()
+	scope := c.leaveScope()
+
+	var freeNames []string
+	for _, symbol := range freeSymbols {
+		c.captureSymbol(node, symbol)
+		freeNames = append(freeNames, symbol.Name)
+	}
+
+	function := &object.CompiledFunction{
+		Instructions:  scope.instructions,
+		SourceMap:     scope.sourceMap,
+		NumLocals:     numLocals,
+		NumParameters: len(node.Parameters),
+		NumRequired:   numRequired,
+		Variadic:      node.Rest != nil,
+		LocalNames:    localNames,
+		FreeNames:     freeNames,
+		Name:          node.Name,
+	}
+
+	c.emit(node, code.OpClosure, c.addConstant(function), len(freeSymbols))
+	return nil
+}
+
+func (c *compiler) compileCallExpression(node *ast.CallExpression) error {
+	if len(node.Arguments) > math.MaxUint8 {
+		return diagnostic.New(node.Position(), "too many arguments in call: %d", len(node.Arguments))
+	}
+
+	if err := c.compile(node.Function); err != nil {
+		return err
+	}
+
+	c.scope().pending++
+	if err := c.compileExpressions(node.Arguments); err != nil {
+		return err
+	}
+	c.scope().pending--
+
+	c.emit(node, code.OpCall, len(node.Arguments))
+	return nil
+}
+
+// compileExpressions pushes the values of the expressions in order
+func (c *compiler) compileExpressions(expressions []ast.Expression) error {
+	scope := c.scope()
+	for _, expression := range expressions {
+		if err := c.compile(expression); err != nil {
+			return err
+		}
+		scope.pending++
+	}
+	scope.pending -= len(expressions)
+	return nil
+}
+
+// resolve finds the symbol for a name, names that are not bound anywhere yet
+// become globals so that they can still be defined before the code runs, e.g.
+// by a later statement or REPL input, else the vm reports them as not found
+func (c *compiler) resolve(name string) Symbol {
+	if symbol, ok := c.symbolTable.Resolve(name); ok {
+		return symbol
+	}
+	c.symbolTable.Global().Define(name)
+
+	symbol, _ := c.symbolTable.Resolve(name)
+	return symbol
+}
+
+func (c *compiler) loadSymbol(node ast.Node, symbol Symbol) {
+	switch symbol.Scope {
+	case GlobalScope:
+		c.emit(node, code.OpGetGlobal, symbol.Index)
+	case LocalScope:
+		c.emit(node, code.OpGetLocal, symbol.Index)
+	case FreeScope:
+		c.emit(node, code.OpGetFree, symbol.Index)
+	case BuiltinScope:
+		c.emit(node, code.OpGetBuiltin, symbol.Index)
+	}
+}
+
+func (c *compiler) storeSymbol(node ast.Node, symbol Symbol) {
+	switch symbol.Scope {
+	case GlobalScope:
+		c.emit(node, code.OpSetGlobal, symbol.Index)
+	case LocalScope:
+		c.emit(node, code.OpSetLocal, symbol.Index)
+	}
+}
+
+// assignSymbol updates an existing variable, the vm reports an error if the
+// variable has not been bound yet
+func (c *compiler) assignSymbol(node ast.Node, symbol Symbol) {
+	switch symbol.Scope {
+	case GlobalScope:
+		c.emit(node, code.OpAssignGlobal, symbol.Index)
+	case LocalScope:
+		c.emit(node, code.OpAssignLocal, symbol.Index)
+	case FreeScope:
+		c.emit(node, code.OpAssignFree, symbol.Index)
+	}
+}
+
+// captureSymbol pushes the cell of a variable of the enclosing function so that
+// a closure can share it
+func (c *compiler) captureSymbol(node ast.Node, symbol Symbol) {
+	switch symbol.Scope {
+	case LocalScope:
+		c.emit(node, code.OpCaptureLocal, symbol.Index)
+	case FreeScope:
+		c.emit(node, code.OpCaptureFree, symbol.Index)
+	}
+}
+
+func (c *compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// emit appends an instruction to the current scope and returns its offset, the
+// position of the node is recorded for error messages
+func (c *compiler) emit(node ast.Node, op code.Opcode, operands ...int) int {
+	scope := c.scope()
+	pos := len(scope.instructions)
+
+	scope.instructions = append(scope.instructions, code.Make(op, operands...)...)
+	if node != nil {
+		scope.sourceMap = scope.sourceMap.Add(pos, node.Position())
+	}
+	c.checkOperands(pos, op, operands)
+
+	return pos
+}
+
+func (c *compiler) changeOperands(pos int, operands ...int) {
+	scope := c.scope()
+	op := code.Opcode(scope.instructions[pos])
+	copy(scope.instructions[pos:], code.Make(op, operands...))
+	c.checkOperands(pos, op, operands)
+}
+
+// checkOperands records an error for the first operand that is too large for
+// its instruction, like the index of the 65537th global
+func (c *compiler) checkOperands(pos int, op code.Opcode, operands []int) {
+	if c.err != nil {
+		return
+	}
+
+	for idx, operand := range operands {
+		if code.OperandFits(op, idx, operand) {
+			continue
+		}
+
+		c.err = diagnostic.New(c.scope().sourceMap.Lookup(pos), "too many %s", operandNames[op][idx])
+		return
+	}
+}
+
+func (c *compiler) scope() *compilationScope {
+	return c.scopes[len(c.scopes)-1]
+}
+
+func (c *compiler) enterScope() {
+	c.scopes = append(c.scopes, &compilationScope{})
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *compiler) leaveScope() *compilationScope {
+	scope := c.scope()
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.symbolTable = c.symbolTable.Outer
+	return scope
+}
@@ -0,0 +1,318 @@
+package compiler_test
+
+import (
+	"fmt"
+	"strings"
+	"taulang/code"
+	"taulang/compiler"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiler(t *testing.T) {
+	tests := []struct {
+		name                 string
+		input                string
+		expectedInstructions []code.Instructions
+		expectedConstants    []object.Object
+	}{
+		{
+			name:  "integer arithmetic",
+			input: "1 + 2;",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}},
+		},
+		{
+			name:  "intermediate values are popped",
+			input: "1; 2;",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}},
+		},
+		{
+			name:  "global bindings",
+			input: "sun_liyo_tau x ne_bana_diye 1; x;",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 1}},
+		},
+		{
+			name:  "conditional with else",
+			input: "agar_maan_lo (saccha) { 10 } na_toh { 20 };",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpJumpNotTruthy, 10),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpJump, 13),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 10}, &object.Integer{Value: 20}},
+		},
+		{
+			name:  "statements without value leave null",
+			input: "sun_liyo_tau x ne_bana_diye 1;",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpNull),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 1}},
+		},
+		{
+			name:  "interpolated string leaves out empty text",
+			input: `$"a{1}{2}";`,
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpInterpolate, 3),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.String{Value: "a"}, &object.Integer{Value: 1}, &object.Integer{Value: 2}},
+		},
+		{
+			name:  "slice with a left out bound",
+			input: `"tau"[1:];`,
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpNull),
+				code.Make(code.OpSlice),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.String{Value: "tau"}, &object.Integer{Value: 1}},
+		},
+		{
+			name:  "try catch finally",
+			input: "koshish_karo { 1 } pakad_lo (e) { e } aakhir_mein { 2 };",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpSetupFinally, 27),
+				code.Make(code.OpSetupCatch, 13),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPopHandler),
+				code.Make(code.OpJump, 19),
+				// catch block
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				// finally block after the try or catch block
+				code.Make(code.OpPopHandler),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+				code.Make(code.OpJump, 32),
+				// finally block when an error is not caught
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpPop),
+				code.Make(code.OpThrow),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}, &object.Integer{Value: 2}},
+		},
+		{
+			name:  "compound index assignment reads the element first",
+			input: "sun_liyo_tau a ne_bana_diye [1]; a[0] += 2;",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpArray, 1),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpDuplicate, 2),
+				code.Make(code.OpIndex),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpAdd),
+				code.Make(code.OpSetIndex),
+				code.Make(code.OpNull),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 0}, &object.Integer{Value: 2}},
+		},
+		{
+			name:  "for-in loop with break",
+			input: "har_ek (k, v mein [1]) { rok_diye; };",
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpArray, 1),
+				code.Make(code.OpIter),
+				code.Make(code.OpNull),
+				code.Make(code.OpIterNext, 27, 2),
+				code.Make(code.OpSetGlobal, 1),
+				code.Make(code.OpSetGlobal, 0),
+				// break pops the iterator too
+				code.Make(code.OpPop),
+				code.Make(code.OpNull),
+				code.Make(code.OpJump, 27),
+				code.Make(code.OpNull),
+				code.Make(code.OpJump, 8),
+				code.Make(code.OpReturnValue),
+			},
+			expectedConstants: []object.Object{&object.Integer{Value: 1}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bytecode := compile(t, tc.input)
+
+			assert.Equal(t, concatInstructions(tc.expectedInstructions).String(), bytecode.MainFunction.Instructions.String())
+			assert.Equal(t, tc.expectedConstants, bytecode.Constants)
+		})
+	}
+}
+
+func TestCompilerClosures(t *testing.T) {
+	t.Parallel()
+
+	bytecode := compile(t, "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a) { tau_ka_jugaad() { a } };")
+
+	expectedMain := concatInstructions([]code.Instructions{
+		code.Make(code.OpClosure, 1, 0),
+		code.Make(code.OpSetGlobal, 0),
+		code.Make(code.OpNull),
+		code.Make(code.OpReturnValue),
+	})
+	assert.Equal(t, expectedMain.String(), bytecode.MainFunction.Instructions.String())
+
+	inner, ok := bytecode.Constants[0].(*object.CompiledFunction)
+	assert.True(t, ok)
+	assert.Equal(t, concatInstructions([]code.Instructions{
+		code.Make(code.OpGetFree, 0),
+		code.Make(code.OpReturnValue),
+	}).String(), inner.Instructions.String())
+	assert.Equal(t, []string{"a"}, inner.FreeNames)
+
+	outer, ok := bytecode.Constants[1].(*object.CompiledFunction)
+	assert.True(t, ok)
+	assert.Equal(t, concatInstructions([]code.Instructions{
+		code.Make(code.OpCaptureLocal, 0),
+		code.Make(code.OpClosure, 0, 1),
+		code.Make(code.OpReturnValue),
+	}).String(), outer.Instructions.String())
+	assert.Equal(t, 1, outer.NumLocals)
+	assert.Equal(t, 1, outer.NumParameters)
+}
+
+func TestCompilerAssignment(t *testing.T) {
+	t.Parallel()
+
+	bytecode := compile(t, `sun_liyo_tau f ne_bana_diye tau_ka_jugaad() {
+	sun_liyo_tau n ne_bana_diye 0;
+	tau_ka_jugaad() { n ne_bana_diye n + 1; };
+};`)
+
+	// the inner function updates the captured cell instead of defining a local
+	inner, ok := bytecode.Constants[2].(*object.CompiledFunction)
+	assert.True(t, ok)
+	assert.Equal(t, concatInstructions([]code.Instructions{
+		code.Make(code.OpGetFree, 0),
+		code.Make(code.OpConstant, 1),
+		code.Make(code.OpAdd),
+		code.Make(code.OpAssignFree, 0),
+		code.Make(code.OpNull),
+		code.Make(code.OpReturnValue),
+	}).String(), inner.Instructions.String())
+	assert.Equal(t, 0, inner.NumLocals)
+}
+
+func TestCompilerErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name:          "break outside of loop",
+			input:         "rok_diye;",
+			expectedError: "1:1: found break statement outside of loop",
+		},
+		{
+			name:          "continue outside of loop",
+			input:         "sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { jaan_de; };",
+			expectedError: "1:47: found continue statement outside of loop",
+		},
+		{
+			name:          "assignment to builtin",
+			input:         "len ne_bana_diye 1;",
+			expectedError: "1:1: cannot assign to undeclared identifier: len",
+		},
+		{
+			name:          "too many globals",
+			input:         declarations(65537),
+			expectedError: "65537:1: too many global variables",
+		},
+		{
+			name:          "too many locals",
+			input:         "tau_ka_jugaad() {\n" + declarations(257) + "};",
+			expectedError: "258:1: too many local variables in function",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			program := p.Parse()
+			assert.Empty(t, p.Errors())
+
+			err = compiler.NewCompiler().Compile(program)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func compile(t *testing.T, input string) *compiler.Bytecode {
+	t.Helper()
+
+	l, err := lexer.NewLexer(input)
+	assert.NoError(t, err)
+
+	p := parser.NewParser(l)
+	program := p.Parse()
+	assert.Empty(t, p.Errors())
+
+	c := compiler.NewCompiler()
+	assert.NoError(t, c.Compile(program))
+
+	return c.Bytecode()
+}
+
+func concatInstructions(instructions []code.Instructions) code.Instructions {
+	out := code.Instructions{}
+	for _, ins := range instructions {
+		out = append(out, ins...)
+	}
+	return out
+}
+
+// declarations declares the variables v0, v1... one per line
+func declarations(count int) string {
+	var out strings.Builder
+	for idx := range count {
+		fmt.Fprintf(&out, "sun_liyo_tau v%d ne_bana_diye saccha;\n", idx)
+	}
+	return out.String()
+}
@@ -0,0 +1,136 @@
+package compiler
+
+type SymbolScope string
+
+const (
+	GlobalScope  SymbolScope = "GLOBAL"
+	LocalScope   SymbolScope = "LOCAL"
+	BuiltinScope SymbolScope = "BUILTIN"
+	FreeScope    SymbolScope = "FREE"
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+// SymbolTable resolves names to slots, there is one table per function being
+// compiled and the outermost one holds the globals and builtins. Blocks with
+// bindings of their own have a table too, which takes its slots from the table
+// of the function around it.
+type SymbolTable struct {
+	Outer *SymbolTable
+	block bool
+
+	store          map[string]Symbol
+	numDefinitions int
+
+	// Symbols of enclosing functions that are referenced by this function,
+	// in the order of their FreeScope indexes
+	FreeSymbols []Symbol
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		store:       map[string]Symbol{},
+		FreeSymbols: []Symbol{},
+	}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+// NewBlockSymbolTable creates the table of a block inside the function of
+// outer, names defined in it shadow the ones of outer until the block ends
+func NewBlockSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	s.block = true
+	return s
+}
+
+// Define binds the name in this table, redefining a name reuses its slot
+func (s *SymbolTable) Define(name string) Symbol {
+	if symbol, ok := s.store[name]; ok && symbol.Scope != BuiltinScope && symbol.Scope != FreeScope {
+		return symbol
+	}
+
+	function := s.function()
+	symbol := Symbol{Name: name, Index: function.numDefinitions}
+	if function.Outer == nil {
+		symbol.Scope = GlobalScope
+	} else {
+		symbol.Scope = LocalScope
+	}
+
+	s.store[name] = symbol
+	function.numDefinitions++
+	return symbol
+}
+
+// function returns the table of the function the table belongs to
+func (s *SymbolTable) function() *SymbolTable {
+	for s.block {
+		s = s.Outer
+	}
+	return s
+}
+
+func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
+	symbol := Symbol{Name: name, Scope: BuiltinScope, Index: index}
+	s.store[name] = symbol
+	return symbol
+}
+
+// Resolve looks the name up in this table and then in the enclosing ones,
+// locals of enclosing functions become free variables of this one
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	symbol, ok := s.store[name]
+	if ok {
+		return symbol, true
+	}
+
+	if s.Outer == nil {
+		return Symbol{}, false
+	}
+
+	// blocks share the slots of their function, so nothing is free in them
+	symbol, ok = s.Outer.Resolve(name)
+	if s.block || !ok || symbol.Scope == GlobalScope || symbol.Scope == BuiltinScope {
+		return symbol, ok
+	}
+
+	return s.defineFree(symbol), true
+}
+
+// Global returns the outermost symbol table
+func (s *SymbolTable) Global() *SymbolTable {
+	if s.Outer == nil {
+		return s
+	}
+	return s.Outer.Global()
+}
+
+// Names returns the names of the symbols defined in this table by index, the
+// slots of symbols defined in blocks have no name
+func (s *SymbolTable) Names() []string {
+	names := make([]string, s.numDefinitions)
+	for _, symbol := range s.store {
+		if symbol.Scope == GlobalScope || symbol.Scope == LocalScope {
+			names[symbol.Index] = symbol.Name
+		}
+	}
+	return names
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Scope: FreeScope, Index: len(s.FreeSymbols) - 1}
+	s.store[original.Name] = symbol
+	return symbol
+}
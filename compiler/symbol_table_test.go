@@ -0,0 +1,79 @@
+package compiler_test
+
+import (
+	"taulang/compiler"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolTable(t *testing.T) {
+	t.Parallel()
+
+	global := compiler.NewSymbolTable()
+	global.DefineBuiltin(0, "len")
+	a := global.Define("a")
+
+	outer := compiler.NewEnclosedSymbolTable(global)
+	b := outer.Define("b")
+
+	inner := compiler.NewEnclosedSymbolTable(outer)
+	c := inner.Define("c")
+
+	assert.Equal(t, compiler.Symbol{Name: "a", Scope: compiler.GlobalScope, Index: 0}, a)
+	assert.Equal(t, compiler.Symbol{Name: "b", Scope: compiler.LocalScope, Index: 0}, b)
+	assert.Equal(t, compiler.Symbol{Name: "c", Scope: compiler.LocalScope, Index: 0}, c)
+
+	tests := []struct {
+		name     string
+		expected compiler.Symbol
+	}{
+		{name: "len", expected: compiler.Symbol{Name: "len", Scope: compiler.BuiltinScope, Index: 0}},
+		{name: "a", expected: compiler.Symbol{Name: "a", Scope: compiler.GlobalScope, Index: 0}},
+		{name: "b", expected: compiler.Symbol{Name: "b", Scope: compiler.FreeScope, Index: 0}},
+		{name: "c", expected: compiler.Symbol{Name: "c", Scope: compiler.LocalScope, Index: 0}},
+	}
+	for _, tc := range tests {
+		symbol, ok := inner.Resolve(tc.name)
+		assert.True(t, ok, tc.name)
+		assert.Equal(t, tc.expected, symbol)
+	}
+
+	assert.Equal(t, []compiler.Symbol{b}, inner.FreeSymbols)
+
+	_, ok := inner.Resolve("d")
+	assert.False(t, ok)
+
+	// redefining reuses the slot, shadowing a builtin takes a new one
+	assert.Equal(t, a, global.Define("a"))
+	assert.Equal(t, compiler.Symbol{Name: "len", Scope: compiler.GlobalScope, Index: 1}, global.Define("len"))
+	assert.Equal(t, []string{"a", "len"}, global.Names())
+}
+
+func TestBlockSymbolTable(t *testing.T) {
+	t.Parallel()
+
+	global := compiler.NewSymbolTable()
+	function := compiler.NewEnclosedSymbolTable(global)
+	e := function.Define("e")
+
+	// blocks take new slots of their function and shadow its names
+	block := compiler.NewBlockSymbolTable(function)
+	shadow := block.Define("e")
+	assert.Equal(t, compiler.Symbol{Name: "e", Scope: compiler.LocalScope, Index: 1}, shadow)
+
+	symbol, ok := block.Resolve("e")
+	assert.True(t, ok)
+	assert.Equal(t, shadow, symbol)
+	symbol, ok = function.Resolve("e")
+	assert.True(t, ok)
+	assert.Equal(t, e, symbol)
+
+	// locals of the function are not free in its blocks
+	x := function.Define("x")
+	symbol, _ = block.Resolve("x")
+	assert.Equal(t, x, symbol)
+	assert.Empty(t, block.FreeSymbols)
+
+	assert.Equal(t, []string{"e", "", "x"}, function.Names())
+}
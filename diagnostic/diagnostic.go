@@ -0,0 +1,96 @@
+package diagnostic
+
+import (
+	"fmt"
+	"strings"
+	"taulang/token"
+	"unicode/utf8"
+)
+
+// Diagnostic is a message attached to a location in the source, used for both
+// parser errors and runtime errors.
+type Diagnostic struct {
+	Position token.Position
+	Message  string
+}
+
+func New(position token.Position, messageTemplate string, args ...any) Diagnostic {
+	return Diagnostic{Position: position, Message: fmt.Sprintf(messageTemplate, args...)}
+}
+
+func (d Diagnostic) Error() string {
+	if !d.Position.IsValid() {
+		return d.Message
+	}
+	return fmt.Sprintf("%s: %s", d.Position, d.Message)
+}
+
+// Render formats the diagnostic together with the offending source line and a
+// caret under the column, e.g.
+//
+//	main.tau:2:29: identifier not found: y
+//	   2 | sun_liyo_tau x ne_bana_diye y;
+//	     |                             ^
+func Render(source string, filename string, d Diagnostic) string {
+	var out strings.Builder
+
+	if filename != "" {
+		out.WriteString(filename)
+		if d.Position.IsValid() {
+			out.WriteString(":")
+		} else {
+			out.WriteString(": ")
+		}
+	}
+	out.WriteString(d.Error())
+
+	if !d.Position.IsValid() {
+		return out.String()
+	}
+
+	line, ok := sourceLine(source, d.Position)
+	if !ok {
+		return out.String()
+	}
+
+	gutter := fmt.Sprintf("%4d | ", d.Position.Line)
+	out.WriteString("\n")
+	out.WriteString(gutter)
+	out.WriteString(line)
+	out.WriteString("\n")
+	out.WriteString(strings.Repeat(" ", len(gutter)-2))
+	out.WriteString("| ")
+	out.WriteString(caretPadding(line, d.Position.Column))
+	out.WriteString("^")
+
+	return out.String()
+}
+
+func sourceLine(source string, position token.Position) (string, bool) {
+	lines := strings.Split(source, "\n")
+	if position.Line > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[position.Line-1], "\r"), true
+}
+
+// caretPadding returns the whitespace that puts a caret under the given
+// 1-based column, reusing tabs from the line so that alignment survives them.
+func caretPadding(line string, column int) string {
+	var padding strings.Builder
+	for idx := 1; idx < column; idx++ {
+		if line == "" {
+			padding.WriteRune(' ')
+			continue
+		}
+
+		r, width := utf8.DecodeRuneInString(line)
+		line = line[width:]
+		if r == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+	return padding.String()
+}
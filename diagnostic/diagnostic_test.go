@@ -0,0 +1,69 @@
+package diagnostic
+
+import (
+	"taulang/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		filename   string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			name:     "caret under column",
+			source:   "sun_liyo_tau x ne_bana_diye 1;\nx + y;",
+			filename: "main.tau",
+			diagnostic: Diagnostic{
+				Position: token.Position{Line: 2, Column: 5, Offset: 35},
+				Message:  "identifier not found: y",
+			},
+			expected: "main.tau:2:5: identifier not found: y\n" +
+				"   2 | x + y;\n" +
+				"     |     ^",
+		},
+		{
+			name:     "tabs are preserved before the caret",
+			source:   "\t\tx + y;",
+			filename: "",
+			diagnostic: Diagnostic{
+				Position: token.Position{Line: 1, Column: 7, Offset: 6},
+				Message:  "identifier not found: y",
+			},
+			expected: "1:7: identifier not found: y\n" +
+				"   1 | \t\tx + y;\n" +
+				"     | \t\t    ^",
+		},
+		{
+			name:     "caret past the end of the line",
+			source:   "foo(",
+			filename: "main.tau",
+			diagnostic: Diagnostic{
+				Position: token.Position{Line: 1, Column: 5, Offset: 4},
+				Message:  "expected next token to be RIGHT_PAREN, got EOF",
+			},
+			expected: "main.tau:1:5: expected next token to be RIGHT_PAREN, got EOF\n" +
+				"   1 | foo(\n" +
+				"     |     ^",
+		},
+		{
+			name:       "no position",
+			source:     "x",
+			filename:   "main.tau",
+			diagnostic: Diagnostic{Message: "something went wrong"},
+			expected:   "main.tau: something went wrong",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, Render(tt.source, tt.filename, tt.diagnostic))
+		})
+	}
+}
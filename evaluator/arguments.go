@@ -0,0 +1,57 @@
+package evaluator
+
+import (
+	"slices"
+	"taulang/object"
+)
+
+// AnyType matches arguments of every type in an ArgumentSpec
+const AnyType object.Type = "ANY"
+
+// ArgumentSpec describes the arguments of a builtin, Check reports calls that
+// don't match it with the same errors as the core builtins
+type ArgumentSpec struct {
+	// Name of the builtin, used in type errors
+	Name     string
+	Required []object.Type
+	Optional []object.Type
+	// Rest is the type of any further arguments, it is empty when there can't
+	// be more arguments
+	Rest object.Type
+}
+
+func (s ArgumentSpec) Check(args []object.Object) *object.Error {
+	required, total := len(s.Required), len(s.Required)+len(s.Optional)
+	switch {
+	case s.Rest != "" && len(args) < required:
+		return newError(object.ArgumentError, "wrong number of arguments. got=%d, want at least %d",
+			len(args), required)
+	case s.Rest == "" && required == total && len(args) != total:
+		return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=%d",
+			len(args), total)
+	case s.Rest == "" && (len(args) < required || len(args) > total):
+		return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=%d to %d",
+			len(args), required, total)
+	}
+
+	types := slices.Concat(s.Required, s.Optional)
+	for idx, arg := range args {
+		want := s.Rest
+		if idx < total {
+			want = types[idx]
+		}
+
+		if want != AnyType && arg.Type() != want {
+			return newError(object.TypeError, "argument %d to `%s` must be %s, got %s",
+				idx+1, s.Name, want, arg.Type())
+		}
+	}
+
+	return nil
+}
+
+// checkArguments checks the number of arguments of the builtin name and that
+// they have the types given in order
+func checkArguments(name string, args []object.Object, types ...object.Type) *object.Error {
+	return ArgumentSpec{Name: name, Required: types}.Check(args)
+}
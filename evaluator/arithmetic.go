@@ -0,0 +1,183 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"taulang/object"
+)
+
+// maxIntegerBits bounds the size of the results of ** and <<, which grow far
+// faster than the memory to hold them
+const maxIntegerBits = 1 << 24
+
+// CheckedAdd returns left + right, and false when the sum doesn't fit in an
+// integer
+func CheckedAdd(left int64, right int64) (int64, bool) {
+	result := left + right
+	return result, (result > left) == (right > 0)
+}
+
+// CheckedSub is CheckedAdd for left - right
+func CheckedSub(left int64, right int64) (int64, bool) {
+	result := left - right
+	return result, (result < left) == (right > 0)
+}
+
+// CheckedMul is CheckedAdd for left * right
+func CheckedMul(left int64, right int64) (int64, bool) {
+	if left == 0 || right == 0 {
+		return 0, true
+	}
+	result := left * right
+	if result/right != left || (left == -1 && right == math.MinInt64) || (right == -1 && left == math.MinInt64) {
+		return result, false
+	}
+	return result, true
+}
+
+// integerPower raises base to a non-negative exponent by squaring
+func integerPower(base int64, exponent int64) (int64, bool) {
+	result := int64(1)
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			var ok bool
+			if result, ok = CheckedMul(result, base); !ok {
+				return 0, false
+			}
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			var ok bool
+			if base, ok = CheckedMul(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// integerShiftLeft reports false when bits are shifted out of the integer,
+// which includes its sign
+func integerShiftLeft(value int64, count int64) (int64, bool) {
+	if value == 0 {
+		return 0, true
+	}
+	if count >= 64 {
+		return 0, false
+	}
+	result := value << count
+	return result, result>>count == value
+}
+
+func overflowError(left object.Object, operator string, right object.Object) *object.Error {
+	return newError(object.OverflowError, "integer overflow: %s %s %s", left.Inspect(), operator, right.Inspect())
+}
+
+// evaluateBigIntegerInfixExpression is evaluateIntegerInfixExpression for
+// operands where one is a BIG_INTEGER or whose result doesn't fit in an INTEGER
+func evaluateBigIntegerInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+
+	switch operator {
+	case "+":
+		return integerObject(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return integerObject(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return integerObject(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError(object.ZeroDivisionError, "division by zero")
+		}
+		return integerObject(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError(object.ZeroDivisionError, "modulo by zero")
+		}
+		return integerObject(new(big.Int).Rem(leftVal, rightVal))
+	case "**":
+		if rightVal.Sign() < 0 {
+			if leftVal.Sign() == 0 {
+				return newError(object.ZeroDivisionError, "zero raised to a negative power")
+			}
+			return &object.Float{Value: math.Pow(toFloat(left).Value, toFloat(right).Value)}
+		}
+
+		// 0, 1 and -1 stay small whatever the exponent, other bases need at
+		// least one more bit for every power
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 &&
+			(!rightVal.IsInt64() || rightVal.Int64() > maxIntegerBits/int64(leftVal.BitLen()-1)) {
+			return overflowError(left, operator, right)
+		}
+		return integerObject(new(big.Int).Exp(leftVal, rightVal, nil))
+	case "&":
+		return integerObject(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return integerObject(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return integerObject(new(big.Int).Xor(leftVal, rightVal))
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError(object.ValueError, "negative shift count: %s", right.Inspect())
+		}
+		if leftVal.Sign() == 0 {
+			return &object.Integer{Value: 0}
+		}
+
+		// shifting right by more than the bits of the value gives 0 or -1
+		fits := rightVal.IsInt64() && rightVal.Int64() <= int64(leftVal.BitLen())
+		if operator == ">>" {
+			count := uint(leftVal.BitLen() + 1)
+			if fits {
+				count = uint(rightVal.Int64())
+			}
+			return integerObject(new(big.Int).Rsh(leftVal, count))
+		}
+
+		if !rightVal.IsInt64() || rightVal.Int64() > maxIntegerBits-int64(leftVal.BitLen()) {
+			return overflowError(left, operator, right)
+		}
+		return integerObject(new(big.Int).Lsh(leftVal, uint(rightVal.Int64())))
+	case "==":
+		return getBoolObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return getBoolObject(leftVal.Cmp(rightVal) != 0)
+	case "<":
+		return getBoolObject(leftVal.Cmp(rightVal) < 0)
+	case "<=":
+		return getBoolObject(leftVal.Cmp(rightVal) <= 0)
+	case ">":
+		return getBoolObject(leftVal.Cmp(rightVal) > 0)
+	case ">=":
+		return getBoolObject(leftVal.Cmp(rightVal) >= 0)
+	default:
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// integerObject returns an INTEGER for values that fit in one and a
+// BIG_INTEGER otherwise
+func integerObject(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIG_INTEGER_OBJ
+}
+
+// toBigInt converts an INTEGER or BIG_INTEGER object to a big.Int, the result
+// may be shared with the object and must not be changed
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.BigInteger:
+		return obj.Value
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,271 @@
+package evaluator
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"taulang/object"
+	"unicode/utf8"
+)
+
+// arrayBuiltins work on arrays, like push they return new arrays rather than
+// changing the ones they are given. Functions passed to them are called with
+// the builtin context.
+var arrayBuiltins = map[string]*object.Builtin{
+	"rest": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("rest", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			if len(elements) == 0 {
+				return NULL
+			}
+			return &object.Array{Elements: slices.Clone(elements[1:])}
+		},
+	},
+	"pop": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("pop", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			if len(elements) == 0 {
+				return NULL
+			}
+			return &object.Array{Elements: slices.Clone(elements[:len(elements)-1])}
+		},
+	},
+	"insert": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=3",
+					len(args))
+			}
+			if err := checkArguments("insert", args[:2], object.ARRAY_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			index := args[1].(*object.Integer).Value
+			position := normalizeIndex(index, len(elements))
+			if position < 0 || position > int64(len(elements)) {
+				return newError(object.IndexError, "insert index out of bounds: %d", index)
+			}
+
+			return &object.Array{Elements: slices.Insert(slices.Clone(elements), int(position), args[2])}
+		},
+	},
+	"reverse": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Array:
+				elements := slices.Clone(arg.Elements)
+				slices.Reverse(elements)
+				return &object.Array{Elements: elements}
+			case *object.String:
+				runes := []rune(arg.Value)
+				slices.Reverse(runes)
+				return &object.String{Value: string(runes)}
+			default:
+				return newError(object.TypeError, "argument to `reverse` must be ARRAY or STRING, got %s",
+					args[0].Type())
+			}
+		},
+	},
+	"contains": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			idx := indexOf("contains", args)
+			if isError(idx) {
+				return idx
+			}
+			return getBoolObject(idx.(*object.Integer).Value != -1)
+		},
+	},
+	"index_of": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			return indexOf("index_of", args)
+		},
+	},
+	"map": &object.Builtin{
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+			if err := checkArguments("map", args[:1], object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			mapped := make([]object.Object, len(elements))
+			for idx, element := range elements {
+				result := ctx.Call(args[1], element)
+				if isError(result) {
+					return result
+				}
+				mapped[idx] = result
+			}
+			return &object.Array{Elements: mapped}
+		},
+	},
+	"filter": &object.Builtin{
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+			if err := checkArguments("filter", args[:1], object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			filtered := []object.Object{}
+			for _, element := range args[0].(*object.Array).Elements {
+				result := ctx.Call(args[1], element)
+				if isError(result) {
+					return result
+				}
+				if IsTruthy(result) {
+					filtered = append(filtered, element)
+				}
+			}
+			return &object.Array{Elements: filtered}
+		},
+	},
+	"reduce": &object.Builtin{
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2 or 3",
+					len(args))
+			}
+			if err := checkArguments("reduce", args[:1], object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			// without an initial value the first element is used
+			elements := args[0].(*object.Array).Elements
+			var accumulator object.Object
+			if len(args) == 3 {
+				accumulator = args[2]
+			} else if len(elements) == 0 {
+				return newError(object.ValueError, "`reduce` of an empty array needs an initial value")
+			} else {
+				accumulator, elements = elements[0], elements[1:]
+			}
+
+			for _, element := range elements {
+				accumulator = ctx.Call(args[1], accumulator, element)
+				if isError(accumulator) {
+					return accumulator
+				}
+			}
+			return accumulator
+		},
+	},
+	"sort": &object.Builtin{
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1 or 2",
+					len(args))
+			}
+			if err := checkArguments("sort", args[:1], object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			compare := compareValues
+			if len(args) == 2 {
+				compare = func(a object.Object, b object.Object) (int, *object.Error) {
+					return callComparator(ctx, args[1], a, b)
+				}
+			}
+
+			// the sort can't be stopped, so comparisons after an error are skipped
+			var sortErr *object.Error
+			sorted := slices.Clone(args[0].(*object.Array).Elements)
+			slices.SortStableFunc(sorted, func(a object.Object, b object.Object) int {
+				if sortErr != nil {
+					return 0
+				}
+				result, err := compare(a, b)
+				sortErr = err
+				return result
+			})
+
+			if sortErr != nil {
+				return sortErr
+			}
+			return &object.Array{Elements: sorted}
+		},
+	},
+}
+
+// indexOf finds a value in an array, or a substring in a string, and returns
+// its index or -1
+func indexOf(name string, args []object.Object) object.Object {
+	if len(args) != 2 {
+		return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2",
+			len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Array:
+		for idx, element := range arg.Elements {
+			if EvalInfixOperator("==", element, args[1]) == TRUE {
+				return &object.Integer{Value: int64(idx)}
+			}
+		}
+		return &object.Integer{Value: -1}
+	case *object.String:
+		sub, ok := args[1].(*object.String)
+		if !ok {
+			return newError(object.TypeError, "argument 2 to `%s` must be STRING, got %s", name, args[1].Type())
+		}
+
+		idx := strings.Index(arg.Value, sub.Value)
+		if idx == -1 {
+			return &object.Integer{Value: -1}
+		}
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value[:idx]))}
+	default:
+		return newError(object.TypeError, "argument 1 to `%s` must be ARRAY or STRING, got %s", name, args[0].Type())
+	}
+}
+
+// compareValues orders numbers and strings, the values sort compares by
+// default
+func compareValues(a object.Object, b object.Object) (int, *object.Error) {
+	switch {
+	case a.Type() == object.INTEGER_OBJ && b.Type() == object.INTEGER_OBJ:
+		return cmp.Compare(a.(*object.Integer).Value, b.(*object.Integer).Value), nil
+	case isInteger(a) && isInteger(b):
+		return toBigInt(a).Cmp(toBigInt(b)), nil
+	case isNumber(a) && isNumber(b):
+		return cmp.Compare(toFloat(a).Value, toFloat(b).Value), nil
+	case a.Type() == object.STRING_OBJ && b.Type() == object.STRING_OBJ:
+		return strings.Compare(a.(*object.String).Value, b.(*object.String).Value), nil
+	default:
+		return 0, newError(object.TypeError, "cannot compare %s with %s, pass a comparator to `sort`", a.Type(), b.Type())
+	}
+}
+
+// callComparator calls a comparator passed to sort, which returns a negative
+// integer when a comes first, a positive one when b does and 0 otherwise
+func callComparator(ctx object.BuiltinContext, comparator object.Object, a object.Object, b object.Object) (int, *object.Error) {
+	result := ctx.Call(comparator, a, b)
+	if err, ok := result.(*object.Error); ok {
+		return 0, err
+	}
+
+	order, ok := result.(*object.Integer)
+	if !ok {
+		return 0, newError(object.TypeError, "comparator passed to `sort` must return INTEGER, got %s", result.Type())
+	}
+	return cmp.Compare(order.Value, 0), nil
+}
@@ -2,38 +2,115 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
 	"taulang/object"
+	"unicode/utf8"
 )
 
+func init() {
+	for _, group := range []map[string]*object.Builtin{stringBuiltins, arrayBuiltins, hashMapBuiltins, systemBuiltins} {
+		for name, builtin := range group {
+			builtins[name] = builtin
+		}
+	}
+
+	for name, builtin := range builtins {
+		builtin.Name = name
+	}
+}
+
+// CheckCapability returns an error when the program of the module context may
+// not call the builtin
+func CheckCapability(builtin *object.Builtin, context *object.ModuleContext) *object.Error {
+	if context == nil || context.Capabilities.Allows(builtin.Capability) {
+		return nil
+	}
+
+	return newError(object.PermissionError, "permission denied: `%s` needs the %s capability",
+		builtin.Name, builtin.Capability)
+}
+
+func LookupBuiltin(name string) (*object.Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+// BuiltinNames returns the names of all builtins in sorted order
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				// counts characters rather than bytes, so "नमस्ते" is 6 long
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.HashMap:
-				return &object.Integer{Value: int64(len(arg.Pairs))}
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Range:
+				return &object.Integer{Value: arg.Len()}
 			default:
-				return newError("argument to `len` not supported, got %s",
+				return newError(object.TypeError, "argument to `len` not supported, got %s",
 					args[0].Type())
 			}
 		},
 	},
+	"range": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1 to 3",
+					len(args))
+			}
+
+			bounds := make([]int64, len(args))
+			for idx, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError(object.TypeError, "argument %d to `range` must be INTEGER, got %s",
+						idx+1, arg.Type())
+				}
+				bounds[idx] = integer.Value
+			}
+
+			// range(end) starts at 0, the step defaults to 1
+			switch len(bounds) {
+			case 1:
+				return &object.Range{Start: 0, End: bounds[0], Step: 1}
+			case 2:
+				return &object.Range{Start: bounds[0], End: bounds[1], Step: 1}
+			}
+			if bounds[2] == 0 {
+				return newError(object.ValueError, "step passed to `range` must not be 0")
+			}
+			return &object.Range{Start: bounds[0], End: bounds[1], Step: bounds[2]}
+		},
+	},
 	"first": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `first` must be ARRAY, got %s",
+				return newError(object.TypeError, "argument to `first` must be ARRAY, got %s",
 					args[0].Type())
 			}
 			arr := args[0].(*object.Array)
@@ -44,13 +121,13 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"last": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `last` must be ARRAY, got %s",
+				return newError(object.TypeError, "argument to `last` must be ARRAY, got %s",
 					args[0].Type())
 			}
 
@@ -64,18 +141,21 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"push": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("wrong number of arguments. got=%d, want=2",
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2",
 					len(args))
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `push` must be ARRAY, got %s",
+				return newError(object.TypeError, "argument to `push` must be ARRAY, got %s",
 					args[0].Type())
 			}
 
 			arr := args[0].(*object.Array)
 			length := len(arr.Elements)
+			if err := ctx.CheckAllocation(int64(length+1) * elementSize); err != nil {
+				return err
+			}
 
 			newElements := make([]object.Object, length+1, length+1)
 			copy(newElements, arr.Elements)
@@ -84,10 +164,77 @@ var builtins = map[string]*object.Builtin{
 			return &object.Array{Elements: newElements}
 		},
 	},
+	"int": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInteger:
+				return arg
+			case *object.Float:
+				// Conversion truncates towards zero like Go does
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+					return newError(object.ValueError, "float %s can't be converted to INTEGER", arg.Inspect())
+				}
+				value, _ := big.NewFloat(arg.Value).Int(nil)
+				return integerObject(value)
+			case *object.String:
+				value, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10)
+				if !ok {
+					return newError(object.ValueError, "could not parse %q as integer", arg.Value)
+				}
+				return integerObject(value)
+			default:
+				return newError(object.TypeError, "argument to `int` not supported, got %s",
+					args[0].Type())
+			}
+		},
+	},
+	"float": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Float:
+				return arg
+			case *object.Integer, *object.BigInteger:
+				return toFloat(arg)
+			case *object.String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return newError(object.ValueError, "could not parse %q as float", arg.Value)
+				}
+				return &object.Float{Value: value}
+			default:
+				return newError(object.TypeError, "argument to `float` not supported, got %s",
+					args[0].Type())
+			}
+		},
+	},
+	"str": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			if str, ok := args[0].(*object.String); ok {
+				return str
+			}
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
 	"print": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Capability: object.PrintCapability,
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(ctx.Streams().Stdout, arg.Inspect())
 			}
 
 			return NULL
@@ -0,0 +1,80 @@
+package evaluator_test
+
+import (
+	"taulang/compiler"
+	"taulang/evaluator"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/parser"
+	"taulang/vm"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVMAgreesWithEvaluator runs the evaluator cases through the compiler and
+// the vm, both engines must produce the same values and error messages
+func TestVMAgreesWithEvaluator(t *testing.T) {
+	for _, tc := range evaluatorTests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// function values differ between the engines by design
+			if containsFunction(tc.expectedObject) {
+				t.Skip("compiled functions are not comparable with evaluator functions")
+			}
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			program := p.Parse()
+			assert.Empty(t, p.Errors())
+
+			var o object.Object
+			c := compiler.NewCompiler()
+			compileErr := c.Compile(program)
+			if compileErr != nil {
+				o = &object.Error{Message: compileErr.Error()}
+			} else {
+				o = vm.NewVM(c.Bytecode()).Run()
+			}
+
+			if expectedError, ok := tc.expectedObject.(*object.Error); ok {
+				actualError, ok := o.(*object.Error)
+				assert.True(t, ok, "expected error, got %s", o.Inspect())
+				if ok {
+					assert.Contains(t, actualError.Message, expectedError.Message)
+				}
+				// the compiler reports some errors early, which don't have a kind
+				if ok && compileErr == nil {
+					evaluatedError, _ := evaluator.Eval(program, object.NewEnvironment()).(*object.Error)
+					assert.Equal(t, evaluatedError.Kind, actualError.Kind)
+				}
+				return
+			}
+
+			assert.Equal(t, tc.expectedObject, o)
+		})
+	}
+}
+
+func containsFunction(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Function:
+		return true
+	case *object.Array:
+		for _, element := range obj.Elements {
+			if containsFunction(element) {
+				return true
+			}
+		}
+	case *object.HashMap:
+		for _, pair := range obj.Pairs {
+			if containsFunction(pair.Key) || containsFunction(pair.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,8 +2,13 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
 	"taulang/ast"
 	"taulang/object"
+	"taulang/token"
 )
 
 var (
@@ -15,13 +20,38 @@ var (
 )
 
 func Eval(node ast.Node, env object.Environment) object.Object {
+	var result object.Object
+	if budget := budgetOf(env); budget != nil {
+		if err := budget.Step(); err != nil {
+			result = err
+		}
+	}
+	if result == nil {
+		result = eval(node, env)
+	}
+
+	// Errors are created deep inside helpers that don't know about the node being
+	// evaluated, so the innermost node that sees an error without a position owns it
+	if err, ok := result.(*object.Error); ok && !err.Position.IsValid() {
+		err.Position = node.Position()
+	}
+
+	return result
+}
+
+func eval(node ast.Node, env object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node.Statements, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInteger{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.Boolean:
 		return getBoolObject(node.Value)
 	case *ast.String:
@@ -41,27 +71,41 @@ func Eval(node ast.Node, env object.Environment) object.Object {
 	case *ast.Identifier:
 		return evalIdentifier(node.Value, env)
 	case *ast.FunctionLiteral:
-		return &object.Function{Params: node.Parameters, Body: node.Body, Env: env}
+		return &object.Function{Params: node.Parameters, Defaults: node.Defaults, Rest: node.Rest, Body: node.Body, Env: env, Name: node.Name}
 	case *ast.CallExpression:
-		return evalCallExpression(node.Function, node.Arguments, env)
+		return evalCallExpression(node, env)
 	case *ast.AssignmentStatement:
-		return evalAssignmentStatement(node.Name, node.Value, env)
+		return evalAssignmentStatement(node, env)
 	case *ast.IndexAssignmentStatement:
 		return evalIndexAssignmentStatement(node, env)
 	case *ast.WhileLoopExpression:
 		return evalWhileLoopExpression(node.Condition, node.Body, env)
+	case *ast.ForInExpression:
+		return evalForInExpression(node, env)
 	case *ast.BreakStatement:
 		return BREAK
 	case *ast.ContinueStatement:
 		return CONTINUE
 	case *ast.ArrayLiteral:
-		return evalArrayLiteral(node.Elements, env)
+		return allocate(env, evalArrayLiteral(node.Elements, env))
 	case *ast.IndexExpression:
 		return evalIndexExpression(node.IndexedExpression, node.Index, env)
+	case *ast.SliceExpression:
+		return allocate(env, evalSliceExpression(node, env))
 	case *ast.HashLiteral:
-		return evalHashLiteral(node.Pairs, env)
+		return allocate(env, evalHashLiteral(node.Pairs, env))
+	case *ast.InterpolatedString:
+		return allocate(env, evalInterpolatedString(node, env))
+	case *ast.MemberExpression:
+		return evalMemberExpression(node.Object, node.Member, env)
+	case *ast.ImportExpression:
+		return evalImportExpression(node.Path, env)
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node.Value, env)
 	default:
-		return newError("no defined evaluations for input: %s", node.String())
+		return newError(object.InternalError, "no defined evaluations for input: %s", node.String())
 	}
 }
 
@@ -74,7 +118,13 @@ func evalIdentifier(identifierName string, env object.Environment) object.Object
 		return obj
 	}
 
-	return newError("identifier not found: %s", identifierName)
+	if context := env.Context(); context != nil && context.Builtins != nil {
+		if obj, ok := context.Builtins.Lookup(identifierName); ok {
+			return obj
+		}
+	}
+
+	return newError(object.NameError, "identifier not found: %s", identifierName)
 }
 
 func evalReturnStatement(returnValue ast.Expression, env object.Environment) object.Object {
@@ -99,11 +149,11 @@ func evalProgram(statements []ast.Statement, env object.Environment) object.Obje
 		}
 
 		if isBreak(result) {
-			return newError("found break statement outside of loop")
+			return newError(object.SyntaxError, "found break statement outside of loop")
 		}
 
 		if isContinue(result) {
-			return newError("found continue statement outside of loop")
+			return newError(object.SyntaxError, "found continue statement outside of loop")
 		}
 	}
 	return result
@@ -122,13 +172,27 @@ func evalPrefixExpression(operator string, operand ast.Expression, env object.En
 		return evaluatedOperand
 	}
 
+	return EvalPrefixOperator(operator, evaluatedOperand)
+}
+
+// EvalPrefixOperator applies a prefix operator to an evaluated operand
+func EvalPrefixOperator(operator string, evaluatedOperand object.Object) object.Object {
 	switch operator {
 	case "-":
 		return evalMinusPrefixOperatorExpression(evaluatedOperand)
 	case "!":
 		return evalBangOperatorExpression(evaluatedOperand)
+	case "~":
+		switch operand := evaluatedOperand.(type) {
+		case *object.Integer:
+			return &object.Integer{Value: ^operand.Value}
+		case *object.BigInteger:
+			return integerObject(new(big.Int).Not(operand.Value))
+		default:
+			return newError(object.TypeError, "unknown operator: ~%s", evaluatedOperand.Type())
+		}
 	default:
-		return newError("unknown prefix expression: %s%s", operator, evaluatedOperand.Type())
+		return newError(object.TypeError, "unknown prefix expression: %s%s", operator, evaluatedOperand.Type())
 	}
 }
 
@@ -140,15 +204,26 @@ func evalBangOperatorExpression(operand object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(operand object.Object) object.Object {
-	if operand.Type() != object.INTEGER_OBJ {
-		return newError("unknown operator: -%s", operand.Type())
+	switch operand := operand.(type) {
+	case *object.Integer:
+		if operand.Value == math.MinInt64 {
+			return integerObject(new(big.Int).Neg(big.NewInt(operand.Value)))
+		}
+		return &object.Integer{Value: -operand.Value}
+	case *object.BigInteger:
+		return integerObject(new(big.Int).Neg(operand.Value))
+	case *object.Float:
+		return &object.Float{Value: -operand.Value}
+	default:
+		return newError(object.TypeError, "unknown operator: -%s", operand.Type())
 	}
-
-	value := operand.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 func evalInfixExpression(operator string, left ast.Expression, right ast.Expression, env object.Environment) object.Object {
+	if operator == "&&" || operator == "||" {
+		return evalLogicalExpression(operator, left, right, env)
+	}
+
 	evaluatedLeft := Eval(left, env)
 	if isError(evaluatedLeft) {
 		return evaluatedLeft
@@ -159,9 +234,20 @@ func evalInfixExpression(operator string, left ast.Expression, right ast.Express
 		return evaluatedRight
 	}
 
+	return evalInfixOperator(operator, evaluatedLeft, evaluatedRight, env)
+}
+
+// EvalInfixOperator applies an infix operator to evaluated operands, the logical
+// operators are not handled here as they need the unevaluated right operand
+func EvalInfixOperator(operator string, evaluatedLeft object.Object, evaluatedRight object.Object) object.Object {
 	switch {
 	case evaluatedLeft.Type() == object.INTEGER_OBJ && evaluatedRight.Type() == object.INTEGER_OBJ:
 		return evaluateIntegerInfixExpression(operator, evaluatedLeft.(*object.Integer), evaluatedRight.(*object.Integer))
+	case isInteger(evaluatedLeft) && isInteger(evaluatedRight):
+		return evaluateBigIntegerInfixExpression(operator, evaluatedLeft, evaluatedRight)
+	// Mixed integer and float operands are promoted to float
+	case isNumber(evaluatedLeft) && isNumber(evaluatedRight):
+		return evaluateFloatInfixExpression(operator, toFloat(evaluatedLeft), toFloat(evaluatedRight))
 	case evaluatedLeft.Type() == object.STRING_OBJ && evaluatedRight.Type() == object.STRING_OBJ:
 		return evaluateStringInfixExpression(operator, evaluatedLeft.(*object.String), evaluatedRight.(*object.String))
 
@@ -173,29 +259,156 @@ func evalInfixExpression(operator string, left ast.Expression, right ast.Express
 		return getBoolObject(evaluatedLeft != evaluatedRight)
 
 	case evaluatedLeft.Type() != evaluatedRight.Type():
-		return newError("type mismatch: %s %s %s", evaluatedLeft.Type(), operator, evaluatedRight.Type())
+		return newError(object.TypeError, "type mismatch: %s %s %s", evaluatedLeft.Type(), operator, evaluatedRight.Type())
 	default:
-		return newError("unknown operator: %s %s %s", evaluatedLeft.Type(), operator, evaluatedRight.Type())
+		return newError(object.TypeError, "unknown operator: %s %s %s", evaluatedLeft.Type(), operator, evaluatedRight.Type())
+	}
+}
+
+// evalLogicalExpression short-circuits, the right operand is only evaluated when
+// the left one doesn't already decide the result
+func evalLogicalExpression(operator string, left ast.Expression, right ast.Expression, env object.Environment) object.Object {
+	evaluatedLeft := Eval(left, env)
+	if isError(evaluatedLeft) {
+		return evaluatedLeft
+	}
+
+	if operator == "&&" && !IsTruthy(evaluatedLeft) {
+		return FALSE
 	}
+
+	if operator == "||" && IsTruthy(evaluatedLeft) {
+		return TRUE
+	}
+
+	evaluatedRight := Eval(right, env)
+	if isError(evaluatedRight) {
+		return evaluatedRight
+	}
+
+	return getBoolObject(IsTruthy(evaluatedRight))
 }
 
+// evaluateIntegerInfixExpression promotes results that don't fit in an
+// INTEGER to a BIG_INTEGER
 func evaluateIntegerInfixExpression(operator string, left *object.Integer, right *object.Integer) object.Object {
 	leftVal := left.Value
 	rightVal := right.Value
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		result, ok := CheckedAdd(leftVal, rightVal)
+		if !ok {
+			return evaluateBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: result}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		result, ok := CheckedSub(leftVal, rightVal)
+		if !ok {
+			return evaluateBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: result}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		result, ok := CheckedMul(leftVal, rightVal)
+		if !ok {
+			return evaluateBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: result}
 	case "/":
 		if rightVal == 0 {
-			return newError("division by zero")
+			return newError(object.ZeroDivisionError, "division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evaluateBigIntegerInfixExpression(operator, left, right)
 		}
 
 		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		// the remainder has the sign of the left operand, like for /
+		if rightVal == 0 {
+			return newError(object.ZeroDivisionError, "modulo by zero")
+		}
+
+		return &object.Integer{Value: leftVal % rightVal}
+	case "**":
+		// a negative exponent gives a fraction, which divides by a zero base
+		if rightVal < 0 {
+			if leftVal == 0 {
+				return newError(object.ZeroDivisionError, "zero raised to a negative power")
+			}
+			return &object.Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
+		}
+
+		result, ok := integerPower(leftVal, rightVal)
+		if !ok {
+			return evaluateBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: result}
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		if rightVal < 0 {
+			return newError(object.ValueError, "negative shift count: %d", rightVal)
+		}
+		if operator == ">>" {
+			return &object.Integer{Value: leftVal >> rightVal}
+		}
+
+		result, ok := integerShiftLeft(leftVal, rightVal)
+		if !ok {
+			return evaluateBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: result}
+	case "==":
+		return getBoolObject(leftVal == rightVal)
+	case "!=":
+		return getBoolObject(leftVal != rightVal)
+	case "<":
+		return getBoolObject(leftVal < rightVal)
+	case "<=":
+		return getBoolObject(leftVal <= rightVal)
+	case ">":
+		return getBoolObject(leftVal > rightVal)
+	case ">=":
+		return getBoolObject(leftVal >= rightVal)
+	default:
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evaluateFloatInfixExpression(operator string, left *object.Float, right *object.Float) object.Object {
+	leftVal := left.Value
+	rightVal := right.Value
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError(object.ZeroDivisionError, "division by zero")
+		}
+
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError(object.ZeroDivisionError, "modulo by zero")
+		}
+
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		if leftVal == 0 && rightVal < 0 {
+			return newError(object.ZeroDivisionError, "zero raised to a negative power")
+		}
+
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case "==":
 		return getBoolObject(leftVal == rightVal)
 	case "!=":
@@ -209,7 +422,7 @@ func evaluateIntegerInfixExpression(operator string, left *object.Integer, right
 	case ">=":
 		return getBoolObject(leftVal >= rightVal)
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -225,7 +438,7 @@ func evaluateStringInfixExpression(operator string, left *object.String, right *
 	case "!=":
 		return getBoolObject(leftVal != rightVal)
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TypeError, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -235,7 +448,7 @@ func evalConditionalExpression(condition ast.Expression, consequence *ast.BlockS
 		return evaluatedCondition
 	}
 
-	if isTruthy(evaluatedCondition) {
+	if IsTruthy(evaluatedCondition) {
 		return Eval(consequence, env)
 	} else if alternative != nil {
 		return Eval(alternative, env)
@@ -266,27 +479,93 @@ func evalLetStatement(name *ast.Identifier, value ast.Expression, env object.Env
 	return NULL
 }
 
-func evalCallExpression(function ast.Expression, arguments []ast.Expression, env object.Environment) object.Object {
-	evaluatedFunc := Eval(function, env)
+func evalCallExpression(node *ast.CallExpression, env object.Environment) object.Object {
+	evaluatedFunc := Eval(node.Function, env)
 	if isError(evaluatedFunc) {
 		return evaluatedFunc
 	}
 
-	evaluatedArgs := evaluateExpression(arguments, env)
+	evaluatedArgs := evaluateExpression(node.Arguments, env)
 	if len(evaluatedArgs) == 1 && isError(evaluatedArgs[0]) {
 		return evaluatedArgs[0]
 	}
 
-	switch funcObj := evaluatedFunc.(type) {
+	return applyFunction(evaluatedFunc, evaluatedArgs, node.Position(), env)
+}
+
+// applyFunction calls fn with the arguments, for a call at callPosition in env
+func applyFunction(fn object.Object, args []object.Object, callPosition token.Position, env object.Environment) object.Object {
+	switch funcObj := fn.(type) {
 	case *object.Function:
-		enclosedEnv := extendEnvAndBindArgs(funcObj, evaluatedArgs, env)
-		result := Eval(funcObj.Body, enclosedEnv)
-		return unwrapReturnValue(result)
+		// the call is entered before binding the arguments, as default
+		// parameters can call functions too
+		if budget := budgetOf(env); budget != nil {
+			if err := budget.Enter(); err != nil {
+				return err
+			}
+			defer budget.Leave()
+		}
+
+		if err := checkArity(funcObj, len(args)); err != nil {
+			return err
+		}
+
+		// errors of default parameters happen inside the function
+		enclosedEnv, err := extendEnvAndBindArgs(funcObj, args)
+		if err != nil {
+			pushStackFrame(err, funcObj, callPosition, env)
+			return err
+		}
+
+		result := unwrapReturnValue(Eval(funcObj.Body, enclosedEnv))
+		if err, ok := result.(*object.Error); ok {
+			pushStackFrame(err, funcObj, callPosition, env)
+		}
+		return result
 	case *object.Builtin:
-		return funcObj.Fn(evaluatedArgs...)
+		if err := CheckCapability(funcObj, env.Context()); err != nil {
+			return err
+		}
+		return allocate(env, funcObj.Fn(&builtinContext{callPosition: callPosition, env: env}, args...))
 	default:
-		return newError("not a function: %s", evaluatedFunc.Type())
+		return newError(object.TypeError, "not a function: %s", fn.Type())
+	}
+}
+
+// builtinContext lets builtins call functions, the calls are reported in
+// stack traces as made where the builtin was called
+type builtinContext struct {
+	callPosition token.Position
+	env          object.Environment
+}
+
+func (c *builtinContext) Call(fn object.Object, args ...object.Object) object.Object {
+	return applyFunction(fn, args, c.callPosition, c.env)
+}
+
+func (c *builtinContext) Streams() *object.Streams {
+	return c.env.Context().ProgramStreams()
+}
+
+func (c *builtinContext) CheckAllocation(size int64) *object.Error {
+	return checkAllocation(c.env, size)
+}
+
+// pushStackFrame records in the stack of err that it left function, which was
+// called at callPosition in env
+func pushStackFrame(err *object.Error, function *object.Function, callPosition token.Position, env object.Environment) {
+	if len(err.Stack) == 0 {
+		err.Stack = []object.StackFrame{{Filename: filename(function.Env), Position: err.Position}}
 	}
+	err.Stack[len(err.Stack)-1].Function = function.Name
+	err.Stack = append(err.Stack, object.StackFrame{Filename: filename(env), Position: callPosition})
+}
+
+func filename(env object.Environment) string {
+	if context := env.Context(); context != nil {
+		return context.Filename
+	}
+	return ""
 }
 
 func evaluateExpression(expressions []ast.Expression, env object.Environment) []object.Object {
@@ -301,14 +580,68 @@ func evaluateExpression(expressions []ast.Expression, env object.Environment) []
 	return evaluatedArgs
 }
 
-func extendEnvAndBindArgs(function *object.Function, args []object.Object, env object.Environment) object.Environment {
-	enclosedEnv := object.NewEnclosedEnvironment(env)
+// extendEnvAndBindArgs encloses the environment the function was defined in,
+// so that its body sees the bindings of its definition site and not of the caller.
+// Defaults are evaluated in the new environment so they can refer to earlier
+// parameters.
+func extendEnvAndBindArgs(function *object.Function, args []object.Object) (object.Environment, *object.Error) {
+	enclosedEnv := object.NewEnclosedEnvironment(function.Env)
 
+	// All parameters are in scope in the defaults, the ones whose default has
+	// not been evaluated yet are declared without a value so that they are
+	// not found instead of resolving to outer variables
 	for idx, param := range function.Params {
-		enclosedEnv.Set(param.Value, args[idx])
+		if idx < len(args) {
+			enclosedEnv.Set(param.Value, args[idx])
+		} else {
+			enclosedEnv.Set(param.Value, nil)
+		}
+	}
+
+	if function.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(function.Params) {
+			rest = append(rest, args[len(function.Params):]...)
+		}
+		enclosedEnv.Set(function.Rest.Value, &object.Array{Elements: rest})
+	}
+
+	for idx := len(args); idx < len(function.Params); idx++ {
+		value := Eval(function.Defaults[idx], enclosedEnv)
+		if err, ok := value.(*object.Error); ok {
+			return nil, err
+		}
+		enclosedEnv.Set(function.Params[idx].Value, value)
+	}
+
+	return enclosedEnv, nil
+}
+
+func checkArity(function *object.Function, numArgs int) *object.Error {
+	required := len(function.Params)
+	for idx := range function.Params {
+		if function.Defaults != nil && function.Defaults[idx] != nil {
+			required = idx
+			break
+		}
 	}
 
-	return enclosedEnv
+	return ArityError(required, len(function.Params), function.Rest != nil, numArgs)
+}
+
+// ArityError returns an error if numArgs arguments can't be passed to a function
+// with the given number of required and total parameters
+func ArityError(required int, total int, variadic bool, numArgs int) *object.Error {
+	switch {
+	case numArgs >= required && (variadic || numArgs <= total):
+		return nil
+	case variadic:
+		return newError(object.ArgumentError, "wrong number of arguments: expected at least %d, got %d", required, numArgs)
+	case required != total:
+		return newError(object.ArgumentError, "wrong number of arguments: expected %d to %d, got %d", required, total, numArgs)
+	default:
+		return newError(object.ArgumentError, "wrong number of arguments: expected %d, got %d", total, numArgs)
+	}
 }
 
 func evalWhileLoopExpression(condition ast.Expression, body *ast.BlockStatement, env object.Environment) object.Object {
@@ -319,7 +652,7 @@ func evalWhileLoopExpression(condition ast.Expression, body *ast.BlockStatement,
 			return evaluatedCondition
 		}
 
-		isConditionTruthy := isTruthy(evaluatedCondition)
+		isConditionTruthy := IsTruthy(evaluatedCondition)
 		if !isConditionTruthy {
 			break
 		}
@@ -340,13 +673,32 @@ func evalWhileLoopExpression(condition ast.Expression, body *ast.BlockStatement,
 	return result
 }
 
-func evalAssignmentStatement(name *ast.Identifier, value ast.Expression, env object.Environment) object.Object {
-	evaluatedValue := Eval(value, env)
+// evalAssignmentStatement reads the variable of a compound assignment before
+// evaluating the value, x += f() adds to the value x had before calling f
+func evalAssignmentStatement(node *ast.AssignmentStatement, env object.Environment) object.Object {
+	var current object.Object
+	if node.Operator != "" {
+		current = evalIdentifier(node.Name.Value, env)
+		if isError(current) {
+			return current
+		}
+	}
+
+	evaluatedValue := Eval(node.Value, env)
 	if isError(evaluatedValue) {
 		return evaluatedValue
 	}
 
-	env.Set(name.Value, evaluatedValue)
+	if node.Operator != "" {
+		evaluatedValue = evalInfixOperator(node.Operator, current, evaluatedValue, env)
+		if isError(evaluatedValue) {
+			return evaluatedValue
+		}
+	}
+
+	if _, ok := env.Assign(node.Name.Value, evaluatedValue); !ok {
+		return newError(object.NameError, "cannot assign to undeclared identifier: %s", node.Name.Value)
+	}
 
 	return NULL
 }
@@ -354,12 +706,12 @@ func evalAssignmentStatement(name *ast.Identifier, value ast.Expression, env obj
 func evalIndexAssignmentStatement(node *ast.IndexAssignmentStatement, env object.Environment) object.Object {
 	identifier, ok := node.IndexedExpression.(*ast.Identifier)
 	if !ok {
-		return newError("index assignment only supported for identifiers, got: %s", node.IndexedExpression.String())
+		return newError(object.SyntaxError, "index assignment only supported for identifiers, got: %s", node.IndexedExpression.String())
 	}
 
 	indexedObject, ok := env.Get(identifier.Value)
 	if !ok {
-		return newError("identifier not found: %s", identifier.Value)
+		return newError(object.NameError, "identifier not found: %s", identifier.Value)
 	}
 
 	evaluatedIndex := Eval(node.Index, env)
@@ -367,30 +719,50 @@ func evalIndexAssignmentStatement(node *ast.IndexAssignmentStatement, env object
 		return evaluatedIndex
 	}
 
+	var current object.Object
+	if node.Operator != "" {
+		current = EvalIndex(indexedObject, evaluatedIndex)
+		if isError(current) {
+			return current
+		}
+	}
+
 	evaluatedValue := Eval(node.Value, env)
 	if isError(evaluatedValue) {
 		return evaluatedValue
 	}
 
+	if node.Operator != "" {
+		evaluatedValue = evalInfixOperator(node.Operator, current, evaluatedValue, env)
+		if isError(evaluatedValue) {
+			return evaluatedValue
+		}
+	}
+
+	return AssignIndexWithBudget(indexedObject, evaluatedIndex, evaluatedValue, budgetOf(env))
+}
+
+// AssignIndex updates an array or hash map in place
+func AssignIndex(indexedObject object.Object, index object.Object, value object.Object) object.Object {
 	switch obj := indexedObject.(type) {
 	case *object.Array:
-		return evalArrayIndexAssignment(identifier.Value, obj, evaluatedIndex, evaluatedValue, env)
+		return evalArrayIndexAssignment(obj, index, value)
 	case *object.HashMap:
-		return evalHashIndexAssignment(identifier.Value, obj, evaluatedIndex, evaluatedValue, env)
+		return evalHashIndexAssignment(obj, index, value)
 	default:
-		return newError("index assignment not supported for type: %s", indexedObject.Type())
+		return newError(object.TypeError, "index assignment not supported for type: %s", indexedObject.Type())
 	}
 }
 
-func evalArrayIndexAssignment(name string, array *object.Array, index object.Object, value object.Object, env object.Environment) object.Object {
+func evalArrayIndexAssignment(array *object.Array, index object.Object, value object.Object) object.Object {
 	indexInt, ok := index.(*object.Integer)
 	if !ok {
-		return newError("array index must be an integer, got: %s", index.Type())
+		return newError(object.TypeError, "array index must be an integer, got: %s", index.Type())
 	}
 
-	indexVal := indexInt.Value
+	indexVal := normalizeIndex(indexInt.Value, len(array.Elements))
 	if indexVal < 0 {
-		return newError("array index out of bounds: %d", indexVal)
+		return newError(object.IndexError, "array index out of bounds: %d", indexInt.Value)
 	}
 
 	// Extend array if necessary
@@ -403,35 +775,46 @@ func evalArrayIndexAssignment(name string, array *object.Array, index object.Obj
 
 	array.Elements[indexVal] = value
 
-	// Store the modified array back in the environment
-	env.Set(name, array)
-
 	return NULL
 }
 
-func evalHashIndexAssignment(name string, hashMap *object.HashMap, index object.Object, value object.Object, env object.Environment) object.Object {
+func evalHashIndexAssignment(hashMap *object.HashMap, index object.Object, value object.Object) object.Object {
 	hashKey, ok := index.(object.Hashable)
 	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
+		return newError(object.TypeError, "unusable as hash key: %s", index.Type())
 	}
 
-	hashMap.Pairs[hashKey.Hash()] = object.HashPair{Key: index, Value: value}
-
-	// Store the modified hashmap back in the environment
-	env.Set(name, hashMap)
+	hashMap.Set(hashKey.Hash(), object.HashPair{Key: index, Value: value})
 
 	return NULL
 }
 
 func evalArrayLiteral(elements []ast.Expression, env object.Environment) object.Object {
 	evaluatedElements := evaluateExpression(elements, env)
-	if len(elements) == 1 && isError(evaluatedElements[0]) {
+	if len(evaluatedElements) == 1 && isError(evaluatedElements[0]) {
 		return evaluatedElements[0]
 	}
 
 	return &object.Array{Elements: evaluatedElements}
 }
 
+// evalInterpolatedString joins the text with the string forms of the values
+func evalInterpolatedString(node *ast.InterpolatedString, env object.Environment) object.Object {
+	var out strings.Builder
+	for idx, str := range node.Strings {
+		out.WriteString(str)
+		if idx < len(node.Expressions) {
+			value := Eval(node.Expressions[idx], env)
+			if isError(value) {
+				return value
+			}
+			out.WriteString(value.Inspect())
+		}
+	}
+
+	return &object.String{Value: out.String()}
+}
+
 func evalIndexExpression(expression ast.Expression, index ast.Expression, env object.Environment) object.Object {
 	evaluatedIndexedObject := Eval(expression, env)
 	if isError(evaluatedIndexedObject) {
@@ -443,19 +826,30 @@ func evalIndexExpression(expression ast.Expression, index ast.Expression, env ob
 		return evaluatedIndex
 	}
 
+	return EvalIndex(evaluatedIndexedObject, evaluatedIndex)
+}
+
+// EvalIndex evaluates the index operator on evaluated operands
+func EvalIndex(evaluatedIndexedObject object.Object, evaluatedIndex object.Object) object.Object {
 	switch {
 	case evaluatedIndexedObject.Type() == object.ARRAY_OBJ && evaluatedIndex.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(evaluatedIndexedObject, evaluatedIndex)
+	case evaluatedIndexedObject.Type() == object.STRING_OBJ && evaluatedIndex.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(evaluatedIndexedObject, evaluatedIndex)
 	case evaluatedIndexedObject.Type() == object.HASHMAP_OBJ:
 		return evalHashIndexExpression(evaluatedIndexedObject, evaluatedIndex)
+	case evaluatedIndexedObject.Type() == object.MODULE_OBJ && evaluatedIndex.Type() == object.STRING_OBJ:
+		return evalModuleIndexExpression(evaluatedIndexedObject, evaluatedIndex)
+	case evaluatedIndexedObject.Type() == object.EXCEPTION_OBJ && evaluatedIndex.Type() == object.STRING_OBJ:
+		return evalExceptionIndexExpression(evaluatedIndexedObject, evaluatedIndex)
 	default:
-		return newError("index operator not supported: %s[%s]", evaluatedIndexedObject.Type(), evaluatedIndex.Type())
+		return newError(object.TypeError, "index operator not supported: %s[%s]", evaluatedIndexedObject.Type(), evaluatedIndex.Type())
 	}
 }
 
 func evalArrayIndexExpression(indexedObject object.Object, index object.Object) object.Object {
 	array := indexedObject.(*object.Array).Elements
-	indexVal := index.(*object.Integer).Value
+	indexVal := normalizeIndex(index.(*object.Integer).Value, len(array))
 
 	if indexVal < 0 || indexVal >= int64(len(array)) {
 		return NULL
@@ -464,15 +858,105 @@ func evalArrayIndexExpression(indexedObject object.Object, index object.Object)
 	return array[indexVal]
 }
 
+// evalStringIndexExpression returns the character at the index, which counts
+// runes rather than bytes
+func evalStringIndexExpression(indexedObject object.Object, index object.Object) object.Object {
+	runes := []rune(indexedObject.(*object.String).Value)
+	indexVal := normalizeIndex(index.(*object.Integer).Value, len(runes))
+
+	if indexVal < 0 || indexVal >= int64(len(runes)) {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[indexVal])}
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env object.Environment) object.Object {
+	sliced := Eval(node.Sliced, env)
+	if isError(sliced) {
+		return sliced
+	}
+
+	bounds := []object.Object{NULL, NULL}
+	for idx, bound := range []ast.Expression{node.Start, node.End} {
+		if bound == nil {
+			continue
+		}
+		bounds[idx] = Eval(bound, env)
+		if isError(bounds[idx]) {
+			return bounds[idx]
+		}
+	}
+
+	return EvalSlice(sliced, bounds[0], bounds[1])
+}
+
+// EvalSlice returns the part of a string or array between start and end,
+// which are NULL when left out. Bounds past either end are clamped to it.
+func EvalSlice(sliced object.Object, start object.Object, end object.Object) object.Object {
+	switch sliced := sliced.(type) {
+	case *object.String:
+		runes := []rune(sliced.Value)
+		from, to, err := sliceBounds(start, end, len(runes))
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: string(runes[from:to])}
+	case *object.Array:
+		from, to, err := sliceBounds(start, end, len(sliced.Elements))
+		if err != nil {
+			return err
+		}
+		return &object.Array{Elements: slices.Clone(sliced.Elements[from:to])}
+	default:
+		return newError(object.TypeError, "slice operator not supported: %s", sliced.Type())
+	}
+}
+
+// sliceBounds converts the bounds of a slice of a value of length elements
+// to indexes into it, an empty range when start is past end
+func sliceBounds(start object.Object, end object.Object, length int) (int, int, *object.Error) {
+	bound := func(obj object.Object, fallback int) (int, *object.Error) {
+		switch obj := obj.(type) {
+		case *object.Null:
+			return fallback, nil
+		case *object.Integer:
+			return int(min(max(normalizeIndex(obj.Value, length), 0), int64(length))), nil
+		default:
+			return 0, newError(object.TypeError, "slice index must be INTEGER, got %s", obj.Type())
+		}
+	}
+
+	from, err := bound(start, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := bound(end, length)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return from, max(from, to), nil
+}
+
+// normalizeIndex turns a negative index, which counts from the end, into one
+// counting from the start
+func normalizeIndex(index int64, length int) int64 {
+	if index < 0 {
+		return index + int64(length)
+	}
+	return index
+}
+
 func evalHashIndexExpression(indexedObject object.Object, index object.Object) object.Object {
 	hashObject := indexedObject.(*object.HashMap)
 
 	key, ok := index.(object.Hashable)
 	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
+		return newError(object.TypeError, "unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashObject.Pairs[key.Hash()]
+	pair, ok := hashObject.Get(key.Hash())
 	if !ok {
 		return NULL
 	}
@@ -481,7 +965,7 @@ func evalHashIndexExpression(indexedObject object.Object, index object.Object) o
 }
 
 func evalHashLiteral(pairs []ast.HashPair, env object.Environment) object.Object {
-	p := make(map[object.HashKey]object.HashPair)
+	hashMap := object.NewHashMap()
 
 	for _, pair := range pairs {
 		keyNode := pair.Key
@@ -494,7 +978,7 @@ func evalHashLiteral(pairs []ast.HashPair, env object.Environment) object.Object
 
 		hashKey, ok := key.(object.Hashable)
 		if !ok {
-			return newError("unusable as hash key: %s", key.Type())
+			return newError(object.TypeError, "unusable as hash key: %s", key.Type())
 		}
 
 		value := Eval(valueNode, env)
@@ -502,22 +986,40 @@ func evalHashLiteral(pairs []ast.HashPair, env object.Environment) object.Object
 			return value
 		}
 
-		hashed := hashKey.Hash()
-		p[hashed] = object.HashPair{Key: key, Value: value}
+		hashMap.Set(hashKey.Hash(), object.HashPair{Key: key, Value: value})
 	}
 
-	return &object.HashMap{Pairs: p}
+	return hashMap
 }
 
-func newError(messageTemplate string, args ...any) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(messageTemplate, args...)}
+func newError(kind object.ErrorKind, messageTemplate string, args ...any) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(messageTemplate, args...)}
 }
 
 func isError(obj object.Object) bool {
 	return obj != nil && obj.Type() == object.ERROR_OBJ
 }
 
-func isTruthy(obj object.Object) bool {
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat converts an INTEGER, BIG_INTEGER or FLOAT object to a FLOAT object
+func toFloat(obj object.Object) *object.Float {
+	switch obj := obj.(type) {
+	case *object.Float:
+		return obj
+	case *object.Integer:
+		return &object.Float{Value: float64(obj.Value)}
+	case *object.BigInteger:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return &object.Float{Value: value}
+	default:
+		return nil
+	}
+}
+
+func IsTruthy(obj object.Object) bool {
 	if obj == FALSE || obj == NULL {
 		return false
 	}
@@ -1,6 +1,11 @@
 package evaluator_test
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
 	"taulang/ast"
 	"taulang/evaluator"
 	"taulang/lexer"
@@ -12,278 +17,280 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestEvaluator(t *testing.T) {
-	tests := []struct {
-		name           string
-		input          string
-		expectedObject object.Object
-	}{
-		{
-			name:  "success - integer 1",
-			input: "5;",
-			expectedObject: &object.Integer{
-				Value: 5,
-			},
-		},
-		{
-			name:  "success - integer 2",
-			input: "10;",
-			expectedObject: &object.Integer{
-				Value: 10,
-			},
-		},
-		{
-			name:  "success - boolean true",
-			input: "saccha;",
-			expectedObject: &object.Boolean{
-				Value: true,
-			},
-		},
-		{
-			name:  "success - boolean false",
-			input: "jhootha;",
-			expectedObject: &object.Boolean{
-				Value: false,
-			},
-		},
-		{
-			name:  "success - prefix expression - bang true",
-			input: "!saccha;",
-			expectedObject: &object.Boolean{
-				Value: false,
-			},
-		},
-		{
-			name:  "success - boolean expression - bang false",
-			input: "!jhootha;",
-			expectedObject: &object.Boolean{
-				Value: true,
-			},
-		},
-		{
-			name:  "success - boolean expression - bang integer",
-			input: "!!!!-5;",
-			expectedObject: &object.Boolean{
-				Value: true,
-			},
-		},
-		{
-			name:  "success - prefix expression - minus 1",
-			input: "-5;",
-			expectedObject: &object.Integer{
-				Value: -5,
-			},
-		},
-		{
-			name:  "success - prefix expression - minus 2",
-			input: "-10;",
-			expectedObject: &object.Integer{
-				Value: -10,
-			},
-		},
-		{
-			name:           "failure - prefix expression - minus operator on non integer types 1",
-			input:          "-saccha;",
-			expectedObject: &object.Error{Message: "unknown operator: -BOOLEAN"},
-		},
-		{
-			name:           "failure - prefix expression - minus operator on non integer types 2",
-			input:          "-jhootha;",
-			expectedObject: &object.Error{Message: "unknown operator: -BOOLEAN"},
-		},
-		{
-			name:           "failure - multiple statements with error in first",
-			input:          "-jhootha;5;",
-			expectedObject: &object.Error{Message: "unknown operator: -BOOLEAN"},
-		},
-		{
-			name:           "success - infix expression 1",
-			input:          "5 + 5 + 5 + 5 - 10;",
-			expectedObject: &object.Integer{Value: 10},
-		},
-		{
-			name:           "success - infix expression 2",
-			input:          "2 * 2 * 2 * 2 * 2;",
-			expectedObject: &object.Integer{Value: 32},
-		},
-		{
-			name:           "success - infix expression 3",
-			input:          "-50 + 100 + -50;",
-			expectedObject: &object.Integer{Value: 0},
-		},
-		{
-			name:           "success - infix expression 4",
-			input:          "5 * 2 + 10;",
-			expectedObject: &object.Integer{Value: 20},
-		},
-		{
-			name:           "success - infix expression 5",
-			input:          "5 + 2 * 10",
-			expectedObject: &object.Integer{Value: 25},
-		},
-		{
-			name:           "success - infix expression 6",
-			input:          "20 + 2 * -10",
-			expectedObject: &object.Integer{Value: 0},
-		},
-		{
-			name:           "success - infix expression 7",
-			input:          "50 / 2 * 2 + 10;",
-			expectedObject: &object.Integer{Value: 60},
-		},
-		{
-			name:           "success - infix expression 8",
-			input:          "2 * (5 + 10);",
-			expectedObject: &object.Integer{Value: 30},
-		},
-		{
-			name:           "success - infix expression 9",
-			input:          "3 * 3 * 3 + 10",
-			expectedObject: &object.Integer{Value: 37},
-		},
-		{
-			name:           "success - infix expression 10",
-			input:          "3 * (3 * 3) + 10",
-			expectedObject: &object.Integer{Value: 37},
-		},
-		{
-			name:           "success - infix expression 11",
-			input:          "(5 + 10 * 2 + 15 / 3) * 2 + -10",
-			expectedObject: &object.Integer{Value: 50},
-		},
-		{
-			name:           "failure - infix expression - division by 0",
-			input:          "2 / 0 + -10",
-			expectedObject: &object.Error{Message: "division by zero"},
-		},
-		{
-			name:           "failure - infix expression type mismatch",
-			input:          "5 + saccha; 5;",
-			expectedObject: &object.Error{Message: "type mismatch: INTEGER + BOOLEAN"},
-		},
-		{
-			name:           "failure - infix expression type mismatch",
-			input:          "5; saccha + jhootha; 5",
-			expectedObject: &object.Error{Message: "unknown operator: BOOLEAN + BOOLEAN"},
-		},
-		{
-			name:           "success - infix expression - truthy equality object comparison",
-			input:          "!!2 == saccha",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - falsy equality object comparison",
-			input:          "2 == jhootha",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - infix expression - falsy inequality object comparison",
-			input:          "!!2 != saccha",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - infix expression - truthy inequality object comparison",
-			input:          "2 != jhootha",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - integer comparison 1",
-			input:          "2 == 10 - 8",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - integer comparison 2",
-			input:          "2 == 10 * 8",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - infix expression - integer comparison 3",
-			input:          "2 != 10 - 8",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - infix expression - integer comparison 4",
-			input:          "2 != 10",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - integer comparison 5",
-			input:          "2 < 10",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - integer comparison 6",
-			input:          "2 < 1",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - infix expression - integer comparison 7",
-			input:          "2 <= 2",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - integer comparison 8",
-			input:          "2 <= 1",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - infix expression - integer comparison 9",
-			input:          "2 > 10 - 8",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - infix expression - integer comparison 10",
-			input:          "2 > 1",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - integer comparison 11",
-			input:          "2 >= 10 - 8",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - integer comparison 12",
-			input:          "2 >= 10",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "success - conditional expression 1",
-			input:          "agar_maan_lo (1 < 2) { 10 }",
-			expectedObject: &object.Integer{Value: 10},
-		},
-		{
-			name:           "success - conditional expression 2",
-			input:          "agar_maan_lo (1 > 2) { 10 }",
-			expectedObject: &object.Null{},
-		},
-		{
-			name:           "success - conditional expression 3",
-			input:          "agar_maan_lo (1 > 2) { 10 } na_toh { 20 }",
-			expectedObject: &object.Integer{Value: 20},
-		},
-		{
-			name:           "success - conditional expression 4",
-			input:          "agar_maan_lo (1 < 2) { 10 } na_toh { 20 }",
-			expectedObject: &object.Integer{Value: 10},
-		},
-		{
-			name:           "success - return statement 1",
-			input:          "laadle_ye_le 2 * 5; 9;",
-			expectedObject: &object.Integer{Value: 10},
-		},
-		{
-			name:           "success - return statement 2",
-			input:          "9; laadle_ye_le 2 * 3; 9;",
-			expectedObject: &object.Integer{Value: 6},
-		},
-		{
-			name:           "success - return statement 3",
-			input:          "agar_maan_lo (10 > 1) { laadle_ye_le 10; }",
-			expectedObject: &object.Integer{Value: 10},
-		},
-		{
-			name: "success - return statement 4",
-			input: `
+type evaluatorTestCase struct {
+	name           string
+	input          string
+	expectedObject object.Object
+}
+
+// evaluatorTests are shared by both engines, see TestVMAgreesWithEvaluator
+var evaluatorTests = []evaluatorTestCase{
+	{
+		name:  "success - integer 1",
+		input: "5;",
+		expectedObject: &object.Integer{
+			Value: 5,
+		},
+	},
+	{
+		name:  "success - integer 2",
+		input: "10;",
+		expectedObject: &object.Integer{
+			Value: 10,
+		},
+	},
+	{
+		name:  "success - boolean true",
+		input: "saccha;",
+		expectedObject: &object.Boolean{
+			Value: true,
+		},
+	},
+	{
+		name:  "success - boolean false",
+		input: "jhootha;",
+		expectedObject: &object.Boolean{
+			Value: false,
+		},
+	},
+	{
+		name:  "success - prefix expression - bang true",
+		input: "!saccha;",
+		expectedObject: &object.Boolean{
+			Value: false,
+		},
+	},
+	{
+		name:  "success - boolean expression - bang false",
+		input: "!jhootha;",
+		expectedObject: &object.Boolean{
+			Value: true,
+		},
+	},
+	{
+		name:  "success - boolean expression - bang integer",
+		input: "!!!!-5;",
+		expectedObject: &object.Boolean{
+			Value: true,
+		},
+	},
+	{
+		name:  "success - prefix expression - minus 1",
+		input: "-5;",
+		expectedObject: &object.Integer{
+			Value: -5,
+		},
+	},
+	{
+		name:  "success - prefix expression - minus 2",
+		input: "-10;",
+		expectedObject: &object.Integer{
+			Value: -10,
+		},
+	},
+	{
+		name:           "failure - prefix expression - minus operator on non integer types 1",
+		input:          "-saccha;",
+		expectedObject: &object.Error{Message: "unknown operator: -BOOLEAN"},
+	},
+	{
+		name:           "failure - prefix expression - minus operator on non integer types 2",
+		input:          "-jhootha;",
+		expectedObject: &object.Error{Message: "unknown operator: -BOOLEAN"},
+	},
+	{
+		name:           "failure - multiple statements with error in first",
+		input:          "-jhootha;5;",
+		expectedObject: &object.Error{Message: "unknown operator: -BOOLEAN"},
+	},
+	{
+		name:           "success - infix expression 1",
+		input:          "5 + 5 + 5 + 5 - 10;",
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name:           "success - infix expression 2",
+		input:          "2 * 2 * 2 * 2 * 2;",
+		expectedObject: &object.Integer{Value: 32},
+	},
+	{
+		name:           "success - infix expression 3",
+		input:          "-50 + 100 + -50;",
+		expectedObject: &object.Integer{Value: 0},
+	},
+	{
+		name:           "success - infix expression 4",
+		input:          "5 * 2 + 10;",
+		expectedObject: &object.Integer{Value: 20},
+	},
+	{
+		name:           "success - infix expression 5",
+		input:          "5 + 2 * 10",
+		expectedObject: &object.Integer{Value: 25},
+	},
+	{
+		name:           "success - infix expression 6",
+		input:          "20 + 2 * -10",
+		expectedObject: &object.Integer{Value: 0},
+	},
+	{
+		name:           "success - infix expression 7",
+		input:          "50 / 2 * 2 + 10;",
+		expectedObject: &object.Integer{Value: 60},
+	},
+	{
+		name:           "success - infix expression 8",
+		input:          "2 * (5 + 10);",
+		expectedObject: &object.Integer{Value: 30},
+	},
+	{
+		name:           "success - infix expression 9",
+		input:          "3 * 3 * 3 + 10",
+		expectedObject: &object.Integer{Value: 37},
+	},
+	{
+		name:           "success - infix expression 10",
+		input:          "3 * (3 * 3) + 10",
+		expectedObject: &object.Integer{Value: 37},
+	},
+	{
+		name:           "success - infix expression 11",
+		input:          "(5 + 10 * 2 + 15 / 3) * 2 + -10",
+		expectedObject: &object.Integer{Value: 50},
+	},
+	{
+		name:           "failure - infix expression - division by 0",
+		input:          "2 / 0 + -10",
+		expectedObject: &object.Error{Message: "division by zero"},
+	},
+	{
+		name:           "failure - infix expression type mismatch",
+		input:          "5 + saccha; 5;",
+		expectedObject: &object.Error{Message: "type mismatch: INTEGER + BOOLEAN"},
+	},
+	{
+		name:           "failure - infix expression type mismatch",
+		input:          "5; saccha + jhootha; 5",
+		expectedObject: &object.Error{Message: "unknown operator: BOOLEAN + BOOLEAN"},
+	},
+	{
+		name:           "success - infix expression - truthy equality object comparison",
+		input:          "!!2 == saccha",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - falsy equality object comparison",
+		input:          "2 == jhootha",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - infix expression - falsy inequality object comparison",
+		input:          "!!2 != saccha",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - infix expression - truthy inequality object comparison",
+		input:          "2 != jhootha",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - integer comparison 1",
+		input:          "2 == 10 - 8",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - integer comparison 2",
+		input:          "2 == 10 * 8",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - infix expression - integer comparison 3",
+		input:          "2 != 10 - 8",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - infix expression - integer comparison 4",
+		input:          "2 != 10",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - integer comparison 5",
+		input:          "2 < 10",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - integer comparison 6",
+		input:          "2 < 1",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - infix expression - integer comparison 7",
+		input:          "2 <= 2",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - integer comparison 8",
+		input:          "2 <= 1",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - infix expression - integer comparison 9",
+		input:          "2 > 10 - 8",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - infix expression - integer comparison 10",
+		input:          "2 > 1",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - integer comparison 11",
+		input:          "2 >= 10 - 8",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - integer comparison 12",
+		input:          "2 >= 10",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - conditional expression 1",
+		input:          "agar_maan_lo (1 < 2) { 10 }",
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name:           "success - conditional expression 2",
+		input:          "agar_maan_lo (1 > 2) { 10 }",
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "success - conditional expression 3",
+		input:          "agar_maan_lo (1 > 2) { 10 } na_toh { 20 }",
+		expectedObject: &object.Integer{Value: 20},
+	},
+	{
+		name:           "success - conditional expression 4",
+		input:          "agar_maan_lo (1 < 2) { 10 } na_toh { 20 }",
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name:           "success - return statement 1",
+		input:          "laadle_ye_le 2 * 5; 9;",
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name:           "success - return statement 2",
+		input:          "9; laadle_ye_le 2 * 3; 9;",
+		expectedObject: &object.Integer{Value: 6},
+	},
+	{
+		name:           "success - return statement 3",
+		input:          "agar_maan_lo (10 > 1) { laadle_ye_le 10; }",
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name: "success - return statement 4",
+		input: `
 			agar_maan_lo (10 > 1) {
 			  agar_maan_lo (10 > 1) {
 				laadle_ye_le 10;
@@ -292,104 +299,134 @@ func TestEvaluator(t *testing.T) {
 			  laadle_ye_le 1;
 			}
 			`,
-			expectedObject: &object.Integer{Value: 10},
-		},
-		{
-			name:           "success - string literal expression",
-			input:          "\"test_string\";",
-			expectedObject: &object.String{Value: "test_string"},
-		},
-		{
-			name:           "success - infix expression - string concatenation",
-			input:          "laadle_ye_le \"tau\" + \" khush\";",
-			expectedObject: &object.String{Value: "tau khush"},
-		},
-		{
-			name:           "success - infix expression - truthy string equality check",
-			input:          "\"tau\" == \"tau\"",
-			expectedObject: &object.Boolean{Value: true},
-		},
-		{
-			name:           "success - infix expression - falsy string equality check",
-			input:          "\"tau\" == \"not_tau\"",
-			expectedObject: &object.Boolean{Value: false},
-		},
-		{
-			name:           "failure - infix expression - string unknown operator",
-			input:          "\"tau\" * \"not_tau\";",
-			expectedObject: &object.Error{Message: "unknown operator: STRING * STRING"},
-		},
-		{
-			name:           "success - let statement 1",
-			input:          "sun_liyo_tau a ne_bana_diye 5; a;",
-			expectedObject: &object.Integer{Value: 5},
-		},
-		{
-			name: "success - let statement 2",
-			input: `
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name:           "success - string literal expression",
+		input:          "\"test_string\";",
+		expectedObject: &object.String{Value: "test_string"},
+	},
+	{
+		name:           "success - string literal with escape sequences",
+		input:          `"say \"hi\"\n\ttau \\ \u{1F600}";`,
+		expectedObject: &object.String{Value: "say \"hi\"\n\ttau \\ \U0001F600"},
+	},
+	{
+		name:           "success - interpolated string",
+		input:          `sun_liyo_tau name ne_bana_diye "Tau"; $"Namaste {name}, {20 + 1} {[1, 2.5, "a"]} {print()}"`,
+		expectedObject: &object.String{Value: "Namaste Tau, 21 [1, 2.5, a] null"},
+	},
+	{
+		name:           "success - interpolated string with nested strings and braces",
+		input:          `sun_liyo_tau h ne_bana_diye {"k": "v"}; $"{$"<{h["k"]}>"} \{x\}"`,
+		expectedObject: &object.String{Value: "<v> {x}"},
+	},
+	{
+		name:           "failure - interpolated string expression error",
+		input:          `$"a {1 + "b"}"`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "type mismatch: INTEGER + STRING"},
+	},
+	{
+		name:           "success - builtin str",
+		input:          `"n=" + str(5) + str(1.5) + str("s") + str([saccha])`,
+		expectedObject: &object.String{Value: "n=51.5s[true]"},
+	},
+	{
+		name:           "success - raw string literal spans lines",
+		input:          "`SELECT *\nFROM t WHERE a = \"\\n\"`;",
+		expectedObject: &object.String{Value: "SELECT *\nFROM t WHERE a = \"\\n\""},
+	},
+	{
+		name:           "success - infix expression - string concatenation",
+		input:          "laadle_ye_le \"tau\" + \" khush\";",
+		expectedObject: &object.String{Value: "tau khush"},
+	},
+	{
+		name:           "success - infix expression - truthy string equality check",
+		input:          "\"tau\" == \"tau\"",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - infix expression - falsy string equality check",
+		input:          "\"tau\" == \"not_tau\"",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "failure - infix expression - string unknown operator",
+		input:          "\"tau\" * \"not_tau\";",
+		expectedObject: &object.Error{Message: "unknown operator: STRING * STRING"},
+	},
+	{
+		name:           "success - let statement 1",
+		input:          "sun_liyo_tau a ne_bana_diye 5; a;",
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name: "success - let statement 2",
+		input: `
 			sun_liyo_tau a ne_bana_diye 5 * 2
 			sun_liyo_tau b ne_bana_diye a + 2;
 			sun_liyo_tau c ne_bana_diye a + b + 8
 			c;
 			`,
-			expectedObject: &object.Integer{Value: 30},
-		},
-		{
-			name:           "failure - identifier not found",
-			input:          "sun_liyo_tau a ne_bana_diye 5; c;",
-			expectedObject: &object.Error{Message: "identifier not found: c"},
-		},
-		{
-			name:           "failure - identifier not found",
-			input:          "a; sun_liyo_tau a ne_bana_diye 5;",
-			expectedObject: &object.Error{Message: "identifier not found: a"},
-		},
-		{
-			name:  "success - function definition",
-			input: "tau_ka_jugaad(x) { x + 2; };",
-			expectedObject: &object.Function{
-				Params: []*ast.Identifier{
-					{
-						Token: token.Token{Type: token.IDENTIFIER, Literal: "x"},
-						Value: "x",
-					},
+		expectedObject: &object.Integer{Value: 30},
+	},
+	{
+		name:           "failure - identifier not found",
+		input:          "sun_liyo_tau a ne_bana_diye 5; c;",
+		expectedObject: &object.Error{Message: "identifier not found: c"},
+	},
+	{
+		name:           "failure - identifier not found",
+		input:          "a; sun_liyo_tau a ne_bana_diye 5;",
+		expectedObject: &object.Error{Message: "identifier not found: a"},
+	},
+	{
+		name:  "success - function definition",
+		input: "tau_ka_jugaad(x) { x + 2; };",
+		expectedObject: &object.Function{
+			Params: []*ast.Identifier{
+				{
+					Token: token.Token{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 1, Column: 15, Offset: 14}},
+					Value: "x",
 				},
-				Body: &ast.BlockStatement{
-					Token: token.Token{Type: token.LEFT_BRACE, Literal: "{"},
-					Statements: []ast.Statement{
-						&ast.ExpressionStatement{
-							Token: token.Token{Type: token.IDENTIFIER, Literal: "x"},
-							Expression: &ast.InfixExpression{
-								Token: token.Token{Type: token.ADDITION, Literal: "+"},
-								Left: &ast.Identifier{
-									Token: token.Token{Type: token.IDENTIFIER, Literal: "x"},
-									Value: "x",
-								},
-								Operator: "+",
-								Right: &ast.IntegerLiteral{
-									Token: token.Token{Type: token.NUMBER, Literal: "2"},
-									Value: 2,
-								},
+			},
+			Body: &ast.BlockStatement{
+				Token: token.Token{Type: token.LEFT_BRACE, Literal: "{", Position: token.Position{Line: 1, Column: 18, Offset: 17}},
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 1, Column: 20, Offset: 19}},
+						Expression: &ast.InfixExpression{
+							Token: token.Token{Type: token.ADDITION, Literal: "+", Position: token.Position{Line: 1, Column: 22, Offset: 21}},
+							Left: &ast.Identifier{
+								Token: token.Token{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 1, Column: 20, Offset: 19}},
+								Value: "x",
+							},
+							Operator: "+",
+							Right: &ast.IntegerLiteral{
+								Token: token.Token{Type: token.NUMBER, Literal: "2", Position: token.Position{Line: 1, Column: 24, Offset: 23}},
+								Value: 2,
 							},
 						},
 					},
 				},
-				Env: object.NewEnvironment(),
 			},
-		},
-		{
-			name:           "success - call expression 1",
-			input:          "sun_liyo_tau add ne_bana_diye tau_ka_jugaad(x, y) { x + y; }; add(5 + 5, add(5, 5));",
-			expectedObject: &object.Integer{Value: 20},
-		},
-		{
-			name:           "success - call expression 2",
-			input:          "tau_ka_jugaad(x) { x; }(5)",
-			expectedObject: &object.Integer{Value: 5},
-		},
-		{
-			name: "success - call expression 3",
-			input: `
+			Env: object.NewEnvironment(),
+		},
+	},
+	{
+		name:           "success - call expression 1",
+		input:          "sun_liyo_tau add ne_bana_diye tau_ka_jugaad(x, y) { x + y; }; add(5 + 5, add(5, 5));",
+		expectedObject: &object.Integer{Value: 20},
+	},
+	{
+		name:           "success - call expression 2",
+		input:          "tau_ka_jugaad(x) { x; }(5)",
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name: "success - call expression 3",
+		input: `
 			sun_liyo_tau first ne_bana_diye 10;
 			sun_liyo_tau second ne_bana_diye 10;
 			sun_liyo_tau third ne_bana_diye 10;
@@ -402,21 +439,21 @@ func TestEvaluator(t *testing.T) {
 			
 			ourFunction(20) + first + second;
 			`,
-			expectedObject: &object.Integer{Value: 70},
-		},
-		{
-			name:           "failure - call expression - not a function",
-			input:          "sun_liyo_tau x ne_bana_diye 65; x(5)",
-			expectedObject: &object.Error{Message: "not a function: INTEGER"},
-		},
-		{
-			name:           "success - assignment statement 1",
-			input:          "sun_liyo_tau x ne_bana_diye 65; x ne_bana_diye x + 1;x",
-			expectedObject: &object.Integer{Value: 66},
-		},
-		{
-			name: "success - assignment statement 2",
-			input: `
+		expectedObject: &object.Integer{Value: 70},
+	},
+	{
+		name:           "failure - call expression - not a function",
+		input:          "sun_liyo_tau x ne_bana_diye 65; x(5)",
+		expectedObject: &object.Error{Message: "not a function: INTEGER"},
+	},
+	{
+		name:           "success - assignment statement 1",
+		input:          "sun_liyo_tau x ne_bana_diye 65; x ne_bana_diye x + 1;x",
+		expectedObject: &object.Integer{Value: 66},
+	},
+	{
+		name: "success - assignment statement 2",
+		input: `
 			sun_liyo_tau fn ne_bana_diye tau_ka_jugaad() { 
 				laadle_ye_le 12; 
 			}; 
@@ -425,11 +462,11 @@ func TestEvaluator(t *testing.T) {
 			x ne_bana_diye x + 1;
 			laadle_ye_le x;
 			`,
-			expectedObject: &object.Integer{Value: 13},
-		},
-		{
-			name: "success - while loop expression 1",
-			input: `
+		expectedObject: &object.Integer{Value: 13},
+	},
+	{
+		name: "success - while loop expression 1",
+		input: `
 			sun_liyo_tau fn ne_bana_diye tau_ka_jugaad() { 
 				laadle_ye_le 12; 
 			}; 
@@ -441,11 +478,11 @@ func TestEvaluator(t *testing.T) {
 			x ne_bana_diye x + 1;
 			laadle_ye_le x;
 			`,
-			expectedObject: &object.Integer{Value: 73},
-		},
-		{
-			name: "success - while loop expression 2",
-			input: `
+		expectedObject: &object.Integer{Value: 73},
+	},
+	{
+		name: "success - while loop expression 2",
+		input: `
 			sun_liyo_tau power ne_bana_diye tau_ka_jugaad(x, n) { 
 				sun_liyo_tau i ne_bana_diye 0;
 				sun_liyo_tau result ne_bana_diye 1;
@@ -457,11 +494,11 @@ func TestEvaluator(t *testing.T) {
 			}; 
 			power(2, 5) + power(10, 4)
 			`,
-			expectedObject: &object.Integer{Value: 10032},
-		},
-		{
-			name: "success - while loop expression - break statement 1",
-			input: `
+		expectedObject: &object.Integer{Value: 10032},
+	},
+	{
+		name: "success - while loop expression - break statement 1",
+		input: `
 			sun_liyo_tau incr ne_bana_diye tau_ka_jugaad(n) { 
 				sun_liyo_tau i ne_bana_diye 0;
 				jab_tak (i) {
@@ -475,11 +512,11 @@ func TestEvaluator(t *testing.T) {
 			}; 
 			incr(5) + incr(100)
 			`,
-			expectedObject: &object.Integer{Value: 105},
-		},
-		{
-			name: "success - while loop expression - break statement 2",
-			input: `
+		expectedObject: &object.Integer{Value: 105},
+	},
+	{
+		name: "success - while loop expression - break statement 2",
+		input: `
 			sun_liyo_tau power ne_bana_diye tau_ka_jugaad(x, n) { 
 				sun_liyo_tau i ne_bana_diye 0;
 				sun_liyo_tau result ne_bana_diye 1;
@@ -495,20 +532,20 @@ func TestEvaluator(t *testing.T) {
 			}; 
 			power(2, 5) + power(10, 4)
 			`,
-			expectedObject: &object.Integer{Value: 10032},
-		},
-		{
-			name: "failure - break statement outside while loop",
-			input: `
+		expectedObject: &object.Integer{Value: 10032},
+	},
+	{
+		name: "failure - break statement outside while loop",
+		input: `
 			agar_maan_lo (saccha) {
 				rok_diye
 			}
 			`,
-			expectedObject: &object.Error{Message: "found break statement outside of loop"},
-		},
-		{
-			name: "success - while loop expression - continue statement",
-			input: `
+		expectedObject: &object.Error{Message: "found break statement outside of loop"},
+	},
+	{
+		name: "success - while loop expression - continue statement",
+		input: `
 			sun_liyo_tau rangeSum ne_bana_diye tau_ka_jugaad(l, r) { 
 				sun_liyo_tau i ne_bana_diye 0;
 				sun_liyo_tau result ne_bana_diye 0;
@@ -524,258 +561,1710 @@ func TestEvaluator(t *testing.T) {
 			}; 
 			rangeSum(5, 10)
 			`,
-			expectedObject: &object.Integer{Value: 45},
-		},
-		{
-			name: "failure - continue statement outside while loop",
-			input: `
+		expectedObject: &object.Integer{Value: 45},
+	},
+	{
+		name: "failure - continue statement outside while loop",
+		input: `
 			agar_maan_lo (saccha) {
 				jaan_de
 			}
 			`,
-			expectedObject: &object.Error{Message: "found continue statement outside of loop"},
-		},
-		{
-			name:           "success - builtin function - len - string 1",
-			input:          "sun_liyo_tau a ne_bana_diye \"test string\"; len(a);",
-			expectedObject: &object.Integer{Value: 11},
-		},
-		{
-			name:           "success - builtin function - len - string 2",
-			input:          "len(\"twitter\");",
-			expectedObject: &object.Integer{Value: 7},
-		},
-		{
-			name:           "success - builtin function - len - array",
-			input:          "len([1, \"twitter\", saccha]);",
-			expectedObject: &object.Integer{Value: 3},
-		},
-		{
-			name:           "success - builtin function - len - hashmap",
-			input:          "len({\"one\":1, 2: 2, saccha: 3});",
-			expectedObject: &object.Integer{Value: 3},
-		},
-		{
-			name:  "success - array literal",
-			input: "[3, \"hello\", saccha, tau_ka_jugaad(x) { x + 2; }]",
-			expectedObject: &object.Array{
-				Elements: []object.Object{
-					&object.Integer{Value: 3},
-					&object.String{Value: "hello"},
-					&object.Boolean{Value: true},
-					&object.Function{
-						Params: []*ast.Identifier{
-							{Token: token.Token{Type: token.IDENTIFIER, Literal: "x"}, Value: "x"},
-						},
-						Body: &ast.BlockStatement{
-							Token: token.Token{Type: token.LEFT_BRACE, Literal: "{"},
-							Statements: []ast.Statement{
-								&ast.ExpressionStatement{
-									Token: token.Token{Type: token.IDENTIFIER, Literal: "x"},
-									Expression: &ast.InfixExpression{
-										Token: token.Token{Type: token.ADDITION, Literal: "+"},
-										Left: &ast.Identifier{
-											Token: token.Token{Type: token.IDENTIFIER, Literal: "x"},
-											Value: "x",
-										},
-										Operator: "+",
-										Right: &ast.IntegerLiteral{
-											Token: token.Token{Type: token.NUMBER, Literal: "2"},
-											Value: 2,
-										},
+		expectedObject: &object.Error{Message: "found continue statement outside of loop"},
+	},
+	{
+		name:           "success - builtin function - len - string 1",
+		input:          "sun_liyo_tau a ne_bana_diye \"test string\"; len(a);",
+		expectedObject: &object.Integer{Value: 11},
+	},
+	{
+		name:           "success - builtin function - len - string 2",
+		input:          "len(\"twitter\");",
+		expectedObject: &object.Integer{Value: 7},
+	},
+	{
+		name:           "success - builtin function - len - array",
+		input:          "len([1, \"twitter\", saccha]);",
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name:           "success - builtin function - len - hashmap",
+		input:          "len({\"one\":1, 2: 2, saccha: 3});",
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name:           "success - builtin function - len - counts characters",
+		input:          `len("नमस्ते")`,
+		expectedObject: &object.Integer{Value: 6},
+	},
+	{
+		name:           "success - string index",
+		input:          `"नमस्ते"[1]`,
+		expectedObject: &object.String{Value: "म"},
+	},
+	{
+		name:           "success - string index out of bounds",
+		input:          `"tau"[3]`,
+		expectedObject: evaluator.NULL,
+	},
+	{
+		name:           "success - string slice",
+		input:          `sun_liyo_tau s ne_bana_diye "namaste"; s[1:3] + s[:2] + s[5:] + s[:]`,
+		expectedObject: &object.String{Value: "amnatenamaste"},
+	},
+	{
+		name:           "success - string slice clamps the bounds",
+		input:          `"tau"[-5:10] + "|" + "tau"[2:1] + "|"`,
+		expectedObject: &object.String{Value: "tau||"},
+	},
+	{
+		name:           "failure - string slice with a non integer bound",
+		input:          `"tau"[1:"a"]`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "slice index must be INTEGER, got STRING"},
+	},
+	{
+		name:           "failure - slice of an unsupported type",
+		input:          `5[1:2]`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "slice operator not supported: INTEGER"},
+	},
+	{
+		name:  "success - array slice",
+		input: `sun_liyo_tau a ne_bana_diye [1, 2, 3, 4]; [a[1:3], a[:1], a[-2:], a[:-3], a[3:1]]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: 3}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 3}, &object.Integer{Value: 4}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}}},
+			&object.Array{Elements: []object.Object{}},
+		}},
+	},
+	{
+		name:           "success - array slice is a copy",
+		input:          `sun_liyo_tau a ne_bana_diye [1, 2]; sun_liyo_tau b ne_bana_diye a[:]; b[0] ne_bana_diye 5; a[0]`,
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:           "success - negative index assignment",
+		input:          `sun_liyo_tau a ne_bana_diye [1, 2]; a[-1] ne_bana_diye 5; a`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 5}}},
+	},
+	{
+		name:           "success - negative string index",
+		input:          `"tau"[-1] + "tau"[-3:-1]`,
+		expectedObject: &object.String{Value: "uta"},
+	},
+	{
+		name:           "success - builtin map",
+		input:          `sun_liyo_tau double ne_bana_diye tau_ka_jugaad(x) { x * 2 }; map([1, 2, 3], double)`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: 4}, &object.Integer{Value: 6}}},
+	},
+	{
+		name:           "success - builtin map with a builtin",
+		input:          `map(["a", "bb"], len)`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}}},
+	},
+	{
+		name:           "success - builtin map nested in a callback",
+		input:          `map([[1], [2, 3]], tau_ka_jugaad(row) { reduce(map(row, tau_ka_jugaad(x) { x * 10 }), tau_ka_jugaad(a, b) { a + b }) })`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 10}, &object.Integer{Value: 50}}},
+	},
+	{
+		name:           "success - builtin filter",
+		input:          `filter([1, 2, 3, 4], tau_ka_jugaad(x) { x > 2 })`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 3}, &object.Integer{Value: 4}}},
+	},
+	{
+		name:           "success - builtin reduce",
+		input:          `sun_liyo_tau add ne_bana_diye tau_ka_jugaad(a, b) { a + b }; [reduce([1, 2, 3], add), reduce([], add, 10)]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 6}, &object.Integer{Value: 10}}},
+	},
+	{
+		name:           "failure - builtin reduce of an empty array without initial value",
+		input:          `reduce([], tau_ka_jugaad(a, b) { a })`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "`reduce` of an empty array needs an initial value"},
+	},
+	{
+		name:           "failure - error in a function called by a builtin",
+		input:          `map([1, 0], tau_ka_jugaad(x) { 1 / x })`,
+		expectedObject: &object.Error{Kind: object.ZeroDivisionError, Message: "division by zero"},
+	},
+	{
+		name:           "failure - builtin called with a value that isn't a function",
+		input:          `map([1], 2)`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "not a function: INTEGER"},
+	},
+	{
+		name: "success - error in a function called by a builtin is caught",
+		input: `sun_liyo_tau inner ne_bana_diye map([1, 0], tau_ka_jugaad(x) { koshish_karo { 1 / x } pakad_lo (e) { -1 } });
+sun_liyo_tau outer ne_bana_diye koshish_karo { map([0], tau_ka_jugaad(x) { 1 / x }) } pakad_lo (e) { e.kind };
+[inner, outer]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: -1}}},
+			&object.String{Value: "ZeroDivisionError"},
+		}},
+	},
+	{
+		name:  "success - builtin sort",
+		input: `[sort([3, 1.5, -2, 1]), sort(["b", "c", "a"])]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.Integer{Value: -2}, &object.Integer{Value: 1}, &object.Float{Value: 1.5}, &object.Integer{Value: 3}}},
+			&object.Array{Elements: []object.Object{&object.String{Value: "a"}, &object.String{Value: "b"}, &object.String{Value: "c"}}},
+		}},
+	},
+	{
+		name:  "success - builtin sort with a comparator is stable",
+		input: `sort([[2, "a"], [1, "b"], [2, "c"], [1, "d"]], tau_ka_jugaad(a, b) { a[0] - b[0] })`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.String{Value: "b"}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.String{Value: "d"}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.String{Value: "a"}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.String{Value: "c"}}},
+		}},
+	},
+	{
+		name:           "failure - builtin sort of values that can't be compared",
+		input:          `sort([1, "a"])`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "cannot compare STRING with INTEGER, pass a comparator to `sort`"},
+	},
+	{
+		name:           "failure - builtin sort with a comparator that doesn't return an integer",
+		input:          `sort([1, 2], tau_ka_jugaad(a, b) { saccha })`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "comparator passed to `sort` must return INTEGER, got BOOLEAN"},
+	},
+	{
+		name:  "success - builtin rest, pop, insert and reverse",
+		input: `[rest([1, 2, 3]), pop([1, 2, 3]), insert([1, 3], 1, 2), insert([1], -1, 0), reverse([1, 2])]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: 3}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}, &object.Integer{Value: 3}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 0}, &object.Integer{Value: 1}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: 1}}},
+		}},
+	},
+	{
+		name:           "success - builtin rest and pop of an empty array",
+		input:          `[rest([]), pop([])]`,
+		expectedObject: &object.Array{Elements: []object.Object{evaluator.NULL, evaluator.NULL}},
+	},
+	{
+		name:           "failure - builtin insert out of bounds",
+		input:          `insert([1], 3, 0)`,
+		expectedObject: &object.Error{Kind: object.IndexError, Message: "insert index out of bounds: 3"},
+	},
+	{
+		name:           "success - builtin index_of and contains with arrays",
+		input:          `[index_of([1, "a", 2.0], 2), index_of([1], 5), contains([1, 2], 2), contains(["a"], "b")]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: -1}, evaluator.TRUE, evaluator.FALSE}},
+	},
+	{
+		name: "success - hashmap keeps insertion order",
+		input: `sun_liyo_tau h ne_bana_diye {"z": 1, 3: 2, "a": 3};
+h["b"] ne_bana_diye 4;
+h["z"] ne_bana_diye 5;
+str(h)`,
+		expectedObject: &object.String{Value: "{z: 5, 3: 2, a: 3, b: 4}"},
+	},
+	{
+		name:  "success - builtin keys, values and items",
+		input: `sun_liyo_tau h ne_bana_diye {"b": 1, "a": 2}; [keys(h), values(h), items(h), keys({})]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.String{Value: "b"}, &object.String{Value: "a"}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}}},
+			&object.Array{Elements: []object.Object{
+				&object.Array{Elements: []object.Object{&object.String{Value: "b"}, &object.Integer{Value: 1}}},
+				&object.Array{Elements: []object.Object{&object.String{Value: "a"}, &object.Integer{Value: 2}}},
+			}},
+			&object.Array{Elements: []object.Object{}},
+		}},
+	},
+	{
+		name:           "success - builtin has",
+		input:          `sun_liyo_tau h ne_bana_diye {"a": print(), 1: 2}; [has(h, "a"), has(h, 1), has(h, "b")]`,
+		expectedObject: &object.Array{Elements: []object.Object{evaluator.TRUE, evaluator.TRUE, evaluator.FALSE}},
+	},
+	{
+		name: "success - builtin delete returns a new hashmap",
+		input: `sun_liyo_tau h ne_bana_diye {"a": 1, "b": 2, "c": 3};
+sun_liyo_tau d ne_bana_diye delete(h, "b");
+d["b"] ne_bana_diye 4;
+[str(h), str(d), str(delete(h, "x")), len(delete(h, "a"))]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.String{Value: "{a: 1, b: 2, c: 3}"},
+			&object.String{Value: "{a: 1, c: 3, b: 4}"},
+			&object.String{Value: "{a: 1, b: 2, c: 3}"},
+			&object.Integer{Value: 2},
+		}},
+	},
+	{
+		name:           "success - builtin merge",
+		input:          `str(merge({"a": 1, "b": 2}, {"c": 3, "a": 4}, {}))`,
+		expectedObject: &object.String{Value: "{a: 4, b: 2, c: 3}"},
+	},
+	{
+		name:           "failure - builtin merge of a value that isn't a hashmap",
+		input:          `merge({}, [1])`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "argument 2 to `merge` must be HASHMAP, got ARRAY"},
+	},
+	{
+		name:           "failure - builtin has with a key that can't be hashed",
+		input:          `has({}, [1])`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "unusable as hash key: ARRAY"},
+	},
+	{
+		name:           "failure - builtin keys of a value that isn't a hashmap",
+		input:          `keys([1])`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "argument 1 to `keys` must be HASHMAP, got ARRAY"},
+	},
+	{
+		name:           "success - for-in loop over an array",
+		input:          `sun_liyo_tau total ne_bana_diye 0; har_ek (x mein [1, 2, 3]) { total ne_bana_diye total + x; }; total`,
+		expectedObject: &object.Integer{Value: 6},
+	},
+	{
+		name: "success - for-in loop with index",
+		input: `sun_liyo_tau out ne_bana_diye [];
+har_ek (i, x mein ["a", "b"]) { out ne_bana_diye push(out, $"{i}{x}"); };
+out`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.String{Value: "0a"}, &object.String{Value: "1b"}}},
+	},
+	{
+		name: "success - for-in loop over a hashmap",
+		input: `sun_liyo_tau h ne_bana_diye {"b": 1, "a": 2};
+sun_liyo_tau out ne_bana_diye "";
+har_ek (k mein h) { out ne_bana_diye out + k; };
+har_ek (k, v mein h) { out ne_bana_diye out + k + str(v); h["c"] ne_bana_diye 3; };
+out`,
+		expectedObject: &object.String{Value: "bab1a2"},
+	},
+	{
+		name: "success - for-in loop over a string",
+		input: `sun_liyo_tau out ne_bana_diye [];
+har_ek (i, c mein "नमस") { out ne_bana_diye push(out, str(i) + c); };
+out`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.String{Value: "0न"}, &object.String{Value: "1म"}, &object.String{Value: "2स"}}},
+	},
+	{
+		name: "success - for-in loop over ranges",
+		input: `sun_liyo_tau out ne_bana_diye [];
+har_ek (n mein range(3)) { out ne_bana_diye push(out, n); };
+har_ek (n mein range(10, 4, -3)) { out ne_bana_diye push(out, n); };
+har_ek (n mein range(2, 2)) { out ne_bana_diye push(out, n); };
+out`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 0}, &object.Integer{Value: 1}, &object.Integer{Value: 2},
+			&object.Integer{Value: 10}, &object.Integer{Value: 7},
+		}},
+	},
+	{
+		name: "success - for-in loop sees values set after the array grew",
+		input: `sun_liyo_tau a ne_bana_diye [1, 2, 3];
+sun_liyo_tau out ne_bana_diye [];
+har_ek (x mein a) {
+	agar_maan_lo (x == 1) { a[10] ne_bana_diye 0; a[2] ne_bana_diye 30; }
+	out ne_bana_diye push(out, x);
+};
+out`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 1}, &object.Integer{Value: 2}, &object.Integer{Value: 30},
+		}},
+	},
+	{
+		name:           "success - for-in loop value",
+		input:          `[har_ek (x mein [1, 2]) { x * 10 }, har_ek (x mein []) { x }, har_ek (x mein [1]) { rok_diye; }]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 20}, evaluator.NULL, evaluator.NULL}},
+	},
+	{
+		name: "success - for-in loop with break and continue",
+		input: `sun_liyo_tau out ne_bana_diye [];
+har_ek (n mein range(10)) {
+	agar_maan_lo (n == 1) { jaan_de; }
+	agar_maan_lo (n == 4) { rok_diye; }
+	out ne_bana_diye push(out, n);
+};
+[out, n]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 0}, &object.Integer{Value: 2}, &object.Integer{Value: 3}}},
+			&object.Integer{Value: 4},
+		}},
+	},
+	{
+		name: "success - nested for-in loops in a function",
+		input: `sun_liyo_tau find ne_bana_diye tau_ka_jugaad(rows, target) {
+	har_ek (i, row mein rows) {
+		har_ek (j, x mein row) {
+			agar_maan_lo (x == target) { laadle_ye_le [i, j]; }
+		}
+	};
+	laadle_ye_le [];
+};
+find([[1, 2], [3, 4]], 4)`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 1}}},
+	},
+	{
+		name:  "success - range builtin",
+		input: `[len(range(0, 10, 3)), len(range(5, 0)), len(range(5, 0, -2)), str(range(3)), str(range(1, 5, 2))]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 4}, &object.Integer{Value: 0}, &object.Integer{Value: 3},
+			&object.String{Value: "range(0, 3)"}, &object.String{Value: "range(1, 5, 2)"},
+		}},
+	},
+	{
+		name:           "failure - for-in loop over a value that can't be iterated",
+		input:          `har_ek (x mein 5) { x }`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "cannot iterate over INTEGER"},
+	},
+	{
+		name:           "failure - range builtin with a zero step",
+		input:          `range(1, 5, 0)`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "step passed to `range` must not be 0"},
+	},
+	{
+		name:           "failure - range builtin with a value that isn't an integer",
+		input:          `range(1.5)`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "argument 1 to `range` must be INTEGER, got FLOAT"},
+	},
+	{
+		name:  "success - modulo and power",
+		input: `[7 % 3, -7 % 3, 7.5 % 2, 2 ** 10, 2 ** 3 ** 2, -2 ** 2, 2 ** -1, 4.0 ** 0.5]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 1}, &object.Integer{Value: -1}, &object.Float{Value: 1.5}, &object.Integer{Value: 1024},
+			&object.Integer{Value: 512}, &object.Integer{Value: -4}, &object.Float{Value: 0.5}, &object.Float{Value: 2},
+		}},
+	},
+	{
+		name:  "success - bitwise operators",
+		input: `[6 & 3, 6 | 3, 6 ^ 3, ~5, 1 << 10, -16 >> 2, 5 >> 100, 1 | 2 & 3, 1 << 2 + 1]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 2}, &object.Integer{Value: 7}, &object.Integer{Value: 5}, &object.Integer{Value: -6},
+			&object.Integer{Value: 1024}, &object.Integer{Value: -4}, &object.Integer{Value: 0}, &object.Integer{Value: 3},
+			&object.Integer{Value: 8},
+		}},
+	},
+	{
+		name: "success - compound assignment",
+		input: `sun_liyo_tau x ne_bana_diye 10;
+x += 5; x -= 1; x *= 2; x /= 4; x %= 4; x **= 3; x <<= 2; x >>= 1; x |= 1; x &= 7; x ^= 2;
+sun_liyo_tau s ne_bana_diye "a";
+s += "b";
+[x, s]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 5}, &object.String{Value: "ab"}}},
+	},
+	{
+		name: "success - compound assignment of a variable of an enclosing function",
+		input: `sun_liyo_tau counter ne_bana_diye tau_ka_jugaad() {
+	sun_liyo_tau c ne_bana_diye 0;
+	tau_ka_jugaad() { c += 1; c }
+};
+sun_liyo_tau next ne_bana_diye counter();
+next();
+next()`,
+		expectedObject: &object.Integer{Value: 2},
+	},
+	{
+		name: "success - compound index assignment",
+		input: `sun_liyo_tau a ne_bana_diye [1, 2, 3];
+sun_liyo_tau h ne_bana_diye {"n": 1};
+a[0] += 10; a[-1] *= 3; h["n"] -= 2;
+har_ek (i mein range(3)) { a[i] += i; };
+[a, h["n"]]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 11}, &object.Integer{Value: 3}, &object.Integer{Value: 11}}},
+			&object.Integer{Value: -1},
+		}},
+	},
+	{
+		name: "success - compound assignment reads the variable before the value",
+		input: `sun_liyo_tau x ne_bana_diye 1;
+sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { x ne_bana_diye 100; 1 };
+x += f();
+x`,
+		expectedObject: &object.Integer{Value: 2},
+	},
+	{
+		name:           "failure - compound assignment of an undeclared variable",
+		input:          `nahi_hai += 1`,
+		expectedObject: &object.Error{Kind: object.NameError, Message: "identifier not found: nahi_hai"},
+	},
+	{
+		name:           "success - integer addition promotes to a big integer",
+		input:          `9223372036854775807 + 1`,
+		expectedObject: bigInteger("9223372036854775808"),
+	},
+	{
+		name:           "success - integer multiplication promotes to a big integer",
+		input:          `4611686018427387904 * -4`,
+		expectedObject: bigInteger("-18446744073709551616"),
+	},
+	{
+		name:           "success - integer power promotes to a big integer",
+		input:          `3 ** 40`,
+		expectedObject: bigInteger("12157665459056928801"),
+	},
+	{
+		name:           "success - integer shift promotes to a big integer",
+		input:          `1 << 64`,
+		expectedObject: bigInteger("18446744073709551616"),
+	},
+	{
+		name:           "success - negating the smallest integer promotes to a big integer",
+		input:          `-(-9223372036854775807 - 1)`,
+		expectedObject: bigInteger("9223372036854775808"),
+	},
+	{
+		name:           "success - big integer literal",
+		input:          `123456789012345678901234567890`,
+		expectedObject: bigInteger("123456789012345678901234567890"),
+	},
+	{
+		name:           "success - big integer results demote when small",
+		input:          `(9223372036854775807 + 10) - 20`,
+		expectedObject: &object.Integer{Value: 9223372036854775797},
+	},
+	{
+		name: "success - factorial past 20 with big integers",
+		input: `sun_liyo_tau fact ne_bana_diye tau_ka_jugaad(n) {
+	agar_maan_lo (n < 2) { laadle_ye_le 1; };
+	laadle_ye_le n * fact(n - 1);
+};
+fact(25)`,
+		expectedObject: bigInteger("15511210043330985984000000"),
+	},
+	{
+		name:  "success - big integer comparison, division and modulo",
+		input: `sun_liyo_tau b ne_bana_diye 2 ** 100; [b > 2 ** 99, b == 2 ** 100, b / 2 ** 98, b % 3, b / 3.0 > 1.0]`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Boolean{Value: true}, &object.Boolean{Value: true}, &object.Integer{Value: 4}, &object.Integer{Value: 1}, &object.Boolean{Value: true},
+		}},
+	},
+	{
+		name:           "success - big integers as hash map keys",
+		input:          `sun_liyo_tau h ne_bana_diye {2 ** 70: "big"}; h[2 ** 70]`,
+		expectedObject: &object.String{Value: "big"},
+	},
+	{
+		name:           "success - whole floats are the same keys as integers",
+		input:          `sun_liyo_tau h ne_bana_diye {1: "a", 2 ** 70: "big"}; [h[1.0], h[2.0 ** 70], len({1: "a", 1.0: "b"})]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.String{Value: "a"}, &object.String{Value: "big"}, &object.Integer{Value: 1}}},
+	},
+	{
+		name:           "success - negative zero is the same key as zero",
+		input:          `sun_liyo_tau h ne_bana_diye {0.0: "zero", 2.5: "half"}; [h[-0.0], h[0], h[2.5]]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.String{Value: "zero"}, &object.String{Value: "zero"}, &object.String{Value: "half"}}},
+	},
+	{
+		name:           "success - random_int in a range of one integer",
+		input:          `random_int(-5, -4)`,
+		expectedObject: &object.Integer{Value: -5},
+	},
+	{
+		name:           "success - random is below 1",
+		input:          `sun_liyo_tau r ne_bana_diye random(); r >= 0.0 && r < 1.0`,
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - env of a variable that is not set",
+		input:          `env("TAULANG_TEST_NOT_SET")`,
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "failure - random_int with an empty range",
+		input:          `random_int(3, 3)`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "empty range for `random_int`: 3 to 3"},
+	},
+	{
+		name:           "success - format with flags, width and precision",
+		input:          `format("%-5s|%6.2f|%03d|%x|%t|%c|%%", "tau", 3.14159, 7, 255, saccha, 964)`,
+		expectedObject: &object.String{Value: "tau  |  3.14|007|ff|true|τ|%"},
+	},
+	{
+		name:           "success - format of any value",
+		input:          `format("%s %v %q %.1f %d", [1, "a"], {"k": jhootha}, "hi", 2, 2 ** 70)`,
+		expectedObject: &object.String{Value: `[1, a] {k: false} "hi" 2.0 1180591620717411303424`},
+	},
+	{
+		name:           "failure - format with too few arguments",
+		input:          `format("%d and %d", 1)`,
+		expectedObject: &object.Error{Kind: object.ArgumentError, Message: "not enough arguments for format string, got 1"},
+	},
+	{
+		name:           "failure - format with too many arguments",
+		input:          `format("%d", 1, 2)`,
+		expectedObject: &object.Error{Kind: object.ArgumentError, Message: "too many arguments for format string, want 1, got 2"},
+	},
+	{
+		name:           "failure - format verb of another type",
+		input:          `format("%d", "1")`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "format verb %d needs INTEGER, got STRING"},
+	},
+	{
+		name:           "failure - unknown format verb",
+		input:          `format("%y", 1)`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "unknown format verb %y"},
+	},
+	{
+		name:           "failure - format string ending in a verb",
+		input:          `format("100%", 1)`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "format string ends in an incomplete verb: %"},
+	},
+	{
+		name:           "failure - read_file of a missing file",
+		input:          `read_file("/nahi/hai.tau")`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "could not read file: open /nahi/hai.tau: no such file or directory"},
+	},
+	{
+		name:           "failure - integer power too large to hold",
+		input:          `2 ** 100000000`,
+		expectedObject: &object.Error{Kind: object.OverflowError, Message: "integer overflow: 2 ** 100000000"},
+	},
+	{
+		name:           "success - negative exponent gives a float",
+		input:          `[2 ** -1, 0.5 ** -1]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Float{Value: 0.5}, &object.Float{Value: 2}}},
+	},
+	{
+		name:           "failure - zero to a negative power",
+		input:          `0 ** -1`,
+		expectedObject: &object.Error{Kind: object.ZeroDivisionError, Message: "zero raised to a negative power"},
+	},
+	{
+		name:           "failure - zero to a negative big integer power",
+		input:          `0 ** -(2 ** 70)`,
+		expectedObject: &object.Error{Kind: object.ZeroDivisionError, Message: "zero raised to a negative power"},
+	},
+	{
+		name:           "failure - float zero to a negative power",
+		input:          `0.0 ** -2`,
+		expectedObject: &object.Error{Kind: object.ZeroDivisionError, Message: "zero raised to a negative power"},
+	},
+	{
+		name:           "failure - big integer division by zero",
+		input:          `2 ** 100 / 0`,
+		expectedObject: &object.Error{Kind: object.ZeroDivisionError, Message: "division by zero"},
+	},
+	{
+		name:           "failure - negative shift count",
+		input:          `1 >> -1`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "negative shift count: -1"},
+	},
+	{
+		name:           "failure - modulo by zero",
+		input:          `5 % 0`,
+		expectedObject: &object.Error{Kind: object.ZeroDivisionError, Message: "modulo by zero"},
+	},
+	{
+		name:           "failure - bitwise operator on floats",
+		input:          `1.5 & 1`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "unknown operator: FLOAT & FLOAT"},
+	},
+	{
+		name:           "failure - bitwise not of a float",
+		input:          `~1.5`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "unknown operator: ~FLOAT"},
+	},
+	{
+		name:           "success - builtin split and join",
+		input:          `join(split("a,b,,c", ","), "-")`,
+		expectedObject: &object.String{Value: "a-b--c"},
+	},
+	{
+		name:           "failure - builtin join with a non string element",
+		input:          `join(["a", 1], ",")`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "elements joined by `join` must be STRING, got INTEGER at 1"},
+	},
+	{
+		name:           "success - builtin trim",
+		input:          `trim("  tau \n") + "|" + trim_start("  tau ") + "|" + trim_end("  tau ") + "|"`,
+		expectedObject: &object.String{Value: "tau|tau |  tau|"},
+	},
+	{
+		name:           "success - builtin upper and lower",
+		input:          `upper("Tau") + lower("TAU")`,
+		expectedObject: &object.String{Value: "TAUtau"},
+	},
+	{
+		name:           "success - builtin contains, starts_with and ends_with",
+		input:          `[contains("tau ji", "u j"), starts_with("tau", "ta"), ends_with("tau", "ta")]`,
+		expectedObject: &object.Array{Elements: []object.Object{evaluator.TRUE, evaluator.TRUE, evaluator.FALSE}},
+	},
+	{
+		name:           "success - builtin index_of counts characters",
+		input:          `[index_of("नमस्ते tau", "tau"), index_of("tau", "x")]`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 7}, &object.Integer{Value: -1}}},
+	},
+	{
+		name:           "success - builtin replace and repeat",
+		input:          `replace("a-b-c", "-", "+") + repeat("ab", 3)`,
+		expectedObject: &object.String{Value: "a+b+cababab"},
+	},
+	{
+		name:           "success - parameters of nested functions may share names",
+		input:          `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a, b) { tau_ka_jugaad(a) { a + b } }; f(1, 2)(10)`,
+		expectedObject: &object.Integer{Value: 12},
+	},
+	{
+		name:           "failure - builtin repeat with a negative count",
+		input:          `repeat("a", -1)`,
+		expectedObject: &object.Error{Kind: object.ValueError, Message: "count passed to `repeat` must not be negative, got -1"},
+	},
+	{
+		name:           "failure - builtin repeat with a count too large",
+		input:          `koshish_karo { repeat("ab", 9223372036854775807) } pakad_lo (e) { e.kind + ": " + e.message }`,
+		expectedObject: &object.String{Value: "ValueError: `repeat` would create a string longer than 1073741824 bytes"},
+	},
+	{
+		name:           "success - builtin substring",
+		input:          `substring("नमस्ते", 2, 4) + substring("tau", 1)`,
+		expectedObject: &object.String{Value: "स्au"},
+	},
+	{
+		name:           "success - builtin chars",
+		input:          `chars("ताऊ")`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.String{Value: "त"}, &object.String{Value: "ा"}, &object.String{Value: "ऊ"}}},
+	},
+	{
+		name:           "failure - string builtin with a wrong argument type",
+		input:          `upper(1)`,
+		expectedObject: &object.Error{Kind: object.TypeError, Message: "argument 1 to `upper` must be STRING, got INTEGER"},
+	},
+	{
+		name:           "failure - string builtin with a wrong number of arguments",
+		input:          `split("a")`,
+		expectedObject: &object.Error{Kind: object.ArgumentError, Message: "wrong number of arguments. got=1, want=2"},
+	},
+	{
+		name:  "success - array literal",
+		input: "[3, \"hello\", saccha, tau_ka_jugaad(x) { x + 2; }]",
+		expectedObject: &object.Array{
+			Elements: []object.Object{
+				&object.Integer{Value: 3},
+				&object.String{Value: "hello"},
+				&object.Boolean{Value: true},
+				&object.Function{
+					Params: []*ast.Identifier{
+						{Token: token.Token{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 1, Column: 36, Offset: 35}}, Value: "x"},
+					},
+					Body: &ast.BlockStatement{
+						Token: token.Token{Type: token.LEFT_BRACE, Literal: "{", Position: token.Position{Line: 1, Column: 39, Offset: 38}},
+						Statements: []ast.Statement{
+							&ast.ExpressionStatement{
+								Token: token.Token{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 1, Column: 41, Offset: 40}},
+								Expression: &ast.InfixExpression{
+									Token: token.Token{Type: token.ADDITION, Literal: "+", Position: token.Position{Line: 1, Column: 43, Offset: 42}},
+									Left: &ast.Identifier{
+										Token: token.Token{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 1, Column: 41, Offset: 40}},
+										Value: "x",
+									},
+									Operator: "+",
+									Right: &ast.IntegerLiteral{
+										Token: token.Token{Type: token.NUMBER, Literal: "2", Position: token.Position{Line: 1, Column: 45, Offset: 44}},
+										Value: 2,
 									},
 								},
 							},
 						},
-						Env: object.NewEnvironment(),
 					},
+					Env: object.NewEnvironment(),
 				},
 			},
 		},
-		{
-			name:           "success - index expression - array 1",
-			input:          "[1, 2, 3][2]",
-			expectedObject: &object.Integer{Value: 3},
-		},
-		{
-			name:           "success - index expression - array 2",
-			input:          "sun_liyo_tau i ne_bana_diye 0; [1][i];",
-			expectedObject: &object.Integer{Value: 1},
-		},
-		{
-			name:           "success - index expression - array 3",
-			input:          "sun_liyo_tau myArray ne_bana_diye [1, 2, 3]; sun_liyo_tau i ne_bana_diye myArray[0]; myArray[i]",
-			expectedObject: &object.Integer{Value: 2},
+	},
+	{
+		name:           "success - index expression - array 1",
+		input:          "[1, 2, 3][2]",
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name:           "success - index expression - array 2",
+		input:          "sun_liyo_tau i ne_bana_diye 0; [1][i];",
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:           "success - index expression - array 3",
+		input:          "sun_liyo_tau myArray ne_bana_diye [1, 2, 3]; sun_liyo_tau i ne_bana_diye myArray[0]; myArray[i]",
+		expectedObject: &object.Integer{Value: 2},
+	},
+	{
+		name:           "success - index expression - array 4",
+		input:          "[1, 2, 3][1 + 1];",
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name:           "success - index expression - array 5",
+		input:          "[1, 2, 3][3]",
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "success - index expression - array 6",
+		input:          "[1, 2, 3][-1]",
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name:           "success - index expression - array 7",
+		input:          "[1, 2, 3][-4]",
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "success - index expression - hashmap 1",
+		input:          `{"foo": 5}["foo"]`,
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name:           "success - index expression - hashmap 2",
+		input:          `{"foo": 5}["bar"]`,
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "success - index expression - hashmap 3",
+		input:          `sun_liyo_tau key ne_bana_diye "foo"; {"foo": 5}[key]`,
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name:           "success - index expression - hashmap 4",
+		input:          `{}["foo"]`,
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "success - index expression - hashmap 5",
+		input:          `{5: 5}[5]`,
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name:           "success - index expression - hashmap 6",
+		input:          `{saccha: 5}[saccha]`,
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name:           "success - index expression - hashmap 7",
+		input:          `{jhootha: 5}[jhootha]`,
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name:           "failure - index expression - array",
+		input:          "[1, 2, 3][saccha]",
+		expectedObject: &object.Error{Message: "index operator not supported: ARRAY[BOOLEAN]"},
+	},
+	{
+		name:           "failure - index expression - hashmap",
+		input:          "{1: 5}[tau_ka_jugaad(x) {x}]",
+		expectedObject: &object.Error{Message: "unusable as hash key: FUNCTION"},
+	},
+	{
+		name:           "success - builtin function - first",
+		input:          "first([1, 2, 3])",
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:           "success - builtin function - last",
+		input:          "last([1, 2, 3])",
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name:           "success - builtin function - last",
+		input:          "last(push([1, 2, 3], 4))",
+		expectedObject: &object.Integer{Value: 4},
+	},
+	{
+		name: "success - hashmap",
+		input: `sun_liyo_tau two ne_bana_diye "two";
+			{
+				"one": 10 - 9,
+				two: 1 + 1,
+				"thr" + "ee": 6 / 2,
+				4: 4,
+				saccha: 5,
+				jhootha: 6
+			}`,
+		expectedObject: &object.HashMap{
+			Pairs: map[object.HashKey]object.HashPair{
+				(&object.String{Value: "one"}).Hash(): {
+					Key:   &object.String{Value: "one"},
+					Value: &object.Integer{Value: 1},
+				},
+				(&object.String{Value: "two"}).Hash(): {
+					Key:   &object.String{Value: "two"},
+					Value: &object.Integer{Value: 2},
+				},
+				(&object.String{Value: "three"}).Hash(): {
+					Key:   &object.String{Value: "three"},
+					Value: &object.Integer{Value: 3},
+				},
+				(&object.Integer{Value: 4}).Hash(): {
+					Key:   &object.Integer{Value: 4},
+					Value: &object.Integer{Value: 4},
+				},
+				evaluator.TRUE.Hash(): {
+					Key:   &object.Boolean{Value: true},
+					Value: &object.Integer{Value: 5},
+				},
+				evaluator.FALSE.Hash(): {
+					Key:   &object.Boolean{Value: false},
+					Value: &object.Integer{Value: 6},
+				},
+			},
+			Order: []object.HashKey{
+				(&object.String{Value: "one"}).Hash(),
+				(&object.String{Value: "two"}).Hash(),
+				(&object.String{Value: "three"}).Hash(),
+				(&object.Integer{Value: 4}).Hash(),
+				evaluator.TRUE.Hash(),
+				evaluator.FALSE.Hash(),
+			},
 		},
+	},
+	{
+		name:           "failure - hashmap",
+		input:          `{tau_ka_jugaad(x) { x; }: 5}`,
+		expectedObject: &object.Error{Message: "unusable as hash key: FUNCTION"},
+	},
+	{
+		name: "success - hash index assignment",
+		input: `sun_liyo_tau map ne_bana_diye {"one": 1, "two": 2};
+			map["three"] ne_bana_diye 3;
+			map["three"];`,
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name: "success - hash index assignment - overwrite",
+		input: `sun_liyo_tau map ne_bana_diye {"one": 1, "two": 2};
+			map["one"] ne_bana_diye 10;
+			map["one"];`,
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name: "success - array index assignment",
+		input: `sun_liyo_tau arr ne_bana_diye [1, 2, 3];
+			arr[1] ne_bana_diye 20;
+			arr[1];`,
+		expectedObject: &object.Integer{Value: 20},
+	},
+	{
+		name: "success - array index assignment - extend",
+		input: `sun_liyo_tau arr ne_bana_diye [1, 2];
+			arr[5] ne_bana_diye 10;
+			arr[5];`,
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name: "success - array index assignment - verify other elements",
+		input: `sun_liyo_tau arr ne_bana_diye [1, 2];
+			arr[5] ne_bana_diye 10;
+			arr[0];`,
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:           "failure - index assignment on undefined variable",
+		input:          `nahi_hai["key"] ne_bana_diye 1;`,
+		expectedObject: &object.Error{Message: "identifier not found: nahi_hai"},
+	},
+	{
+		name:           "failure - index assignment on non-indexable type",
+		input:          `sun_liyo_tau x ne_bana_diye 5; x[0] ne_bana_diye 1;`,
+		expectedObject: &object.Error{Message: "index assignment not supported for type: INTEGER"},
+	},
+	{
+		name:           "success - no executable code only comments",
+		input:          `// sun_liyo_tau x ne_bana_diye 5; x[0] ne_bana_diye 1;`,
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "success - float literal",
+		input:          "3.14;",
+		expectedObject: &object.Float{Value: 3.14},
+	},
+	{
+		name:           "success - float arithmetic",
+		input:          "(1.5 + 2.25) * 2.0 - 0.5 / 0.25;",
+		expectedObject: &object.Float{Value: 5.5},
+	},
+	{
+		name:           "success - mixed integer and float arithmetic",
+		input:          "10 / 4.0 + 1;",
+		expectedObject: &object.Float{Value: 3.5},
+	},
+	{
+		name:           "success - integer division stays integer",
+		input:          "10 / 4;",
+		expectedObject: &object.Integer{Value: 2},
+	},
+	{
+		name:           "success - float unary minus",
+		input:          "-2.5;",
+		expectedObject: &object.Float{Value: -2.5},
+	},
+	{
+		name:           "success - mixed comparison",
+		input:          "1 < 1.5;",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - mixed equality",
+		input:          "2 == 2.0;",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "failure - float division by zero",
+		input:          "1.5 / 0;",
+		expectedObject: &object.Error{Message: "division by zero"},
+	},
+	{
+		name:           "success - float hash key",
+		input:          `sun_liyo_tau prices ne_bana_diye {1.5: "cheap", 99.99: "costly"}; prices[99.99];`,
+		expectedObject: &object.String{Value: "costly"},
+	},
+	{
+		name:           "success - builtin int truncates float",
+		input:          "int(-3.99);",
+		expectedObject: &object.Integer{Value: -3},
+	},
+	{
+		name:           "success - builtin int parses string",
+		input:          `int("42");`,
+		expectedObject: &object.Integer{Value: 42},
+	},
+	{
+		name:           "success - builtin float converts integer",
+		input:          "float(7) / 2;",
+		expectedObject: &object.Float{Value: 3.5},
+	},
+	{
+		name:           "success - builtin float parses string",
+		input:          `float("2.5");`,
+		expectedObject: &object.Float{Value: 2.5},
+	},
+	{
+		name:           "failure - builtin float with invalid string",
+		input:          `float("tau");`,
+		expectedObject: &object.Error{Message: `could not parse "tau" as float`},
+	},
+	{
+		name:           "failure - builtin int with unsupported type",
+		input:          `int([1]);`,
+		expectedObject: &object.Error{Message: "argument to `int` not supported, got ARRAY"},
+	},
+	{
+		name:           "success - logical and",
+		input:          "saccha && 1 < 2;",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - logical and keyword",
+		input:          "saccha aur jhootha;",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - logical or keyword",
+		input:          "jhootha ya_phir 2 > 1;",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "success - logical and short-circuits",
+		input:          "jhootha && undefined_function();",
+		expectedObject: &object.Boolean{Value: false},
+	},
+	{
+		name:           "success - logical or short-circuits",
+		input:          "saccha || undefined_function();",
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name: "success - logical and skips side effects of right operand",
+		input: `sun_liyo_tau calls ne_bana_diye [0];
+			sun_liyo_tau touch ne_bana_diye tau_ka_jugaad() { calls[0] ne_bana_diye calls[0] + 1; laadle_ye_le saccha; };
+			jhootha && touch();
+			saccha && touch();
+			calls[0];`,
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:           "success - logical operators use truthiness",
+		input:          `0 && "tau";`,
+		expectedObject: &object.Boolean{Value: true},
+	},
+	{
+		name:           "failure - logical or evaluates right operand when needed",
+		input:          "jhootha || undefined_function();",
+		expectedObject: &object.Error{Message: "identifier not found: undefined_function"},
+	},
+	{
+		name: "success - functions see bindings of their definition site",
+		input: `sun_liyo_tau x ne_bana_diye 1;
+		sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { x };
+		sun_liyo_tau g ne_bana_diye tau_ka_jugaad() { sun_liyo_tau x ne_bana_diye 2; f() };
+		g();`,
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:           "success - dot access on hash",
+		input:          `sun_liyo_tau h ne_bana_diye {"name": "tau", "inner": {"n": 1}}; h.inner.n;`,
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:           "success - dot access on missing hash key",
+		input:          `{"name": "tau"}.age;`,
+		expectedObject: &object.Null{},
+	},
+	{
+		name:           "failure - dot access on unsupported type",
+		input:          `saccha.name;`,
+		expectedObject: &object.Error{Message: "index operator not supported: BOOLEAN[STRING]"},
+	},
+	{
+		name: "success - closure counter mutates captured variable",
+		input: `sun_liyo_tau makeCounter ne_bana_diye tau_ka_jugaad() {
+			sun_liyo_tau count ne_bana_diye 0;
+			tau_ka_jugaad() { count ne_bana_diye count + 1; count };
+		};
+		sun_liyo_tau counter ne_bana_diye makeCounter();
+		counter();
+		counter();
+		counter();`,
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name: "success - counters from separate calls don't share state",
+		input: `sun_liyo_tau makeCounter ne_bana_diye tau_ka_jugaad() {
+			sun_liyo_tau count ne_bana_diye 0;
+			tau_ka_jugaad() { count ne_bana_diye count + 1; count };
+		};
+		sun_liyo_tau a ne_bana_diye makeCounter();
+		sun_liyo_tau b ne_bana_diye makeCounter();
+		a();
+		a();
+		[a(), b()];`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 3},
+			&object.Integer{Value: 1},
+		}},
+	},
+	{
+		name: "success - closures sharing a variable see each other's updates",
+		input: `sun_liyo_tau makeAccount ne_bana_diye tau_ka_jugaad(balance) {
+			sun_liyo_tau deposit ne_bana_diye tau_ka_jugaad(amount) { balance ne_bana_diye balance + amount; };
+			sun_liyo_tau current ne_bana_diye tau_ka_jugaad() { balance };
+			{"deposit": deposit, "current": current};
+		};
+		sun_liyo_tau account ne_bana_diye makeAccount(10);
+		account.deposit(5);
+		account.deposit(20);
+		account.current();`,
+		expectedObject: &object.Integer{Value: 35},
+	},
+	{
+		name: "success - nested closures update variable two levels up",
+		input: `sun_liyo_tau outer ne_bana_diye tau_ka_jugaad() {
+			sun_liyo_tau total ne_bana_diye 0;
+			sun_liyo_tau middle ne_bana_diye tau_ka_jugaad() {
+				sun_liyo_tau inner ne_bana_diye tau_ka_jugaad(n) { total ne_bana_diye total + n; };
+				inner(1);
+				inner(2);
+			};
+			middle();
+			total;
+		};
+		outer();`,
+		expectedObject: &object.Integer{Value: 3},
+	},
+	{
+		name: "success - function accumulates into global",
+		input: `sun_liyo_tau sum ne_bana_diye 0;
+		sun_liyo_tau add ne_bana_diye tau_ka_jugaad(n) { sum ne_bana_diye sum + n; };
+		add(4);
+		add(6);
+		sum;`,
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name: "success - let inside function shadows instead of mutating",
+		input: `sun_liyo_tau x ne_bana_diye 1;
+		sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { sun_liyo_tau x ne_bana_diye 2; x ne_bana_diye 3; x };
+		[f(), x];`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 3},
+			&object.Integer{Value: 1},
+		}},
+	},
+	{
+		name:           "failure - assignment to undeclared identifier",
+		input:          "y ne_bana_diye 5;",
+		expectedObject: &object.Error{Message: "cannot assign to undeclared identifier: y"},
+	},
+	{
+		name:           "failure - assignment to undeclared identifier inside function",
+		input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { y ne_bana_diye 5; }; f();",
+		expectedObject: &object.Error{Message: "cannot assign to undeclared identifier: y"},
+	},
+	{
+		name:           "failure - too few arguments",
+		input:          "sun_liyo_tau add ne_bana_diye tau_ka_jugaad(a, b) { a + b }; add(1);",
+		expectedObject: &object.Error{Message: "wrong number of arguments: expected 2, got 1"},
+	},
+	{
+		name:           "failure - too many arguments",
+		input:          "sun_liyo_tau add ne_bana_diye tau_ka_jugaad(a, b) { a + b }; add(1, 2, 3);",
+		expectedObject: &object.Error{Message: "wrong number of arguments: expected 2, got 3"},
+	},
+	{
+		name: "success - default parameter values",
+		input: `sun_liyo_tau greet ne_bana_diye tau_ka_jugaad(name, greeting ne_bana_diye "namaste") { greeting + " " + name };
+		[greet("tau"), greet("tau", "ram ram")];`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.String{Value: "namaste tau"},
+			&object.String{Value: "ram ram tau"},
+		}},
+	},
+	{
+		name:           "success - defaults can refer to earlier parameters",
+		input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a, b ne_bana_diye a * 2) { a + b }; f(3);",
+		expectedObject: &object.Integer{Value: 9},
+	},
+	{
+		name: "success - defaults are evaluated on every call",
+		input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(list ne_bana_diye []) { push(list, 1) };
+		f();
+		f();`,
+		expectedObject: &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}},
+	},
+	{
+		name:           "failure - too few arguments with defaults",
+		input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a, b ne_bana_diye 1) { a }; f();",
+		expectedObject: &object.Error{Message: "wrong number of arguments: expected 1 to 2, got 0"},
+	},
+	{
+		name:           "failure - error in default value",
+		input:          `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a ne_bana_diye 1 + "x") { a }; f();`,
+		expectedObject: &object.Error{Message: "type mismatch: INTEGER + STRING"},
+	},
+	{
+		name: "failure - defaults can't refer to later parameters",
+		input: `sun_liyo_tau b ne_bana_diye 10;
+		sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a ne_bana_diye b, b ne_bana_diye 2) { a };
+		f();`,
+		expectedObject: &object.Error{Message: "identifier not found: b"},
+	},
+	{
+		name: "success - closures in defaults see later parameters once bound",
+		input: `sun_liyo_tau b ne_bana_diye 10;
+		sun_liyo_tau f ne_bana_diye tau_ka_jugaad(get ne_bana_diye tau_ka_jugaad() { b }, b ne_bana_diye 2) { get() };
+		f();`,
+		expectedObject: &object.Integer{Value: 2},
+	},
+	{
+		name:           "success - defaults can refer to the rest parameter",
+		input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a ne_bana_diye len(rest), ...rest) { a }; f();",
+		expectedObject: &object.Integer{Value: 0},
+	},
+	{
+		name: "success - rest parameter collects extra arguments",
+		input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(first, ...rest) { [first, rest] };
+		f(1, 2, 3);`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 1},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: 3}}},
+		}},
+	},
+	{
+		name:           "success - rest parameter is empty without extra arguments",
+		input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(...rest) { len(rest) }; f();",
+		expectedObject: &object.Integer{Value: 0},
+	},
+	{
+		name: "success - defaults and rest parameter together",
+		input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a, b ne_bana_diye 10, ...rest) { a + b + len(rest) };
+		[f(1), f(1, 2), f(1, 2, 3, 4)];`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 11},
+			&object.Integer{Value: 3},
+			&object.Integer{Value: 5},
+		}},
+	},
+	{
+		name:           "failure - too few arguments for variadic function",
+		input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a, ...rest) { a }; f();",
+		expectedObject: &object.Error{Message: "wrong number of arguments: expected at least 1, got 0"},
+	},
+	{
+		name:           "success - try without error",
+		input:          "koshish_karo { 1 } pakad_lo (e) { 2 };",
+		expectedObject: &object.Integer{Value: 1},
+	},
+	{
+		name:  "success - catch exposes the error",
+		input: `koshish_karo { 1 / 0 } pakad_lo (e) { [e.kind, e.message, e.line, e.column] };`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.String{Value: "ZeroDivisionError"},
+			&object.String{Value: "division by zero"},
+			&object.Integer{Value: 1},
+			&object.Integer{Value: 18},
+		}},
+	},
+	{
+		name: "success - catch errors of called functions",
+		input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { fenk_do "oops" };
+		sun_liyo_tau x ne_bana_diye 1 + koshish_karo { 2 + f() } pakad_lo (e) { 10 };
+		x;`,
+		expectedObject: &object.Integer{Value: 11},
+	},
+	{
+		name:           "success - thrown messages have the Error kind",
+		input:          `koshish_karo { fenk_do "oops" } pakad_lo (e) { e.kind + ": " + e.message };`,
+		expectedObject: &object.String{Value: "Error: oops"},
+	},
+	{
+		name:           "success - rethrow keeps the kind",
+		input:          `koshish_karo { koshish_karo { x } pakad_lo (e) { fenk_do e } } pakad_lo (e) { e.kind };`,
+		expectedObject: &object.String{Value: "NameError"},
+	},
+	{
+		name:           "success - catch parameter doesn't overwrite a binding of the same name",
+		input:          `sun_liyo_tau e ne_bana_diye 5; koshish_karo { fenk_do "z" } pakad_lo (e) { e.message }; e;`,
+		expectedObject: &object.Integer{Value: 5},
+	},
+	{
+		name: "success - catch parameter shadows a local of the same name",
+		input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad() {
+			sun_liyo_tau e ne_bana_diye 1;
+			sun_liyo_tau caught ne_bana_diye koshish_karo { 1 / 0 } pakad_lo (e) { e.kind };
+			[e, caught]
+		};
+		f();`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 1},
+			&object.String{Value: "ZeroDivisionError"},
+		}},
+	},
+	{
+		name:           "success - closures capture the catch parameter",
+		input:          `sun_liyo_tau f ne_bana_diye koshish_karo { fenk_do "z" } pakad_lo (e) { tau_ka_jugaad() { e.message } }; f();`,
+		expectedObject: &object.String{Value: "z"},
+	},
+	{
+		name:           "failure - bindings of a catch block are local to it",
+		input:          `koshish_karo { fenk_do "z" } pakad_lo (e) { sun_liyo_tau y ne_bana_diye 1; }; y;`,
+		expectedObject: &object.Error{Message: "identifier not found: y"},
+	},
+	{
+		name: "success - finally runs on success and failure",
+		input: `sun_liyo_tau log ne_bana_diye [];
+		koshish_karo { 1 } aakhir_mein { log[0] ne_bana_diye "ok" };
+		koshish_karo { koshish_karo { 1 / 0 } aakhir_mein { log[1] ne_bana_diye "failed" } } pakad_lo (e) { 0 };
+		log;`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.String{Value: "ok"},
+			&object.String{Value: "failed"},
+		}},
+	},
+	{
+		name: "success - finally runs when returning from try",
+		input: `sun_liyo_tau log ne_bana_diye [];
+		sun_liyo_tau f ne_bana_diye tau_ka_jugaad() {
+			koshish_karo { laadle_ye_le 1; } aakhir_mein { log[0] ne_bana_diye "finally"; }
+			laadle_ye_le 2;
+		};
+		[f(), log];`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 1},
+			&object.Array{Elements: []object.Object{&object.String{Value: "finally"}}},
+		}},
+	},
+	{
+		name:           "success - return in finally overrides the error",
+		input:          `sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { koshish_karo { 1 / 0 } aakhir_mein { laadle_ye_le 2 } }; f();`,
+		expectedObject: &object.Integer{Value: 2},
+	},
+	{
+		name: "success - break and continue run finally",
+		input: `sun_liyo_tau i ne_bana_diye 0;
+		sun_liyo_tau n ne_bana_diye 0;
+		jab_tak (i < 5) {
+			i ne_bana_diye i + 1;
+			koshish_karo {
+				agar_maan_lo (i == 2) { jaan_de; }
+				agar_maan_lo (i == 4) { rok_diye; }
+				fenk_do "x";
+			} pakad_lo (e) {
+				n ne_bana_diye n + 1;
+			} aakhir_mein {
+				n ne_bana_diye n + 10;
+			}
+		};
+		[i, n];`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 4},
+			&object.Integer{Value: 42},
+		}},
+	},
+	{
+		name:           "failure - error in catch block",
+		input:          `koshish_karo { 1 / 0 } pakad_lo (e) { e.stack };`,
+		expectedObject: &object.Error{Message: "exception has no member: stack"},
+	},
+	{
+		name:           "failure - error without catch",
+		input:          `koshish_karo { fenk_do "oops" } aakhir_mein { 1 };`,
+		expectedObject: &object.Error{Message: "oops"},
+	},
+	{
+		name:           "failure - throw requires a message or exception",
+		input:          `fenk_do 1;`,
+		expectedObject: &object.Error{Message: "cannot throw INTEGER, expected STRING or EXCEPTION"},
+	},
+	{
+		name: "success - deep recursion",
+		input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(n) { agar_maan_lo (n == 0) { 0 } na_toh { 1 + f(n - 1) } };
+		f(5000);`,
+		expectedObject: &object.Integer{Value: 5000},
+	},
+	{
+		name:           "success - large array literal",
+		input:          "len([" + strings.Repeat("1, ", 2999) + "1]);",
+		expectedObject: &object.Integer{Value: 3000},
+	},
+	{
+		name: "success - large hash literal in nested calls",
+		input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(n) {
+			agar_maan_lo (n > 0) { laadle_ye_le f(n - 1); };
+			len({` + strings.Repeat(`"a": 1, `, 2999) + `"b": 2});
+		};
+		f(100);`,
+		expectedObject: &object.Integer{Value: 2},
+	},
+}
+
+func bigInteger(value string) *object.BigInteger {
+	b, _ := new(big.Int).SetString(value, 10)
+	return &object.BigInteger{Value: b}
+}
+
+func TestEvaluator(t *testing.T) {
+	for _, tc := range evaluatorTests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			program := p.Parse()
+			assert.NotNil(t, program)
+			errors := p.Errors()
+			assert.Empty(t, errors)
+
+			env := object.NewEnvironment()
+			o := evaluator.Eval(program, env)
+
+			// error positions are covered by TestEvaluatorErrorPositions
+			if expectedError, ok := tc.expectedObject.(*object.Error); ok {
+				actualError, ok := o.(*object.Error)
+				assert.True(t, ok, "expected error, got %s", o.Inspect())
+				if ok {
+					assert.Equal(t, expectedError.Message, actualError.Message)
+				}
+				return
+			}
+
+			assert.Equal(t, tc.expectedObject, o)
+		})
+	}
+}
+
+func TestEvaluatorErrorPositions(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedKind     object.ErrorKind
+		expectedError    string
+		expectedPosition token.Position
+		expectedStack    []object.StackFrame
+	}{
 		{
-			name:           "success - index expression - array 4",
-			input:          "[1, 2, 3][1 + 1];",
-			expectedObject: &object.Integer{Value: 3},
+			name:             "identifier not found",
+			input:            "sun_liyo_tau x ne_bana_diye 1;\nsun_liyo_tau y ne_bana_diye x + z;",
+			expectedKind:     object.NameError,
+			expectedError:    "identifier not found: z",
+			expectedPosition: token.Position{Line: 2, Column: 33, Offset: 63},
+		},
+		{
+			name:             "type mismatch points at the operator",
+			input:            `"a" + 1`,
+			expectedKind:     object.TypeError,
+			expectedError:    "type mismatch: STRING + INTEGER",
+			expectedPosition: token.Position{Line: 1, Column: 5, Offset: 4},
+		},
+		{
+			name: "error inside function body",
+			input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(x) {
+	laadle_ye_le -x;
+};
+f("tau");`,
+			expectedKind:     object.TypeError,
+			expectedError:    "unknown operator: -STRING",
+			expectedPosition: token.Position{Line: 2, Column: 15, Offset: 61},
+			expectedStack: []object.StackFrame{
+				{Function: "f", Position: token.Position{Line: 2, Column: 15, Offset: 61}},
+				{Position: token.Position{Line: 4, Column: 2, Offset: 69}},
+			},
 		},
 		{
-			name:           "success - index expression - array 5",
-			input:          "[1, 2, 3][3]",
-			expectedObject: &object.Null{},
+			name:             "index out of bounds",
+			input:            "sun_liyo_tau a ne_bana_diye [1, 2];\na[-3] ne_bana_diye 0;",
+			expectedKind:     object.IndexError,
+			expectedError:    "array index out of bounds: -3",
+			expectedPosition: token.Position{Line: 2, Column: 2, Offset: 37},
 		},
 		{
-			name:           "success - index expression - array 6",
-			input:          "[1, 2, 3][-1]",
-			expectedObject: &object.Null{},
+			name:             "arity errors belong to the caller",
+			input:            "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(x) { x };\nf();",
+			expectedKind:     object.ArgumentError,
+			expectedError:    "wrong number of arguments: expected 1, got 0",
+			expectedPosition: token.Position{Line: 2, Column: 2, Offset: 53},
 		},
 		{
-			name:           "success - index expression - hashmap 1",
-			input:          `{"foo": 5}["foo"]`,
-			expectedObject: &object.Integer{Value: 5},
+			name: "stack of nested calls",
+			input: `sun_liyo_tau inner ne_bana_diye tau_ka_jugaad(x) { x / 0 };
+sun_liyo_tau outer ne_bana_diye tau_ka_jugaad(x) { inner(x) };
+outer(1);`,
+			expectedKind:     object.ZeroDivisionError,
+			expectedError:    "division by zero",
+			expectedPosition: token.Position{Line: 1, Column: 54, Offset: 53},
+			expectedStack: []object.StackFrame{
+				{Function: "inner", Position: token.Position{Line: 1, Column: 54, Offset: 53}},
+				{Function: "outer", Position: token.Position{Line: 2, Column: 57, Offset: 116}},
+				{Position: token.Position{Line: 3, Column: 6, Offset: 128}},
+			},
 		},
 		{
-			name:           "success - index expression - hashmap 2",
-			input:          `{"foo": 5}["bar"]`,
-			expectedObject: &object.Null{},
+			name: "rethrown exceptions keep their position and stack",
+			input: `sun_liyo_tau inner ne_bana_diye tau_ka_jugaad(x) { x / 0 };
+sun_liyo_tau outer ne_bana_diye tau_ka_jugaad(x) { koshish_karo { inner(x) } pakad_lo (e) { fenk_do e } };
+outer(1);`,
+			expectedKind:     object.ZeroDivisionError,
+			expectedError:    "division by zero",
+			expectedPosition: token.Position{Line: 1, Column: 54, Offset: 53},
+			expectedStack: []object.StackFrame{
+				{Function: "inner", Position: token.Position{Line: 1, Column: 54, Offset: 53}},
+				{Function: "outer", Position: token.Position{Line: 2, Column: 72, Offset: 131}},
+				{Position: token.Position{Line: 3, Column: 6, Offset: 172}},
+			},
 		},
 		{
-			name:           "success - index expression - hashmap 3",
-			input:          `sun_liyo_tau key ne_bana_diye "foo"; {"foo": 5}[key]`,
-			expectedObject: &object.Integer{Value: 5},
+			name: "errors of defaults are inside the function",
+			input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a ne_bana_diye 1 / 0) { a };
+f();`,
+			expectedKind:     object.ZeroDivisionError,
+			expectedError:    "division by zero",
+			expectedPosition: token.Position{Line: 1, Column: 60, Offset: 59},
+			expectedStack: []object.StackFrame{
+				{Function: "f", Position: token.Position{Line: 1, Column: 60, Offset: 59}},
+				{Position: token.Position{Line: 2, Column: 2, Offset: 72}},
+			},
 		},
 		{
-			name:           "success - index expression - hashmap 4",
-			input:          `{}["foo"]`,
-			expectedObject: &object.Null{},
+			name: "anonymous functions have no name",
+			input: `sun_liyo_tau apply ne_bana_diye tau_ka_jugaad(f) { f() };
+apply(tau_ka_jugaad() { y });`,
+			expectedKind:     object.NameError,
+			expectedError:    "identifier not found: y",
+			expectedPosition: token.Position{Line: 2, Column: 25, Offset: 82},
+			expectedStack: []object.StackFrame{
+				{Position: token.Position{Line: 2, Column: 25, Offset: 82}},
+				{Function: "apply", Position: token.Position{Line: 1, Column: 53, Offset: 52}},
+				{Position: token.Position{Line: 2, Column: 6, Offset: 63}},
+			},
 		},
 		{
-			name:           "success - index expression - hashmap 5",
-			input:          `{5: 5}[5]`,
-			expectedObject: &object.Integer{Value: 5},
+			name: "functions called by builtins are called where the builtin is",
+			input: `sun_liyo_tau inv ne_bana_diye tau_ka_jugaad(x) { 1 / x };
+map([1, 0], inv);`,
+			expectedKind:     object.ZeroDivisionError,
+			expectedError:    "division by zero",
+			expectedPosition: token.Position{Line: 1, Column: 52, Offset: 51},
+			expectedStack: []object.StackFrame{
+				{Function: "inv", Position: token.Position{Line: 1, Column: 52, Offset: 51}},
+				{Position: token.Position{Line: 2, Column: 4, Offset: 61}},
+			},
 		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			program := p.Parse()
+			assert.Empty(t, p.Errors())
+
+			o := evaluator.Eval(program, object.NewEnvironment())
+			assert.Equal(t, &object.Error{
+				Kind:     tc.expectedKind,
+				Message:  tc.expectedError,
+				Position: tc.expectedPosition,
+				Stack:    tc.expectedStack,
+			}, o)
+		})
+	}
+}
+
+func TestArgumentSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     evaluator.ArgumentSpec
+		args     []object.Object
+		expected *object.Error
+	}{
 		{
-			name:           "success - index expression - hashmap 6",
-			input:          `{saccha: 5}[saccha]`,
-			expectedObject: &object.Integer{Value: 5},
+			name: "matching arguments",
+			spec: evaluator.ArgumentSpec{Name: "f", Required: []object.Type{object.STRING_OBJ, evaluator.AnyType}},
+			args: []object.Object{&object.String{Value: "a"}, &object.Integer{Value: 1}},
 		},
 		{
-			name:           "success - index expression - hashmap 7",
-			input:          `{jhootha: 5}[jhootha]`,
-			expectedObject: &object.Integer{Value: 5},
+			name:     "wrong number of arguments",
+			spec:     evaluator.ArgumentSpec{Name: "f", Required: []object.Type{object.STRING_OBJ}},
+			args:     []object.Object{},
+			expected: &object.Error{Kind: object.ArgumentError, Message: "wrong number of arguments. got=0, want=1"},
 		},
 		{
-			name:           "failure - index expression - array",
-			input:          "[1, 2, 3][saccha]",
-			expectedObject: &object.Error{Message: "index operator not supported: ARRAY[BOOLEAN]"},
+			name:     "too many optional arguments",
+			spec:     evaluator.ArgumentSpec{Name: "f", Required: []object.Type{object.STRING_OBJ}, Optional: []object.Type{object.INTEGER_OBJ}},
+			args:     []object.Object{&object.String{}, &object.Integer{}, &object.Integer{}},
+			expected: &object.Error{Kind: object.ArgumentError, Message: "wrong number of arguments. got=3, want=1 to 2"},
 		},
 		{
-			name:           "failure - index expression - hashmap",
-			input:          "{1: 5}[tau_ka_jugaad(x) {x}]",
-			expectedObject: &object.Error{Message: "unusable as hash key: FUNCTION"},
+			name:     "too few arguments with rest",
+			spec:     evaluator.ArgumentSpec{Name: "f", Required: []object.Type{object.STRING_OBJ}, Rest: object.INTEGER_OBJ},
+			args:     []object.Object{},
+			expected: &object.Error{Kind: object.ArgumentError, Message: "wrong number of arguments. got=0, want at least 1"},
 		},
 		{
-			name:           "success - builtin function - first",
-			input:          "first([1, 2, 3])",
-			expectedObject: &object.Integer{Value: 1},
+			name:     "wrong type of a rest argument",
+			spec:     evaluator.ArgumentSpec{Name: "f", Required: []object.Type{object.STRING_OBJ}, Rest: object.INTEGER_OBJ},
+			args:     []object.Object{&object.String{}, &object.Integer{}, &object.Float{}},
+			expected: &object.Error{Kind: object.TypeError, Message: "argument 3 to `f` must be INTEGER, got FLOAT"},
 		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.spec.Check(tc.args))
+		})
+	}
+}
+
+func TestEvalContext(t *testing.T) {
+	t.Parallel()
+
+	l, err := lexer.NewLexer(`jab_tak (saccha) { }`)
+	assert.NoError(t, err)
+	program := parser.NewParser(l).Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	env := object.NewModuleEnvironment(evaluator.NewModuleContext(""))
+	result := evaluator.EvalContext(ctx, program, env)
+	assert.Equal(t, &object.Error{
+		Kind:     object.InterruptedError,
+		Message:  "execution interrupted: context canceled",
+		Position: token.Position{Line: 1, Column: 10, Offset: 9},
+	}, result)
+}
+
+func TestCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "out.txt")
+
+	tests := []struct {
+		name         string
+		capabilities object.Capabilities
+		input        string
+		expected     object.Object
+	}{
 		{
-			name:           "success - builtin function - last",
-			input:          "last([1, 2, 3])",
-			expectedObject: &object.Integer{Value: 3},
+			name:         "allowed builtins can be called",
+			capabilities: object.NewCapabilities(object.FileReadCapability, object.FileWriteCapability),
+			input:        fmt.Sprintf(`write_file(%q, "tau"); read_file(%q)`, filename, filename),
+			expected:     &object.String{Value: "tau"},
 		},
 		{
-			name:           "success - builtin function - last",
-			input:          "last(push([1, 2, 3], 4))",
-			expectedObject: &object.Integer{Value: 4},
+			name:         "builtins without a capability can always be called",
+			capabilities: object.NewCapabilities(),
+			input:        `len("tau")`,
+			expected:     &object.Integer{Value: 3},
 		},
 		{
-			name: "success - hashmap",
-			input: `sun_liyo_tau two ne_bana_diye "two";
-			{
-				"one": 10 - 9,
-				two: 1 + 1,
-				"thr" + "ee": 6 / 2,
-				4: 4,
-				saccha: 5,
-				jhootha: 6
-			}`,
-			expectedObject: &object.HashMap{
-				Pairs: map[object.HashKey]object.HashPair{
-					(&object.String{Value: "one"}).Hash(): {
-						Key:   &object.String{Value: "one"},
-						Value: &object.Integer{Value: 1},
-					},
-					(&object.String{Value: "two"}).Hash(): {
-						Key:   &object.String{Value: "two"},
-						Value: &object.Integer{Value: 2},
-					},
-					(&object.String{Value: "three"}).Hash(): {
-						Key:   &object.String{Value: "three"},
-						Value: &object.Integer{Value: 3},
-					},
-					(&object.Integer{Value: 4}).Hash(): {
-						Key:   &object.Integer{Value: 4},
-						Value: &object.Integer{Value: 4},
-					},
-					evaluator.TRUE.Hash(): {
-						Key:   &object.Boolean{Value: true},
-						Value: &object.Integer{Value: 5},
-					},
-					evaluator.FALSE.Hash(): {
-						Key:   &object.Boolean{Value: false},
-						Value: &object.Integer{Value: 6},
-					},
-				},
+			name:         "disallowed builtin",
+			capabilities: object.Profiles["safe"],
+			input:        fmt.Sprintf(`write_file(%q, "tau")`, filename),
+			expected: &object.Error{
+				Kind:     object.PermissionError,
+				Message:  "permission denied: `write_file` needs the fs_write capability",
+				Position: token.Position{Line: 1, Column: 11, Offset: 10},
 			},
 		},
 		{
-			name:           "failure - hashmap",
-			input:          `{tau_ka_jugaad(x) { x; }: 5}`,
-			expectedObject: &object.Error{Message: "unusable as hash key: FUNCTION"},
-		},
-		{
-			name: "success - hash index assignment",
-			input: `sun_liyo_tau map ne_bana_diye {"one": 1, "two": 2};
-			map["three"] ne_bana_diye 3;
-			map["three"];`,
-			expectedObject: &object.Integer{Value: 3},
-		},
-		{
-			name: "success - hash index assignment - overwrite",
-			input: `sun_liyo_tau map ne_bana_diye {"one": 1, "two": 2};
-			map["one"] ne_bana_diye 10;
-			map["one"];`,
-			expectedObject: &object.Integer{Value: 10},
-		},
-		{
-			name: "success - array index assignment",
-			input: `sun_liyo_tau arr ne_bana_diye [1, 2, 3];
-			arr[1] ne_bana_diye 20;
-			arr[1];`,
-			expectedObject: &object.Integer{Value: 20},
-		},
-		{
-			name: "success - array index assignment - extend",
-			input: `sun_liyo_tau arr ne_bana_diye [1, 2];
-			arr[5] ne_bana_diye 10;
-			arr[5];`,
-			expectedObject: &object.Integer{Value: 10},
+			name:         "disallowed builtin called by another builtin",
+			capabilities: object.NewCapabilities(),
+			input:        `map([1], print)`,
+			expected: &object.Error{
+				Kind:     object.PermissionError,
+				Message:  "permission denied: `print` needs the print capability",
+				Position: token.Position{Line: 1, Column: 4, Offset: 3},
+			},
 		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+			program := parser.NewParser(l).Parse()
+
+			context := evaluator.NewModuleContext("")
+			context.Capabilities = tc.capabilities
+			assert.Equal(t, tc.expected, evaluator.Eval(program, object.NewModuleEnvironment(context)))
+		})
+	}
+}
+
+func TestStreams(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		stdin          string
+		expected       object.Object
+		expectedStdout string
+		expectedStderr string
+	}{
 		{
-			name: "success - array index assignment - verify other elements",
-			input: `sun_liyo_tau arr ne_bana_diye [1, 2];
-			arr[5] ne_bana_diye 10;
-			arr[0];`,
-			expectedObject: &object.Integer{Value: 1},
+			name:           "print and eprint",
+			input:          `print("out", 1); eprint("err")`,
+			expected:       &object.Null{},
+			expectedStdout: "out\n1\n",
+			expectedStderr: "err\n",
 		},
 		{
-			name:           "failure - index assignment on undefined variable",
-			input:          `map["key"] ne_bana_diye 1;`,
-			expectedObject: &object.Error{Message: "identifier not found: map"},
+			name:           "printf doesn't add a line break",
+			input:          `printf("%s=%d", "x", 1); printf("!")`,
+			expected:       &object.Null{},
+			expectedStdout: "x=1!",
 		},
 		{
-			name:           "failure - index assignment on non-indexable type",
-			input:          `sun_liyo_tau x ne_bana_diye 5; x[0] ne_bana_diye 1;`,
-			expectedObject: &object.Error{Message: "index assignment not supported for type: INTEGER"},
+			name:           "input prints the prompt",
+			input:          `[input("name? "), read_line()]`,
+			stdin:          "tau\r\nlang",
+			expected:       &object.Array{Elements: []object.Object{&object.String{Value: "tau"}, &object.String{Value: "lang"}}},
+			expectedStdout: "name? ",
 		},
 		{
-			name:           "success - no executable code only comments",
-			input:          `// sun_liyo_tau x ne_bana_diye 5; x[0] ne_bana_diye 1;`,
-			expectedObject: &object.Null{},
+			name:     "input is null at the end",
+			input:    `[read_line(), read_line()]`,
+			stdin:    "\n",
+			expected: &object.Array{Elements: []object.Object{&object.String{Value: ""}, &object.Null{}}},
 		},
 	}
 
@@ -785,16 +2274,15 @@ func TestEvaluator(t *testing.T) {
 
 			l, err := lexer.NewLexer(tc.input)
 			assert.NoError(t, err)
+			program := parser.NewParser(l).Parse()
 
-			p := parser.NewParser(l)
-			program := p.Parse()
-			assert.NotNil(t, program)
-			errors := p.Errors()
-			assert.Empty(t, errors)
+			var stdout, stderr strings.Builder
+			context := evaluator.NewModuleContext("")
+			context.Streams = object.NewStreams(&stdout, &stderr, strings.NewReader(tc.stdin))
 
-			env := object.NewEnvironment()
-			o := evaluator.Eval(program, env)
-			assert.Equal(t, tc.expectedObject, o)
+			assert.Equal(t, tc.expected, evaluator.Eval(program, object.NewModuleEnvironment(context)))
+			assert.Equal(t, tc.expectedStdout, stdout.String())
+			assert.Equal(t, tc.expectedStderr, stderr.String())
 		})
 	}
 }
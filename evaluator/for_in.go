@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"slices"
+	"taulang/ast"
+	"taulang/object"
+)
+
+// evalForInExpression binds the loop variables in the enclosing environment
+// like sun_liyo_tau does, the value of the loop is that of the last body like
+// for while loops
+func evalForInExpression(node *ast.ForInExpression, env object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	iterator, err := NewIterator(iterable)
+	if err != nil {
+		return err
+	}
+
+	var result object.Object = NULL
+	for {
+		values, ok := iterator.Bind(len(node.Variables))
+		if !ok {
+			break
+		}
+		for idx, variable := range node.Variables {
+			env.Set(variable.Value, values[idx])
+		}
+
+		result = Eval(node.Body, env)
+		if isError(result) || isReturnValue(result) {
+			return result
+		}
+
+		if isBreak(result) {
+			return NULL
+		}
+
+		if isContinue(result) {
+			result = NULL
+		}
+	}
+	return result
+}
+
+// NewIterator returns an iterator over the elements of an array, the keys and
+// values of a hash map, the characters of a string or the integers of a range.
+// Arrays and hash maps are iterated as they were when the loop started, values
+// set in the loop are seen but added elements aren't.
+func NewIterator(iterable object.Object) (*object.Iterator, *object.Error) {
+	var index int64
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		// the elements are read on every step as growing the array replaces them
+		length := int64(len(iterable.Elements))
+		return &object.Iterator{Next: func() (object.Object, object.Object, bool) {
+			if index >= min(length, int64(len(iterable.Elements))) {
+				return nil, nil, false
+			}
+			index++
+			return &object.Integer{Value: index - 1}, iterable.Elements[index-1], true
+		}}, nil
+	case *object.HashMap:
+		order := slices.Clone(iterable.Order)
+		return &object.Iterator{KeyOnly: true, Next: func() (object.Object, object.Object, bool) {
+			// keys deleted since the loop started are skipped
+			for index < int64(len(order)) {
+				pair, ok := iterable.Get(order[index])
+				index++
+				if ok {
+					return pair.Key, pair.Value, true
+				}
+			}
+			return nil, nil, false
+		}}, nil
+	case *object.String:
+		runes := []rune(iterable.Value)
+		return &object.Iterator{Next: func() (object.Object, object.Object, bool) {
+			if index >= int64(len(runes)) {
+				return nil, nil, false
+			}
+			index++
+			return &object.Integer{Value: index - 1}, &object.String{Value: string(runes[index-1])}, true
+		}}, nil
+	case *object.Range:
+		length := iterable.Len()
+		return &object.Iterator{Next: func() (object.Object, object.Object, bool) {
+			if index >= length {
+				return nil, nil, false
+			}
+			index++
+			return &object.Integer{Value: index - 1}, &object.Integer{Value: iterable.At(index - 1)}, true
+		}}, nil
+	default:
+		return nil, newError(object.TypeError, "cannot iterate over %s", iterable.Type())
+	}
+}
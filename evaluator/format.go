@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"taulang/object"
+)
+
+// formatString formats the arguments like Go's fmt.Sprintf, the verbs are
+//
+//	%d %b %o %x %X %c  INTEGER
+//	%f %e %g %F %E %G  INTEGER or FLOAT
+//	%t                 BOOLEAN
+//	%s %q %v           any value, strings without quotes for %s and %v
+//	%%                 a percent sign
+//
+// with Go's flags, width and precision, e.g. "%-8s|%6.2f"
+func formatString(format string, args []object.Object) (string, *object.Error) {
+	var out strings.Builder
+	argIdx := 0
+
+	for idx := 0; idx < len(format); idx++ {
+		if format[idx] != '%' {
+			out.WriteByte(format[idx])
+			continue
+		}
+
+		// the flags, width and precision run up to the verb
+		start := idx
+		idx++
+		for idx < len(format) && strings.IndexByte("+-# 0123456789.", format[idx]) != -1 {
+			idx++
+		}
+		if idx == len(format) {
+			return "", newError(object.ValueError, "format string ends in an incomplete verb: %s", format[start:])
+		}
+
+		verb := format[idx]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+
+		if argIdx == len(args) {
+			return "", newError(object.ArgumentError, "not enough arguments for format string, got %d", len(args))
+		}
+		value, err := formatValue(verb, args[argIdx])
+		if err != nil {
+			return "", err
+		}
+		argIdx++
+
+		fmt.Fprintf(&out, format[start:idx+1], value)
+	}
+
+	if argIdx != len(args) {
+		return "", newError(object.ArgumentError, "too many arguments for format string, want %d, got %d", argIdx, len(args))
+	}
+
+	return out.String(), nil
+}
+
+// formatValue returns the Go value the verb formats for the argument
+func formatValue(verb byte, arg object.Object) (any, *object.Error) {
+	switch verb {
+	case 'd', 'b', 'o', 'x', 'X', 'c':
+		if !isInteger(arg) {
+			return nil, newError(object.TypeError, "format verb %%%c needs INTEGER, got %s", verb, arg.Type())
+		}
+		if integer, ok := arg.(*object.Integer); ok {
+			return integer.Value, nil
+		}
+		if verb == 'c' {
+			return nil, newError(object.ValueError, "format verb %%c needs a character code, got %s", arg.Inspect())
+		}
+		return toBigInt(arg), nil
+	case 'f', 'e', 'g', 'F', 'E', 'G':
+		if !isNumber(arg) {
+			return nil, newError(object.TypeError, "format verb %%%c needs FLOAT, got %s", verb, arg.Type())
+		}
+		return toFloat(arg).Value, nil
+	case 't':
+		boolean, ok := arg.(*object.Boolean)
+		if !ok {
+			return nil, newError(object.TypeError, "format verb %%t needs BOOLEAN, got %s", arg.Type())
+		}
+		return boolean.Value, nil
+	case 's', 'q', 'v':
+		if str, ok := arg.(*object.String); ok {
+			return str.Value, nil
+		}
+		return arg.Inspect(), nil
+	default:
+		return nil, newError(object.ValueError, "unknown format verb %%%c", verb)
+	}
+}
@@ -0,0 +1,105 @@
+package evaluator
+
+import (
+	"taulang/object"
+)
+
+// hashMapBuiltins work on hash maps, they return pairs in insertion order and
+// like the array builtins return new hash maps rather than changing the ones
+// they are given
+var hashMapBuiltins = map[string]*object.Builtin{
+	"keys": hashMapElements("keys", func(pair object.HashPair) object.Object {
+		return pair.Key
+	}),
+	"values": hashMapElements("values", func(pair object.HashPair) object.Object {
+		return pair.Value
+	}),
+	"items": hashMapElements("items", func(pair object.HashPair) object.Object {
+		return &object.Array{Elements: []object.Object{pair.Key, pair.Value}}
+	}),
+	"has": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+			if err := checkArguments("has", args[:1], object.HASHMAP_OBJ); err != nil {
+				return err
+			}
+
+			hash, err := hashOf(args[1])
+			if err != nil {
+				return err
+			}
+			_, ok := args[0].(*object.HashMap).Get(hash)
+			return getBoolObject(ok)
+		},
+	},
+	"delete": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+			if err := checkArguments("delete", args[:1], object.HASHMAP_OBJ); err != nil {
+				return err
+			}
+
+			hash, err := hashOf(args[1])
+			if err != nil {
+				return err
+			}
+			hashMap := args[0].(*object.HashMap).Copy()
+			hashMap.Delete(hash)
+			return hashMap
+		},
+	},
+	"merge": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=0, want at least 1")
+			}
+
+			// later hash maps win, keys keep the place where they were first seen
+			merged := object.NewHashMap()
+			for idx, arg := range args {
+				hashMap, ok := arg.(*object.HashMap)
+				if !ok {
+					return newError(object.TypeError, "argument %d to `merge` must be %s, got %s",
+						idx+1, object.HASHMAP_OBJ, arg.Type())
+				}
+				for _, hash := range hashMap.Order {
+					merged.Set(hash, hashMap.Pairs[hash])
+				}
+			}
+			return merged
+		},
+	},
+}
+
+// hashMapElements creates a builtin that returns an array with an element for
+// every pair of a hash map
+func hashMapElements(name string, element func(object.HashPair) object.Object) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments(name, args, object.HASHMAP_OBJ); err != nil {
+				return err
+			}
+
+			pairs := args[0].(*object.HashMap).Ordered()
+			elements := make([]object.Object, len(pairs))
+			for idx, pair := range pairs {
+				elements[idx] = element(pair)
+			}
+			return &object.Array{Elements: elements}
+		},
+	}
+}
+
+func hashOf(key object.Object) (object.HashKey, *object.Error) {
+	hashable, ok := key.(object.Hashable)
+	if !ok {
+		return object.HashKey{}, newError(object.TypeError, "unusable as hash key: %s", key.Type())
+	}
+	return hashable.Hash(), nil
+}
@@ -0,0 +1,124 @@
+package evaluator
+
+import (
+	"context"
+	"math"
+	"taulang/ast"
+	"taulang/object"
+)
+
+// Approximate sizes of the parts of objects, used to account for allocations
+const (
+	elementSize  = 16
+	hashPairSize = 64
+)
+
+// EvalContext is Eval for a new run, which stops with an error once ctx is
+// done or the run exceeds the limits of the budget of the environment. Runs in
+// environments without a module context are not limited.
+func EvalContext(ctx context.Context, node ast.Node, env object.Environment) object.Object {
+	if budget := budgetOf(env); budget != nil {
+		budget.Start(ctx)
+	}
+
+	return Eval(node, env)
+}
+
+func budgetOf(env object.Environment) *object.Budget {
+	if context := env.Context(); context != nil {
+		return context.Budget
+	}
+	return nil
+}
+
+// allocate accounts for the memory of an object the run created, it returns
+// the object or the error when the run exceeds its allocation limit
+func allocate(env object.Environment, obj object.Object) object.Object {
+	return Allocate(obj, budgetOf(env))
+}
+
+// Allocate is allocate for engines that track the budget themselves, runs
+// without a budget are not limited
+func Allocate(obj object.Object, budget *object.Budget) object.Object {
+	if budget == nil {
+		return obj
+	}
+
+	var size int64
+	switch obj := obj.(type) {
+	case *object.String:
+		size = int64(len(obj.Value))
+	case *object.Array:
+		size = int64(len(obj.Elements)) * elementSize
+	case *object.HashMap:
+		size = int64(obj.Len()) * hashPairSize
+	default:
+		return obj
+	}
+
+	if err := budget.Allocate(size); err != nil {
+		return err
+	}
+	return obj
+}
+
+// checkAllocation returns an error when the run can't allocate size more bytes,
+// without accounting for them
+func checkAllocation(env object.Environment, size int64) *object.Error {
+	if budget := budgetOf(env); budget != nil {
+		return budget.CheckAllocation(size)
+	}
+	return nil
+}
+
+// evalInfixOperator is EvalInfixOperator for a run, concatenated strings are
+// checked against the allocation limit before they are built
+func evalInfixOperator(operator string, left object.Object, right object.Object, env object.Environment) object.Object {
+	return EvalInfixOperatorWithBudget(operator, left, right, budgetOf(env))
+}
+
+// EvalInfixOperatorWithBudget is evalInfixOperator for engines that track the
+// budget themselves
+func EvalInfixOperatorWithBudget(operator string, left object.Object, right object.Object, budget *object.Budget) object.Object {
+	leftStr, leftOk := left.(*object.String)
+	rightStr, rightOk := right.(*object.String)
+	if budget != nil && operator == "+" && leftOk && rightOk {
+		if err := budget.CheckAllocation(int64(len(leftStr.Value)) + int64(len(rightStr.Value))); err != nil {
+			return err
+		}
+	}
+
+	return Allocate(EvalInfixOperator(operator, left, right), budget)
+}
+
+// AssignIndexWithBudget is AssignIndex for a run, the growth of arrays is
+// accounted for before the elements are added and so are new hash map pairs
+func AssignIndexWithBudget(indexedObject object.Object, index object.Object, value object.Object, budget *object.Budget) object.Object {
+	if budget == nil {
+		return AssignIndex(indexedObject, index, value)
+	}
+
+	// arrays grow up to an index past their end
+	if array, ok := indexedObject.(*object.Array); ok {
+		if index, ok := index.(*object.Integer); ok && index.Value >= int64(len(array.Elements)) {
+			growth := index.Value - int64(len(array.Elements)) + 1
+			if err := budget.Allocate(min(growth, math.MaxInt64/elementSize) * elementSize); err != nil {
+				return err
+			}
+		}
+	}
+
+	if hashMap, ok := indexedObject.(*object.HashMap); ok {
+		size := hashMap.Len()
+		result := AssignIndex(hashMap, index, value)
+		if isError(result) || hashMap.Len() == size {
+			return result
+		}
+		if err := budget.Allocate(hashPairSize); err != nil {
+			return err
+		}
+		return result
+	}
+
+	return AssignIndex(indexedObject, index, value)
+}
@@ -0,0 +1,91 @@
+package evaluator
+
+import (
+	"taulang/ast"
+	"taulang/module"
+	"taulang/object"
+)
+
+// NewModuleContext returns the context for evaluating the file filename, the
+// files it imports are evaluated once per program by the tree walker
+func NewModuleContext(filename string) *object.ModuleContext {
+	return NewModuleContextWithBuiltins(filename, nil)
+}
+
+// NewModuleContextWithBuiltins is NewModuleContext for programs that can use
+// the host builtins, the files they import can use them too. Runs are limited
+// by the budget of the context, which has the default limits.
+func NewModuleContextWithBuiltins(filename string, builtins object.BuiltinLookup) *object.ModuleContext {
+	context := &object.ModuleContext{Filename: filename, Builtins: builtins, Budget: object.NewBudget(object.Limits{})}
+
+	// imported files share the builtins, the budget, the capabilities and the
+	// streams of the program, which can be replaced after the context is
+	// created
+	context.Loader = module.NewLoaderWithEntry(filename, func(filename string, program *ast.Program) (object.Environment, *object.Error) {
+		env := object.NewModuleEnvironment(&object.ModuleContext{
+			Filename:     filename,
+			Loader:       context.Loader,
+			Builtins:     context.Builtins,
+			Budget:       context.Budget,
+			Capabilities: context.Capabilities,
+			Streams:      context.Streams,
+		})
+
+		if err, ok := Eval(program, env).(*object.Error); ok {
+			return nil, err
+		}
+
+		return env, nil
+	})
+
+	return context
+}
+
+func evalImportExpression(path ast.Expression, env object.Environment) object.Object {
+	evaluatedPath := Eval(path, env)
+	if isError(evaluatedPath) {
+		return evaluatedPath
+	}
+
+	pathString, ok := evaluatedPath.(*object.String)
+	if !ok {
+		return newError(object.TypeError, "import path must be a STRING, got %s", evaluatedPath.Type())
+	}
+
+	return ImportModule(pathString.Value, env.Context())
+}
+
+// ImportModule loads the module at path for the file described by context,
+// importing reads the file so it needs the fs_read capability
+func ImportModule(path string, context *object.ModuleContext) object.Object {
+	if context == nil || context.Loader == nil {
+		return newError(object.ImportError, "imports are not available in this environment")
+	}
+	if !context.Capabilities.Allows(object.FileReadCapability) {
+		return newError(object.PermissionError, "permission denied: `mangwa_lo` needs the %s capability",
+			object.FileReadCapability)
+	}
+
+	return context.Loader.Load(path, context.Filename)
+}
+
+func evalMemberExpression(expression ast.Expression, member *ast.Identifier, env object.Environment) object.Object {
+	evaluatedObject := Eval(expression, env)
+	if isError(evaluatedObject) {
+		return evaluatedObject
+	}
+
+	return EvalIndex(evaluatedObject, &object.String{Value: member.Value})
+}
+
+func evalModuleIndexExpression(indexedObject object.Object, index object.Object) object.Object {
+	module := indexedObject.(*object.Module)
+	name := index.(*object.String).Value
+
+	member, ok := module.Member(name)
+	if !ok {
+		return newError(object.NameError, "module %s has no member: %s", module.Name, name)
+	}
+
+	return member
+}
@@ -0,0 +1,171 @@
+package evaluator_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"taulang/evaluator"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// moduleFixtures are written to a temporary directory for the import tests
+var moduleFixtures = map[string]string{
+	"math.tau": `sun_liyo_tau pi ne_bana_diye 3;
+sun_liyo_tau add ne_bana_diye tau_ka_jugaad(a, b) { a + b };
+sun_liyo_tau double ne_bana_diye tau_ka_jugaad(a) { add(a, a) };`,
+	"lib/geometry.tau": `sun_liyo_tau math ne_bana_diye mangwa_lo "../math";
+sun_liyo_tau circumference ne_bana_diye tau_ka_jugaad(r) { 2 * math.pi * r };`,
+	"counter.tau": `sun_liyo_tau count ne_bana_diye [0];
+count[0] ne_bana_diye count[0] + 1;`,
+	"broken.tau": `sun_liyo_tau x ne_bana_diye 1;
+x + "tau";`,
+	"cycle_a.tau": `mangwa_lo "./cycle_b.tau";`,
+	"cycle_b.tau": `mangwa_lo "./cycle_a.tau";`,
+}
+
+var importTests = []evaluatorTestCase{
+	{
+		name:           "success - dot access to module members",
+		input:          `sun_liyo_tau m ne_bana_diye mangwa_lo "./math.tau"; m.add(m.pi, 1);`,
+		expectedObject: &object.Integer{Value: 4},
+	},
+	{
+		name:           "success - index access to module members",
+		input:          `sun_liyo_tau m ne_bana_diye mangwa_lo "math"; m["double"](5);`,
+		expectedObject: &object.Integer{Value: 10},
+	},
+	{
+		name:           "success - nested imports resolve relative to the importing file",
+		input:          `sun_liyo_tau g ne_bana_diye mangwa_lo "./lib/geometry.tau"; g.circumference(2);`,
+		expectedObject: &object.Integer{Value: 12},
+	},
+	{
+		name: "success - modules are evaluated once",
+		input: `sun_liyo_tau a ne_bana_diye mangwa_lo "./counter.tau";
+		sun_liyo_tau b ne_bana_diye mangwa_lo "counter";
+		[a == b, b.count[0]];`,
+		expectedObject: &object.Array{Elements: []object.Object{
+			&object.Boolean{Value: true},
+			&object.Integer{Value: 1},
+		}},
+	},
+	{
+		name:           "failure - missing module member",
+		input:          `(mangwa_lo "./math.tau").nope;`,
+		expectedObject: &object.Error{Message: "module math.tau has no member: nope"},
+	},
+	{
+		name:           "failure - missing file",
+		input:          `mangwa_lo "./missing.tau";`,
+		expectedObject: &object.Error{Message: `cannot import "./missing.tau": file not found`},
+	},
+	{
+		name:           "failure - error inside module",
+		input:          `mangwa_lo "./broken.tau";`,
+		expectedObject: &object.Error{Message: "error in module broken.tau at 2:3: type mismatch: INTEGER + STRING"},
+	},
+	{
+		name:           "failure - import cycle",
+		input:          `mangwa_lo "./cycle_a.tau";`,
+		expectedObject: &object.Error{Message: "error in module cycle_a.tau at 1:1: error in module cycle_b.tau at 1:1: import cycle: cycle_a.tau -> cycle_b.tau -> cycle_a.tau"},
+	},
+	{
+		name:           "failure - import path must be a string",
+		input:          `mangwa_lo 42;`,
+		expectedObject: &object.Error{Message: "import path must be a STRING, got INTEGER"},
+	},
+}
+
+func TestEvaluatorImports(t *testing.T) {
+	dir := writeModuleFixtures(t)
+
+	for _, tc := range importTests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			program := p.Parse()
+			assert.Empty(t, p.Errors())
+
+			env := object.NewModuleEnvironment(evaluator.NewModuleContext(filepath.Join(dir, "main.tau")))
+			o := evaluator.Eval(program, env)
+
+			if expectedError, ok := tc.expectedObject.(*object.Error); ok {
+				actualError, ok := o.(*object.Error)
+				assert.True(t, ok, "expected error, got %s", o.Inspect())
+				if ok {
+					assert.Equal(t, expectedError.Message, actualError.Message)
+				}
+				return
+			}
+
+			assert.Equal(t, tc.expectedObject, o)
+		})
+	}
+}
+
+func TestEvaluatorImportsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	l, err := lexer.NewLexer(`mangwa_lo "./math.tau";`)
+	assert.NoError(t, err)
+
+	program := parser.NewParser(l).Parse()
+	o := evaluator.Eval(program, object.NewEnvironment())
+	assert.Equal(t, "ImportError: imports are not available in this environment", o.Inspect())
+}
+
+func TestEvaluatorImportCycleThroughTheEntryFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	source := `print("c1"); mangwa_lo "./c2.tau";`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c1.tau"), []byte(source), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c2.tau"), []byte(`mangwa_lo "./c1.tau";`), 0o644))
+
+	l, err := lexer.NewLexer(source)
+	assert.NoError(t, err)
+
+	var stdout strings.Builder
+	context := evaluator.NewModuleContext(filepath.Join(dir, "c1.tau"))
+	context.Streams = object.NewStreams(&stdout, io.Discard, strings.NewReader(""))
+	o := evaluator.Eval(parser.NewParser(l).Parse(), object.NewModuleEnvironment(context))
+
+	// the entry file doesn't run again when it is imported
+	assert.Equal(t, "c1\n", stdout.String())
+	assert.Equal(t, "ImportError: error in module c2.tau at 1:1: import cycle: c1.tau -> c2.tau -> c1.tau", o.Inspect())
+}
+
+func TestEvaluatorImportsNeedFileReadCapability(t *testing.T) {
+	t.Parallel()
+
+	l, err := lexer.NewLexer(`mangwa_lo "/etc/host.conf";`)
+	assert.NoError(t, err)
+
+	context := evaluator.NewModuleContext("main.tau")
+	context.Capabilities = object.Profiles["safe"]
+	o := evaluator.Eval(parser.NewParser(l).Parse(), object.NewModuleEnvironment(context))
+	assert.Equal(t, "PermissionError: permission denied: `mangwa_lo` needs the fs_read capability", o.Inspect())
+}
+
+func writeModuleFixtures(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range moduleFixtures {
+		filename := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+		assert.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	}
+
+	return dir
+}
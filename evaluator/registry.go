@@ -0,0 +1,73 @@
+package evaluator
+
+import (
+	"fmt"
+	"sort"
+	"taulang/object"
+)
+
+// Registry holds the builtins a host adds to one interpreter next to the core
+// builtins. Builtins are registered either under their own name or as members
+// of a namespace, which programs reach like a module, e.g. `geo.distance(a, b)`.
+// A registry must not be changed while programs using it run.
+type Registry struct {
+	builtins map[string]object.Object
+}
+
+func NewRegistry() *Registry {
+	return &Registry{builtins: map[string]object.Object{}}
+}
+
+// Register adds the builtin name, which must not be taken by a core builtin
+// or another registered builtin or namespace
+func (r *Registry) Register(name string, builtin *object.Builtin) error {
+	if err := r.checkAvailable(name); err != nil {
+		return err
+	}
+
+	r.builtins[name] = builtin
+	return nil
+}
+
+// RegisterNamespace adds the builtins as the members of the namespace, their
+// names can be the same as the ones of core builtins
+func (r *Registry) RegisterNamespace(namespace string, builtins map[string]*object.Builtin) error {
+	if err := r.checkAvailable(namespace); err != nil {
+		return err
+	}
+
+	env := object.NewEnvironment()
+	for name, builtin := range builtins {
+		env.Set(name, builtin)
+	}
+
+	r.builtins[namespace] = &object.Module{Name: namespace, Env: env}
+	return nil
+}
+
+func (r *Registry) checkAvailable(name string) error {
+	if _, ok := builtins[name]; ok {
+		return fmt.Errorf("%s is already a core builtin", name)
+	}
+	if _, ok := r.builtins[name]; ok {
+		return fmt.Errorf("%s is already registered", name)
+	}
+	return nil
+}
+
+// Lookup returns the builtin or the namespace called name
+func (r *Registry) Lookup(name string) (object.Object, bool) {
+	builtin, ok := r.builtins[name]
+	return builtin, ok
+}
+
+// Names returns the names of the registered builtins and namespaces in sorted
+// order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.builtins))
+	for name := range r.builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,176 @@
+package evaluator
+
+import (
+	"strings"
+	"taulang/object"
+	"unicode"
+)
+
+// maxStringLength bounds the strings builtins build from a count, so that a
+// large count fails with an error instead of exhausting the memory
+const maxStringLength = 1 << 30
+
+// stringBuiltins work on strings, indexes into strings count runes so that
+// text in any script behaves the same
+var stringBuiltins = map[string]*object.Builtin{
+	"split": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("split", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			parts := strings.Split(args[0].(*object.String).Value, args[1].(*object.String).Value)
+			return stringsToArray(parts)
+		},
+	},
+	"join": &object.Builtin{
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("join", args, object.ARRAY_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			elements, sep := args[0].(*object.Array).Elements, args[1].(*object.String).Value
+			parts := make([]string, len(elements))
+			size := int64(len(sep)) * int64(max(len(elements)-1, 0))
+			for idx, element := range elements {
+				str, ok := element.(*object.String)
+				if !ok {
+					return newError(object.TypeError, "elements joined by `join` must be STRING, got %s at %d",
+						element.Type(), idx)
+				}
+				parts[idx] = str.Value
+				size += int64(len(str.Value))
+			}
+			if err := ctx.CheckAllocation(size); err != nil {
+				return err
+			}
+
+			return &object.String{Value: strings.Join(parts, sep)}
+		},
+	},
+	"trim":       stringTransform("trim", strings.TrimSpace),
+	"trim_start": stringTransform("trim_start", func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) }),
+	"trim_end":   stringTransform("trim_end", func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) }),
+	"upper":      stringTransform("upper", strings.ToUpper),
+	"lower":      stringTransform("lower", strings.ToLower),
+	"starts_with": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("starts_with", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			return getBoolObject(strings.HasPrefix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+		},
+	},
+	"ends_with": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("ends_with", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			return getBoolObject(strings.HasSuffix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+		},
+	},
+	"replace": &object.Builtin{
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("replace", args, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			str, old, replacement := args[0].(*object.String).Value, args[1].(*object.String).Value, args[2].(*object.String).Value
+			size := int64(len(str)) + int64(strings.Count(str, old))*int64(len(replacement)-len(old))
+			if err := ctx.CheckAllocation(size); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ReplaceAll(str, old, replacement)}
+		},
+	},
+	// format formats the arguments with printf-style verbs, e.g.
+	// format("%-5s|%6.2f", "tau", 3.14159) is "tau  |  3.14"
+	"format": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := (ArgumentSpec{Name: "format", Required: []object.Type{object.STRING_OBJ}, Rest: AnyType}).Check(args); err != nil {
+				return err
+			}
+
+			formatted, err := formatString(args[0].(*object.String).Value, args[1:])
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: formatted}
+		},
+	},
+	"repeat": &object.Builtin{
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("repeat", args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			str, count := args[0].(*object.String).Value, args[1].(*object.Integer).Value
+			if count < 0 {
+				return newError(object.ValueError, "count passed to `repeat` must not be negative, got %d", count)
+			}
+			// the length is checked by dividing, multiplying could overflow
+			if len(str) != 0 && count > maxStringLength/int64(len(str)) {
+				return newError(object.ValueError, "`repeat` would create a string longer than %d bytes", maxStringLength)
+			}
+			if err := ctx.CheckAllocation(int64(len(str)) * count); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.Repeat(str, int(count))}
+		},
+	},
+	"substring": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			// the end is optional, the substring runs to the end of the string then
+			if len(args) == 2 {
+				args = append(args, NULL)
+			}
+			if len(args) != 3 {
+				return newError(object.ArgumentError, "wrong number of arguments. got=%d, want=2 or 3",
+					len(args))
+			}
+			if args[0].Type() != object.STRING_OBJ {
+				return newError(object.TypeError, "argument 1 to `substring` must be STRING, got %s",
+					args[0].Type())
+			}
+
+			return EvalSlice(args[0], args[1], args[2])
+		},
+	},
+	"chars": &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("chars", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			runes := []rune(args[0].(*object.String).Value)
+			chars := make([]string, len(runes))
+			for idx, r := range runes {
+				chars[idx] = string(r)
+			}
+			return stringsToArray(chars)
+		},
+	},
+}
+
+// stringTransform creates a builtin that maps a string to another one
+func stringTransform(name string, transform func(string) string) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments(name, args, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			return &object.String{Value: transform(args[0].(*object.String).Value)}
+		},
+	}
+}
+
+func stringsToArray(strs []string) *object.Array {
+	elements := make([]object.Object, len(strs))
+	for idx, str := range strs {
+		elements[idx] = &object.String{Value: str}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,161 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"taulang/object"
+	"time"
+)
+
+// systemBuiltins reach outside of the program, each of them needs a capability
+var systemBuiltins = map[string]*object.Builtin{
+	// eprint is print for the standard error
+	"eprint": &object.Builtin{
+		Capability: object.PrintCapability,
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			for _, arg := range args {
+				fmt.Fprintln(ctx.Streams().Stderr, arg.Inspect())
+			}
+
+			return NULL
+		},
+	},
+	// printf prints the arguments formatted like `format` does, without
+	// adding a line break
+	"printf": &object.Builtin{
+		Capability: object.PrintCapability,
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if err := (ArgumentSpec{Name: "printf", Required: []object.Type{object.STRING_OBJ}, Rest: AnyType}).Check(args); err != nil {
+				return err
+			}
+
+			formatted, err := formatString(args[0].(*object.String).Value, args[1:])
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(ctx.Streams().Stdout, formatted)
+			return NULL
+		},
+	},
+	// input prints the optional prompt and returns the next line of input
+	// without its line break, or null when there is no more input
+	"input": &object.Builtin{
+		Capability: object.InputCapability,
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if err := (ArgumentSpec{Name: "input", Optional: []object.Type{object.STRING_OBJ}}).Check(args); err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				fmt.Fprint(ctx.Streams().Stdout, args[0].(*object.String).Value)
+			}
+			return readLine(ctx)
+		},
+	},
+	// read_line is input without a prompt
+	"read_line": &object.Builtin{
+		Capability: object.InputCapability,
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("read_line", args); err != nil {
+				return err
+			}
+
+			return readLine(ctx)
+		},
+	},
+	"read_file": &object.Builtin{
+		Capability: object.FileReadCapability,
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("read_file", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			content, err := os.ReadFile(args[0].(*object.String).Value)
+			if err != nil {
+				return newError(object.ValueError, "could not read file: %s", err)
+			}
+			return &object.String{Value: string(content)}
+		},
+	},
+	"write_file": &object.Builtin{
+		Capability: object.FileWriteCapability,
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("write_file", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(args[0].(*object.String).Value, []byte(args[1].(*object.String).Value), 0o644); err != nil {
+				return newError(object.ValueError, "could not write file: %s", err)
+			}
+			return NULL
+		},
+	},
+	// env returns null for variables that are not set
+	"env": &object.Builtin{
+		Capability: object.EnvCapability,
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("env", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			value, ok := os.LookupEnv(args[0].(*object.String).Value)
+			if !ok {
+				return NULL
+			}
+			return &object.String{Value: value}
+		},
+	},
+	// time returns the seconds since the Unix epoch
+	"time": &object.Builtin{
+		Capability: object.ClockCapability,
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("time", args); err != nil {
+				return err
+			}
+
+			return &object.Float{Value: float64(time.Now().UnixNano()) / float64(time.Second)}
+		},
+	},
+	// random returns a float from 0 up to but not including 1
+	"random": &object.Builtin{
+		Capability: object.RandomCapability,
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("random", args); err != nil {
+				return err
+			}
+
+			return &object.Float{Value: rand.Float64()}
+		},
+	},
+	// random_int returns an integer from low up to but not including high,
+	// like range
+	"random_int": &object.Builtin{
+		Capability: object.RandomCapability,
+		Fn: func(_ object.BuiltinContext, args ...object.Object) object.Object {
+			if err := checkArguments("random_int", args, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			low, high := args[0].(*object.Integer).Value, args[1].(*object.Integer).Value
+			if high <= low {
+				return newError(object.ValueError, "empty range for `random_int`: %d to %d", low, high)
+			}
+			// the width is computed unsigned so that it can't overflow
+			return &object.Integer{Value: low + int64(rand.Uint64N(uint64(high)-uint64(low)))}
+		},
+	},
+}
+
+func readLine(ctx object.BuiltinContext) object.Object {
+	line, err := ctx.Streams().ReadLine()
+	if errors.Is(err, io.EOF) {
+		return NULL
+	}
+	if err != nil {
+		return newError(object.ValueError, "could not read input: %s", err)
+	}
+	return &object.String{Value: line}
+}
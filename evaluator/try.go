@@ -0,0 +1,71 @@
+package evaluator
+
+import (
+	"slices"
+	"taulang/ast"
+	"taulang/object"
+)
+
+func evalTryExpression(node *ast.TryExpression, env object.Environment) object.Object {
+	result := Eval(node.Block, env)
+
+	// the catch parameter is only bound in the catch block
+	if err, ok := result.(*object.Error); ok && err.Catchable() && node.CatchBlock != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		catchEnv.Set(node.CatchParameter.Value, &object.Exception{Error: err})
+		result = Eval(node.CatchBlock, catchEnv)
+	}
+
+	if node.Finally != nil {
+		// The finally block only changes the outcome when it leaves it early
+		finally := Eval(node.Finally, env)
+		if isError(finally) || isReturnValue(finally) || isBreak(finally) || isContinue(finally) {
+			return finally
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+func evalThrowStatement(value ast.Expression, env object.Environment) object.Object {
+	evaluatedValue := Eval(value, env)
+	if isError(evaluatedValue) {
+		return evaluatedValue
+	}
+
+	return Throw(evaluatedValue)
+}
+
+// Throw returns the error raised by throwing value, a message or a caught
+// exception which is raised again as it was, with its position and stack
+func Throw(value object.Object) *object.Error {
+	switch value := value.(type) {
+	case *object.String:
+		return newError(object.ThrownError, "%s", value.Value)
+	case *object.Exception:
+		// the stack is copied as it grows while the error is raised
+		return &object.Error{
+			Kind:     value.Error.Kind,
+			Message:  value.Error.Message,
+			Position: value.Error.Position,
+			Stack:    slices.Clone(value.Error.Stack),
+		}
+	default:
+		return newError(object.TypeError, "cannot throw %s, expected STRING or EXCEPTION", value.Type())
+	}
+}
+
+func evalExceptionIndexExpression(indexedObject object.Object, index object.Object) object.Object {
+	exception := indexedObject.(*object.Exception)
+	name := index.(*object.String).Value
+
+	member, ok := exception.Member(name)
+	if !ok {
+		return newError(object.NameError, "exception has no member: %s", name)
+	}
+
+	return member
+}
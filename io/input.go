@@ -1,13 +1,14 @@
 package io
 
 import (
+	"flag"
 	"fmt"
 	"os"
 )
 
 func ReadArgs() string {
-	// Read args from command line
-	args := os.Args[1:]
+	// Read args from command line, flags are parsed by main
+	args := flag.Args()
 
 	// Read filepath if provided
 	var filepath string
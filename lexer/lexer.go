@@ -3,6 +3,8 @@ package lexer
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"taulang/token"
 	"unicode"
 	"unicode/utf8"
@@ -10,6 +12,10 @@ import (
 
 const EOF = rune(0)
 
+// ErrUnterminatedString is reported, as the literal of an ILLEGAL token, when
+// the source ends inside of a string
+var ErrUnterminatedString = errors.New("unterminated string")
+
 type Lexer interface {
 	NextToken() token.Token
 }
@@ -19,6 +25,14 @@ type lexer struct {
 	currCharPosition int
 	nextCharPosition int
 	currChar         rune
+
+	// 1-based line and column (in runes) of currChar
+	currLine   int
+	currColumn int
+
+	// interpolations has an entry for each interpolated string whose embedded
+	// expression is being lexed, the number of braces opened in it
+	interpolations []int
 }
 
 func NewLexer(input string) (Lexer, error) {
@@ -27,6 +41,8 @@ func NewLexer(input string) (Lexer, error) {
 		currCharPosition: 0,
 		nextCharPosition: 0,
 		currChar:         0,
+		currLine:         1,
+		currColumn:       1,
 	}
 
 	if err := l.readNextChar(); err != nil {
@@ -41,21 +57,49 @@ func NewLexer(input string) (Lexer, error) {
 }
 
 func (l *lexer) NextToken() token.Token {
+	position := l.currPosition()
+
 	tok, err := l.getNextToken()
 	if err != nil {
-		return token.NewToken(token.ILLEGAL, err.Error())
+		tok = token.NewToken(token.ILLEGAL, err.Error())
 	}
+
+	tok.Position = position
 	return tok
 }
 
+func (l *lexer) currPosition() token.Position {
+	return token.Position{Line: l.currLine, Column: l.currColumn, Offset: l.currCharPosition}
+}
+
 func (l *lexer) getNextToken() (token.Token, error) {
 	var tok token.Token
 
 	switch l.currChar {
 	case '{':
+		if len(l.interpolations) != 0 {
+			l.interpolations[len(l.interpolations)-1]++
+		}
 		tok = token.NewToken(token.LEFT_BRACE, "{")
 	case '}':
+		if depth := len(l.interpolations); depth != 0 {
+			// the brace closing an embedded expression continues its string
+			if l.interpolations[depth-1] == 0 {
+				tok = l.continueInterpolatedString()
+				break
+			}
+			l.interpolations[depth-1]--
+		}
 		tok = token.NewToken(token.RIGHT_BRACE, "}")
+	case '$':
+		if nextChar, _, err := l.decodeNextChar(); err != nil || nextChar != '"' {
+			tok = token.NewToken(token.ILLEGAL, "$")
+			break
+		}
+		if err := l.readNextChar(); err != nil {
+			return token.Token{}, err
+		}
+		tok = l.readInterpolatedString()
 	case '[':
 		tok = token.NewToken(token.LEFT_BRACKET, "[")
 	case ']':
@@ -68,6 +112,12 @@ func (l *lexer) getNextToken() (token.Token, error) {
 		tok = token.NewToken(token.COLON, ":")
 	case ',':
 		tok = token.NewToken(token.COMMA, ",")
+	case '.':
+		t, err := l.readEllipsisOrDotToken()
+		if err != nil {
+			return t, err
+		}
+		tok = t
 	case ';':
 		tok = token.NewToken(token.SEMICOLON, ";")
 	case '=':
@@ -82,34 +132,35 @@ func (l *lexer) getNextToken() (token.Token, error) {
 			return t, err
 		}
 		tok = t
-	case '>':
-		t, err := l.readEqualsOrDefaultToken(token.GREATER_EQUALS, token.GREATER_THAN)
+	case '>', '<', '&', '|', '^', '+', '-', '*', '/', '%':
+		t, err := l.readOperator()
 		if err != nil {
 			return t, err
 		}
 		tok = t
-	case '<':
-		t, err := l.readEqualsOrDefaultToken(token.LESSER_EQUALS, token.LESSER_THAN)
-		if err != nil {
-			return t, err
+	case '~':
+		tok = token.NewToken(token.BITWISE_NOT, "~")
+	case '"', '`':
+		readString := l.readString
+		if l.currChar == '`' {
+			readString = l.readRawString
 		}
-		tok = t
-	case '+':
-		tok = token.NewToken(token.ADDITION, "+")
-	case '-':
-		tok = token.NewToken(token.SUBTRACTION, "-")
-	case '*':
-		tok = token.NewToken(token.MULTIPLICATION, "*")
-	case '/':
-		tok = token.NewToken(token.DIVISION, "/")
-	case '"':
-		stringLiteral, err := l.readString()
+
+		// a string with a bad escape sequence is still consumed up to its
+		// closing quote, so lexing continues after it
+		stringLiteral, err := readString()
 		if err != nil {
-			return token.Token{}, err
+			tok = token.NewToken(token.ILLEGAL, err.Error())
+		} else {
+			tok = token.NewToken(token.STRING, stringLiteral)
 		}
-		tok = token.NewToken(token.STRING, stringLiteral)
 	case EOF:
 		tok = token.NewToken(token.EOF, "")
+		if len(l.interpolations) != 0 {
+			// reported once, the next token is EOF again
+			l.interpolations = nil
+			tok = token.NewToken(token.ILLEGAL, ErrUnterminatedString.Error())
+		}
 	default:
 		if unicode.IsLetter(l.currChar) {
 			identifier, err := l.readIdentifier()
@@ -123,7 +174,11 @@ func (l *lexer) getNextToken() (token.Token, error) {
 			if err != nil {
 				return token.Token{}, err
 			}
-			tok = token.NewToken(token.NUMBER, number)
+			if strings.ContainsRune(number, '.') {
+				tok = token.NewToken(token.FLOAT, number)
+			} else {
+				tok = token.NewToken(token.NUMBER, number)
+			}
 		} else {
 			tok = token.NewToken(token.ILLEGAL, string(l.currChar))
 		}
@@ -143,8 +198,15 @@ func (l *lexer) getNextToken() (token.Token, error) {
 }
 
 func (l *lexer) readNextChar() error {
+	// currChar was consumed from the source (as opposed to being the initial
+	// value or EOF) only when the read cursor is ahead of it
+	if l.nextCharPosition > l.currCharPosition {
+		l.advanceLineAndColumn()
+	}
+
 	if l.nextCharPosition >= len(l.source) {
 		l.currChar = EOF
+		l.currCharPosition = len(l.source)
 		return nil
 	}
 	runeValue, width, err := l.decodeNextChar()
@@ -157,6 +219,15 @@ func (l *lexer) readNextChar() error {
 	return nil
 }
 
+func (l *lexer) advanceLineAndColumn() {
+	if l.currChar == '\n' {
+		l.currLine++
+		l.currColumn = 1
+		return
+	}
+	l.currColumn++
+}
+
 func (l *lexer) skipWhitespaceAndComments() error {
 	for {
 		advanced := false
@@ -237,27 +308,253 @@ func (l *lexer) readNumber() (string, error) {
 			return "", err
 		}
 	}
+
+	// a trailing dot without a fractional part, e.g. 1.
+	if number[len(number)-1] == '.' {
+		return "", errors.New("invalid number")
+	}
+
 	return string(number), nil
 }
 
+// readString reads a string literal and decodes its escape sequences, the
+// first bad escape sequence is reported once the closing quote is reached
 func (l *lexer) readString() (string, error) {
+	str, _, err := l.readStringText(false)
+	return str, err
+}
+
+// readInterpolatedString reads the text of an interpolated string up to its
+// first embedded expression, or all of it when there is none
+func (l *lexer) readInterpolatedString() token.Token {
+	str, end, err := l.readStringText(true)
+	if end == '{' {
+		l.interpolations = append(l.interpolations, 0)
+	}
+	if err != nil {
+		return token.NewToken(token.ILLEGAL, err.Error())
+	}
+	if end == '{' {
+		return token.NewToken(token.INTERPOLATION_START, str)
+	}
+	return token.NewToken(token.STRING, str)
+}
+
+// continueInterpolatedString reads the text of an interpolated string after
+// an embedded expression, up to the next one or the end of the string
+func (l *lexer) continueInterpolatedString() token.Token {
+	str, end, err := l.readStringText(true)
+	if end == '"' {
+		l.interpolations = l.interpolations[:len(l.interpolations)-1]
+	}
+	if err != nil {
+		return token.NewToken(token.ILLEGAL, err.Error())
+	}
+	if end == '{' {
+		return token.NewToken(token.INTERPOLATION_MIDDLE, str)
+	}
+	return token.NewToken(token.INTERPOLATION_END, str)
+}
+
+// readStringText reads string text up to the closing quote, or up to an
+// embedded expression's opening brace when interpolated, and returns which of
+// them ended it. Interpolated strings also have the escapes \{ and \}.
+func (l *lexer) readStringText(interpolated bool) (string, rune, error) {
 	var str []rune
-	escaped := false
+	var escapeErr error
 	for {
 		if err := l.readNextChar(); err != nil {
-			return "", err
+			return "", 0, err
+		}
+		switch {
+		case l.currChar == EOF:
+			// the source ends in it, so do all strings it's embedded in
+			l.interpolations = nil
+			return "", EOF, ErrUnterminatedString
+		case l.currChar == '"', interpolated && l.currChar == '{':
+			return string(str), l.currChar, escapeErr
+		case l.currChar == '\\':
+			if nextChar, _, err := l.decodeNextChar(); interpolated && err == nil && (nextChar == '{' || nextChar == '}') {
+				if err := l.readNextChar(); err != nil {
+					return "", 0, err
+				}
+				str = append(str, nextChar)
+				continue
+			}
+
+			r, err := l.readEscapeSequence()
+			if err != nil {
+				if errors.Is(err, ErrUnterminatedString) {
+					l.interpolations = nil
+					return "", EOF, err
+				}
+				if escapeErr == nil {
+					escapeErr = err
+				}
+				continue
+			}
+			str = append(str, r)
+		default:
+			str = append(str, l.currChar)
 		}
-		if l.currChar == '"' && !escaped {
+	}
+}
+
+// readEscapeSequence decodes the escape sequence after a backslash, leaving
+// currChar at its last character
+func (l *lexer) readEscapeSequence() (rune, error) {
+	if err := l.readNextChar(); err != nil {
+		return 0, err
+	}
+
+	switch l.currChar {
+	case 'n':
+		return '\n', nil
+	case 't':
+		return '\t', nil
+	case 'r':
+		return '\r', nil
+	case '0':
+		return 0, nil
+	case '\\':
+		return '\\', nil
+	case '"':
+		return '"', nil
+	case 'u':
+		return l.readUnicodeEscape()
+	case EOF:
+		return 0, ErrUnterminatedString
+	default:
+		return 0, fmt.Errorf("unknown escape sequence \\%c", l.currChar)
+	}
+}
+
+// readUnicodeEscape decodes the code point of \u{...}, given as 1 to 6 hex digits
+func (l *lexer) readUnicodeEscape() (rune, error) {
+	if nextChar, _, err := l.decodeNextChar(); err != nil || nextChar != '{' {
+		return 0, errors.New("invalid unicode escape, expected \\u{...}")
+	}
+	if err := l.readNextChar(); err != nil {
+		return 0, err
+	}
+
+	// the digits are only consumed while they are valid, so that the closing
+	// quote is left to readString
+	var digits []rune
+	for {
+		nextChar, _, err := l.decodeNextChar()
+		if err != nil || (nextChar != '}' && !isHexDigit(nextChar)) {
+			return 0, errors.New("invalid unicode escape, expected \\u{...}")
+		}
+		if err := l.readNextChar(); err != nil {
+			return 0, err
+		}
+		if nextChar == '}' {
 			break
 		}
-		str = append(str, l.currChar)
-		escaped = l.currChar == '\\'
+		digits = append(digits, nextChar)
+	}
+
+	if len(digits) == 0 || len(digits) > 6 {
+		return 0, errors.New("invalid unicode escape, expected 1 to 6 hex digits")
+	}
+
+	code, _ := strconv.ParseUint(string(digits), 16, 32)
+	if !utf8.ValidRune(rune(code)) {
+		return 0, fmt.Errorf("invalid unicode code point %s", strings.ToUpper(string(digits)))
+	}
+
+	return rune(code), nil
+}
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// readRawString reads a string literal between backticks, which may span
+// several lines and has no escape sequences
+func (l *lexer) readRawString() (string, error) {
+	var str []rune
+	for {
+		if err := l.readNextChar(); err != nil {
+			return "", err
+		}
+		if l.currChar == EOF {
+			l.interpolations = nil
+			return "", ErrUnterminatedString
+		}
+		if l.currChar == '`' {
+			return string(str), nil
+		}
+		// carriage returns of Windows line endings aren't part of the text
+		if l.currChar != '\r' {
+			str = append(str, l.currChar)
+		}
 	}
-	return string(str), nil
+}
+
+// operators are the operators that readOperator reads, longer ones that start
+// with the same characters as shorter ones come first
+var operators = []struct {
+	literal   string
+	tokenType token.Type
+}{
+	{"**=", token.COMPOUND_ASSIGNMENT},
+	{"<<=", token.COMPOUND_ASSIGNMENT},
+	{">>=", token.COMPOUND_ASSIGNMENT},
+	{"+=", token.COMPOUND_ASSIGNMENT},
+	{"-=", token.COMPOUND_ASSIGNMENT},
+	{"*=", token.COMPOUND_ASSIGNMENT},
+	{"/=", token.COMPOUND_ASSIGNMENT},
+	{"%=", token.COMPOUND_ASSIGNMENT},
+	{"&=", token.COMPOUND_ASSIGNMENT},
+	{"|=", token.COMPOUND_ASSIGNMENT},
+	{"^=", token.COMPOUND_ASSIGNMENT},
+	{"**", token.POWER},
+	{"<<", token.LEFT_SHIFT},
+	{">>", token.RIGHT_SHIFT},
+	{">=", token.GREATER_EQUALS},
+	{"<=", token.LESSER_EQUALS},
+	{"&&", token.AND},
+	{"||", token.OR},
+	{">", token.GREATER_THAN},
+	{"<", token.LESSER_THAN},
+	{"&", token.BITWISE_AND},
+	{"|", token.BITWISE_OR},
+	{"^", token.BITWISE_XOR},
+	{"+", token.ADDITION},
+	{"-", token.SUBTRACTION},
+	{"*", token.MULTIPLICATION},
+	{"/", token.DIVISION},
+	{"%", token.MODULO},
+}
+
+// readOperator reads the longest operator that starts at currChar
+func (l *lexer) readOperator() (token.Token, error) {
+	for _, operator := range operators {
+		if !strings.HasPrefix(l.source[l.currCharPosition:], operator.literal) {
+			continue
+		}
+
+		// the last character is consumed by the caller like for every other token
+		for range len(operator.literal) - 1 {
+			if err := l.readNextChar(); err != nil {
+				return token.Token{}, err
+			}
+		}
+		return token.NewToken(operator.tokenType, operator.literal), nil
+	}
+	return token.NewToken(token.ILLEGAL, string(l.currChar)), nil
 }
 
 func (l *lexer) readEqualsOrDefaultToken(compoundType token.Type, defaultType token.Type) (token.Token, error) {
-	if nextChar, _, err := l.decodeNextChar(); err == nil && nextChar == '=' {
+	return l.readCompoundOrDefaultToken('=', compoundType, defaultType)
+}
+
+// readCompoundOrDefaultToken reads a two character token if currChar is followed
+// by expectedNextChar, e.g. == or !=, else a single character token of defaultType
+func (l *lexer) readCompoundOrDefaultToken(expectedNextChar rune, compoundType token.Type, defaultType token.Type) (token.Token, error) {
+	if nextChar, _, err := l.decodeNextChar(); err == nil && nextChar == expectedNextChar {
 		currChar := l.currChar
 		err := l.readNextChar()
 		if err != nil {
@@ -268,6 +565,20 @@ func (l *lexer) readEqualsOrDefaultToken(compoundType token.Type, defaultType to
 	return token.NewToken(defaultType, string(l.currChar)), nil
 }
 
+func (l *lexer) readEllipsisOrDotToken() (token.Token, error) {
+	if !strings.HasPrefix(l.source[l.currCharPosition:], "...") {
+		return token.NewToken(token.DOT, "."), nil
+	}
+
+	// the last dot is consumed by the caller like for every other token
+	for range 2 {
+		if err := l.readNextChar(); err != nil {
+			return token.Token{}, err
+		}
+	}
+	return token.NewToken(token.ELLIPSIS, "..."), nil
+}
+
 func (l *lexer) decodeNextChar() (rune, int, error) {
 	runeValue, width := utf8.DecodeRuneInString(l.source[l.nextCharPosition:])
 
@@ -22,6 +22,8 @@ func TestNewLexer(t *testing.T) {
 				currCharPosition: 0,
 				nextCharPosition: 0,
 				currChar:         0,
+				currLine:         1,
+				currColumn:       1,
 			},
 		},
 		{
@@ -32,6 +34,8 @@ func TestNewLexer(t *testing.T) {
 				currCharPosition: 0,
 				nextCharPosition: 1,
 				currChar:         'a',
+				currLine:         1,
+				currColumn:       1,
 			},
 		},
 		{
@@ -42,6 +46,8 @@ func TestNewLexer(t *testing.T) {
 				currCharPosition: 0,
 				nextCharPosition: 1,
 				currChar:         '\\',
+				currLine:         1,
+				currColumn:       1,
 			},
 		},
 	}
@@ -76,8 +82,10 @@ x;
 	l, err := NewLexer(input)
 	assert.NoError(t, err)
 
-	for _, tok := range expected {
-		assert.Equal(t, tok, l.NextToken())
+	for _, expectedToken := range expected {
+		tok := l.NextToken()
+		assert.Equal(t, expectedToken.Type, tok.Type)
+		assert.Equal(t, expectedToken.Literal, tok.Literal)
 	}
 }
 
@@ -231,6 +239,166 @@ func TestLexer(t *testing.T) {
 				Literal: "@",
 			},
 		},
+		{
+			name:  "float",
+			input: "3.14",
+			expected: token.Token{
+				Type:    token.FLOAT,
+				Literal: "3.14",
+			},
+		},
+		{
+			name:  "float without fractional part",
+			input: "3.",
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "invalid number",
+			},
+		},
+		{
+			name:  "number with multiple dots",
+			input: "3.1.4",
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "invalid number",
+			},
+		},
+		{
+			name:  "and",
+			input: "&&",
+			expected: token.Token{
+				Type:    token.AND,
+				Literal: "&&",
+			},
+		},
+		{
+			name:  "or",
+			input: "||",
+			expected: token.Token{
+				Type:    token.OR,
+				Literal: "||",
+			},
+		},
+		{
+			name:  "dot",
+			input: ".",
+			expected: token.Token{
+				Type:    token.DOT,
+				Literal: ".",
+			},
+		},
+		{
+			name:  "ellipsis",
+			input: "...rest",
+			expected: token.Token{
+				Type:    token.ELLIPSIS,
+				Literal: "...",
+			},
+		},
+		{
+			name:  "import keyword",
+			input: "mangwa_lo",
+			expected: token.Token{
+				Type:    token.IMPORT,
+				Literal: "import",
+			},
+		},
+		{
+			name:  "unterminated string",
+			input: "\"test",
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "unterminated string",
+			},
+		},
+		{
+			name:  "string with escape sequences",
+			input: `"a\n\t\r\\\"\0b"`,
+			expected: token.Token{
+				Type:    token.STRING,
+				Literal: "a\n\t\r\\\"\x00b",
+			},
+		},
+		{
+			name:  "string with unicode escapes",
+			input: `"\u{41}\u{e9}\u{1F600}"`,
+			expected: token.Token{
+				Type:    token.STRING,
+				Literal: "Aé😀",
+			},
+		},
+		{
+			name:  "unknown escape sequence",
+			input: `"a\qb"`,
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "unknown escape sequence \\q",
+			},
+		},
+		{
+			name:  "unicode escape without braces",
+			input: `"\u41"`,
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "invalid unicode escape, expected \\u{...}",
+			},
+		},
+		{
+			name:  "unicode escape with too many digits",
+			input: `"\u{1234567}"`,
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "invalid unicode escape, expected 1 to 6 hex digits",
+			},
+		},
+		{
+			name:  "unicode escape of a surrogate",
+			input: `"\u{d800}"`,
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "invalid unicode code point D800",
+			},
+		},
+		{
+			name:  "string ending in a backslash",
+			input: `"test\`,
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "unterminated string",
+			},
+		},
+		{
+			name:  "raw string",
+			input: "`line \\n \"one\"\r\nline two`",
+			expected: token.Token{
+				Type:    token.STRING,
+				Literal: "line \\n \"one\"\nline two",
+			},
+		},
+		{
+			name:  "unterminated raw string",
+			input: "`test",
+			expected: token.Token{
+				Type:    token.ILLEGAL,
+				Literal: "unterminated string",
+			},
+		},
+		{
+			name:  "single ampersand",
+			input: "&",
+			expected: token.Token{
+				Type:    token.BITWISE_AND,
+				Literal: "&",
+			},
+		},
+		{
+			name:  "compound assignment",
+			input: "<<=",
+			expected: token.Token{
+				Type:    token.COMPOUND_ASSIGNMENT,
+				Literal: "<<=",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -238,6 +406,9 @@ func TestLexer(t *testing.T) {
 			l, err := NewLexer(tt.input)
 			assert.NoError(t, err)
 			tok := l.NextToken()
+
+			// every input here is a single token starting at the beginning of the source
+			tt.expected.Position = token.Position{Line: 1, Column: 1, Offset: 0}
 			assert.Equal(t, tt.expected, tok)
 		})
 	}
@@ -274,6 +445,7 @@ func TestLexerMultipleTokens(t *testing.T) {
 
 	rok_diye;
 	jaan_de;
+	har_ek (k mein obj) {}
 
 	sun_liyo_tau arr ne_bana_diye [1, 2, 3, 4, 5];
 	sun_liyo_tau obj ne_bana_diye {"a": 1, "b": 2, "c": 3};
@@ -391,6 +563,14 @@ func TestLexerMultipleTokens(t *testing.T) {
 		{Type: token.SEMICOLON, Literal: ";"},
 		{Type: token.CONTINUE, Literal: "continue"},
 		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.FOR, Literal: "for"},
+		{Type: token.LEFT_PAREN, Literal: "("},
+		{Type: token.IDENTIFIER, Literal: "k"},
+		{Type: token.IN, Literal: "in"},
+		{Type: token.IDENTIFIER, Literal: "obj"},
+		{Type: token.RIGHT_PAREN, Literal: ")"},
+		{Type: token.LEFT_BRACE, Literal: "{"},
+		{Type: token.RIGHT_BRACE, Literal: "}"},
 		{Type: token.LET, Literal: "let"},
 		{Type: token.IDENTIFIER, Literal: "arr"},
 		{Type: token.ASSIGNMENT, Literal: "="},
@@ -445,7 +625,223 @@ func TestLexerMultipleTokens(t *testing.T) {
 				t.Log(expectedToken)
 			}
 			tok := l.NextToken()
-			assert.Equal(t, expectedToken, tok)
+			assert.Equal(t, expectedToken.Type, tok.Type)
+			assert.Equal(t, expectedToken.Literal, tok.Literal)
 		})
 	}
 }
+
+func TestLexerPositions(t *testing.T) {
+	input := "sun_liyo_tau x ne_bana_diye 42;\n// comment\n\tx + \"तau\";\r\ny"
+
+	expected := []token.Token{
+		{Type: token.LET, Literal: "let", Position: token.Position{Line: 1, Column: 1, Offset: 0}},
+		{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 1, Column: 14, Offset: 13}},
+		{Type: token.ASSIGNMENT, Literal: "=", Position: token.Position{Line: 1, Column: 16, Offset: 15}},
+		{Type: token.NUMBER, Literal: "42", Position: token.Position{Line: 1, Column: 29, Offset: 28}},
+		{Type: token.SEMICOLON, Literal: ";", Position: token.Position{Line: 1, Column: 31, Offset: 30}},
+		{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 3, Column: 2, Offset: 44}},
+		{Type: token.ADDITION, Literal: "+", Position: token.Position{Line: 3, Column: 4, Offset: 46}},
+		{Type: token.STRING, Literal: "तau", Position: token.Position{Line: 3, Column: 6, Offset: 48}},
+		// columns count runes, so the multi-byte character only moves the offset
+		{Type: token.SEMICOLON, Literal: ";", Position: token.Position{Line: 3, Column: 11, Offset: 55}},
+		{Type: token.IDENTIFIER, Literal: "y", Position: token.Position{Line: 4, Column: 1, Offset: 58}},
+		{Type: token.EOF, Literal: "", Position: token.Position{Line: 4, Column: 2, Offset: 59}},
+	}
+
+	l, err := NewLexer(input)
+	assert.NoError(t, err)
+
+	for _, expectedToken := range expected {
+		assert.Equal(t, expectedToken, l.NextToken())
+	}
+}
+
+func TestLexerStrings(t *testing.T) {
+	// a bad escape sequence doesn't end the string, lexing continues after it
+	input := "\"a\\qb\" + `raw\nstring`;\nx"
+
+	expected := []token.Token{
+		{Type: token.ILLEGAL, Literal: "unknown escape sequence \\q", Position: token.Position{Line: 1, Column: 1, Offset: 0}},
+		{Type: token.ADDITION, Literal: "+", Position: token.Position{Line: 1, Column: 8, Offset: 7}},
+		{Type: token.STRING, Literal: "raw\nstring", Position: token.Position{Line: 1, Column: 10, Offset: 9}},
+		{Type: token.SEMICOLON, Literal: ";", Position: token.Position{Line: 2, Column: 8, Offset: 21}},
+		{Type: token.IDENTIFIER, Literal: "x", Position: token.Position{Line: 3, Column: 1, Offset: 23}},
+		{Type: token.EOF, Literal: "", Position: token.Position{Line: 3, Column: 2, Offset: 24}},
+	}
+
+	l, err := NewLexer(input)
+	assert.NoError(t, err)
+
+	for _, expectedToken := range expected {
+		assert.Equal(t, expectedToken, l.NextToken())
+	}
+}
+
+func TestLexerOperators(t *testing.T) {
+	// the longest operator is read, spaces split them
+	input := "** **= * *= < << <= <<= > >> >= >>= & && &= | || |= ^ ^= % %= ~ += -= /= ---"
+
+	expected := []token.Token{
+		{Type: token.POWER, Literal: "**"},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "**="},
+		{Type: token.MULTIPLICATION, Literal: "*"},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "*="},
+		{Type: token.LESSER_THAN, Literal: "<"},
+		{Type: token.LEFT_SHIFT, Literal: "<<"},
+		{Type: token.LESSER_EQUALS, Literal: "<="},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "<<="},
+		{Type: token.GREATER_THAN, Literal: ">"},
+		{Type: token.RIGHT_SHIFT, Literal: ">>"},
+		{Type: token.GREATER_EQUALS, Literal: ">="},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: ">>="},
+		{Type: token.BITWISE_AND, Literal: "&"},
+		{Type: token.AND, Literal: "&&"},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "&="},
+		{Type: token.BITWISE_OR, Literal: "|"},
+		{Type: token.OR, Literal: "||"},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "|="},
+		{Type: token.BITWISE_XOR, Literal: "^"},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "^="},
+		{Type: token.MODULO, Literal: "%"},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "%="},
+		{Type: token.BITWISE_NOT, Literal: "~"},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "+="},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "-="},
+		{Type: token.COMPOUND_ASSIGNMENT, Literal: "/="},
+		{Type: token.SUBTRACTION, Literal: "-"},
+		{Type: token.SUBTRACTION, Literal: "-"},
+		{Type: token.SUBTRACTION, Literal: "-"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l, err := NewLexer(input)
+	assert.NoError(t, err)
+
+	for _, expectedToken := range expected {
+		tok := l.NextToken()
+		assert.Equal(t, expectedToken.Type, tok.Type)
+		assert.Equal(t, expectedToken.Literal, tok.Literal)
+	}
+}
+
+func TestLexerInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []token.Token
+	}{
+		{
+			name:  "without expressions",
+			input: `$"plain \{text\}"`,
+			expected: []token.Token{
+				{Type: token.STRING, Literal: "plain {text}"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "embedded expressions",
+			input: `$"a{x}b{y + 1}"`,
+			expected: []token.Token{
+				{Type: token.INTERPOLATION_START, Literal: "a"},
+				{Type: token.IDENTIFIER, Literal: "x"},
+				{Type: token.INTERPOLATION_MIDDLE, Literal: "b"},
+				{Type: token.IDENTIFIER, Literal: "y"},
+				{Type: token.ADDITION, Literal: "+"},
+				{Type: token.NUMBER, Literal: "1"},
+				{Type: token.INTERPOLATION_END, Literal: ""},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "braces and quotes inside of an expression",
+			input: `$"{{"k": $"{v}"}["k"]}!"`,
+			expected: []token.Token{
+				{Type: token.INTERPOLATION_START, Literal: ""},
+				{Type: token.LEFT_BRACE, Literal: "{"},
+				{Type: token.STRING, Literal: "k"},
+				{Type: token.COLON, Literal: ":"},
+				{Type: token.INTERPOLATION_START, Literal: ""},
+				{Type: token.IDENTIFIER, Literal: "v"},
+				{Type: token.INTERPOLATION_END, Literal: ""},
+				{Type: token.RIGHT_BRACE, Literal: "}"},
+				{Type: token.LEFT_BRACKET, Literal: "["},
+				{Type: token.STRING, Literal: "k"},
+				{Type: token.RIGHT_BRACKET, Literal: "]"},
+				{Type: token.INTERPOLATION_END, Literal: "!"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "unterminated expression",
+			input: `$"a{x`,
+			expected: []token.Token{
+				{Type: token.INTERPOLATION_START, Literal: "a"},
+				{Type: token.IDENTIFIER, Literal: "x"},
+				{Type: token.ILLEGAL, Literal: "unterminated string"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "unterminated text",
+			input: `$"a{x} b`,
+			expected: []token.Token{
+				{Type: token.INTERPOLATION_START, Literal: "a"},
+				{Type: token.IDENTIFIER, Literal: "x"},
+				{Type: token.ILLEGAL, Literal: "unterminated string"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			name:  "dollar without a string",
+			input: `$x`,
+			expected: []token.Token{
+				{Type: token.ILLEGAL, Literal: "$"},
+				{Type: token.IDENTIFIER, Literal: "x"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLexer(tt.input)
+			assert.NoError(t, err)
+
+			for _, expectedToken := range tt.expected {
+				tok := l.NextToken()
+				assert.Equal(t, expectedToken.Type, tok.Type)
+				assert.Equal(t, expectedToken.Literal, tok.Literal)
+			}
+		})
+	}
+}
+
+func TestLexerImportAndDotAccess(t *testing.T) {
+	input := `sun_liyo_tau m ne_bana_diye mangwa_lo "./math.tau"; m.pi + 1.5;`
+
+	expected := []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENTIFIER, Literal: "m"},
+		{Type: token.ASSIGNMENT, Literal: "="},
+		{Type: token.IMPORT, Literal: "import"},
+		{Type: token.STRING, Literal: "./math.tau"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IDENTIFIER, Literal: "m"},
+		{Type: token.DOT, Literal: "."},
+		{Type: token.IDENTIFIER, Literal: "pi"},
+		{Type: token.ADDITION, Literal: "+"},
+		{Type: token.FLOAT, Literal: "1.5"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l, err := NewLexer(input)
+	assert.NoError(t, err)
+
+	for _, expectedToken := range expected {
+		tok := l.NextToken()
+		assert.Equal(t, expectedToken.Type, tok.Type)
+		assert.Equal(t, expectedToken.Literal, tok.Literal)
+	}
+}
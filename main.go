@@ -1,14 +1,32 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"taulang/io"
+	"taulang/object"
 	"taulang/repl"
 )
 
 func main() {
 	logger := log.New(os.Stdout, "", 0)
+
+	engineName := flag.String("engine", string(repl.EngineEval), "execution engine to use, eval or vm")
+	capabilitiesSpec := flag.String("capabilities", "full",
+		"builtins programs may use, a profile (full, safe or none) or a comma separated list of print, input, fs_read, fs_write, env, clock and random")
+	flag.Parse()
+
+	engine, err := repl.ParseEngine(*engineName)
+	if err != nil {
+		io.OutputFatalErrorAndExit(logger, err)
+	}
+
+	capabilities, err := object.ParseCapabilities(*capabilitiesSpec)
+	if err != nil {
+		io.OutputFatalErrorAndExit(logger, err)
+	}
+
 	filepath := io.ReadArgs()
 	content, err := io.GetContentFromFilepath(filepath)
 	if err != nil {
@@ -16,8 +34,8 @@ func main() {
 	}
 
 	if content != "" {
-		repl.ExecuteInput(content, logger)
+		repl.ExecuteInput(filepath, content, logger, engine, capabilities)
 	} else {
-		repl.StartREPL(logger)
+		repl.StartREPL(logger, engine, capabilities)
 	}
 }
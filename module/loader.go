@@ -0,0 +1,155 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"taulang/ast"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/parser"
+)
+
+// Extension is appended to import paths that don't have one
+const Extension = ".tau"
+
+// ExecuteFunc runs the program of a module that has not been loaded yet and
+// returns the environment holding its top-level bindings
+type ExecuteFunc func(filename string, program *ast.Program) (object.Environment, *object.Error)
+
+// Loader resolves import paths, runs every module once and detects import
+// cycles. One loader is shared by a program and all the modules it imports.
+type Loader struct {
+	execute ExecuteFunc
+	modules map[string]*object.Module
+	// files currently being loaded, in import order
+	loading []string
+}
+
+func NewLoader(execute ExecuteFunc) *Loader {
+	return NewLoaderWithEntry("", execute)
+}
+
+// NewLoaderWithEntry is NewLoader for the program in the file entry, which is
+// being loaded for as long as the program runs so that importing it again is
+// reported as a cycle instead of running it twice. entry may be empty for
+// programs that don't come from a file.
+func NewLoaderWithEntry(entry string, execute ExecuteFunc) *Loader {
+	loader := &Loader{
+		execute: execute,
+		modules: map[string]*object.Module{},
+	}
+	if entry != "" {
+		if filename, err := filepath.Abs(entry); err == nil {
+			loader.loading = append(loader.loading, filename)
+		}
+	}
+	return loader
+}
+
+// Load returns the module at path, relative paths are resolved against the
+// directory of the importer
+func (l *Loader) Load(path string, importer string) object.Object {
+	filename, err := Resolve(path, importer)
+	if err != nil {
+		return newError(object.ImportError, "cannot import %q: %s", path, err)
+	}
+
+	if module, ok := l.modules[filename]; ok {
+		return module
+	}
+
+	for idx, loading := range l.loading {
+		if loading == filename {
+			return newError(object.ImportError, "import cycle: %s", formatCycle(append(l.loading[idx:], filename)))
+		}
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return newError(object.ImportError, "cannot import %q: file not found", path)
+		}
+		return newError(object.ImportError, "cannot import %q: %s", path, err)
+	}
+
+	l.loading = append(l.loading, filename)
+	defer func() {
+		l.loading = l.loading[:len(l.loading)-1]
+	}()
+
+	name := filepath.Base(filename)
+
+	program, parseErr := parse(string(content))
+	if parseErr != nil {
+		return moduleError(name, parseErr)
+	}
+
+	env, execErr := l.execute(filename, program)
+	if execErr != nil {
+		return moduleError(name, execErr)
+	}
+
+	module := &object.Module{Name: name, Env: env}
+	l.modules[filename] = module
+
+	return module
+}
+
+// Resolve returns the absolute file name for an import path, importer is the
+// file containing the import or empty to resolve against the working directory
+func Resolve(path string, importer string) (string, error) {
+	if path == "" {
+		return "", errors.New("empty path")
+	}
+
+	if filepath.Ext(path) == "" {
+		path += Extension
+	}
+
+	if !filepath.IsAbs(path) && importer != "" {
+		path = filepath.Join(filepath.Dir(importer), path)
+	}
+
+	return filepath.Abs(path)
+}
+
+func parse(source string) (*ast.Program, *object.Error) {
+	l, err := lexer.NewLexer(source)
+	if err != nil {
+		return nil, newError(object.SyntaxError, "%s", err)
+	}
+
+	p := parser.NewParser(l)
+	program := p.Parse()
+
+	if diagnostics := p.Diagnostics(); len(diagnostics) != 0 {
+		return nil, &object.Error{Kind: object.SyntaxError, Message: diagnostics[0].Message, Position: diagnostics[0].Position}
+	}
+
+	return program, nil
+}
+
+// moduleError reports an error of an imported module at the import, the
+// position inside the module is kept in the message, the kind is kept as is
+func moduleError(name string, err *object.Error) *object.Error {
+	if !err.Position.IsValid() {
+		return newError(err.Kind, "error in module %s: %s", name, err.Message)
+	}
+	return newError(err.Kind, "error in module %s at %s: %s", name, err.Position, err.Message)
+}
+
+func formatCycle(filenames []string) string {
+	names := make([]string, len(filenames))
+	for idx, filename := range filenames {
+		names[idx] = filepath.Base(filename)
+	}
+	return strings.Join(names, " -> ")
+}
+
+func newError(kind object.ErrorKind, messageTemplate string, args ...any) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(messageTemplate, args...)}
+}
@@ -0,0 +1,146 @@
+package module_test
+
+import (
+	"os"
+	"path/filepath"
+	"taulang/ast"
+	"taulang/module"
+	"taulang/object"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	workingDir, err := os.Getwd()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		importer string
+		expected string
+	}{
+		{
+			name:     "relative to importer",
+			path:     "./lib/math.tau",
+			importer: "/project/main.tau",
+			expected: "/project/lib/math.tau",
+		},
+		{
+			name:     "parent directory",
+			path:     "../shared/util.tau",
+			importer: "/project/src/main.tau",
+			expected: "/project/shared/util.tau",
+		},
+		{
+			name:     "extension is added",
+			path:     "math",
+			importer: "/project/main.tau",
+			expected: "/project/math.tau",
+		},
+		{
+			name:     "absolute path",
+			path:     "/lib/math.tau",
+			importer: "/project/main.tau",
+			expected: "/lib/math.tau",
+		},
+		{
+			name:     "no importer resolves against working directory",
+			path:     "math.tau",
+			importer: "",
+			expected: filepath.Join(workingDir, "math.tau"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			filename, err := module.Resolve(tc.path, tc.importer)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, filename)
+		})
+	}
+}
+
+func TestLoader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ok.tau"), []byte("1;"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken.tau"), []byte("sun_liyo_tau = 1;"), 0o644))
+	importer := filepath.Join(dir, "main.tau")
+
+	executed := 0
+	loader := module.NewLoader(func(filename string, program *ast.Program) (object.Environment, *object.Error) {
+		executed++
+		return object.NewEnvironment(), nil
+	})
+
+	first := loader.Load("./ok.tau", importer)
+	module, ok := first.(*object.Module)
+	assert.True(t, ok, "expected module, got %s", first.Inspect())
+	assert.Equal(t, "ok.tau", module.Name)
+
+	// modules are cached by their resolved file name
+	assert.Same(t, first, loader.Load("ok", importer))
+	assert.Equal(t, 1, executed)
+
+	assert.Equal(t, &object.Error{Kind: object.ImportError, Message: `cannot import "./missing.tau": file not found`}, loader.Load("./missing.tau", importer))
+	assert.Equal(t,
+		&object.Error{Kind: object.SyntaxError, Message: "error in module broken.tau at 1:14: expected next token to be IDENTIFIER, got ILLEGAL (=)"},
+		loader.Load("./broken.tau", importer),
+	)
+	assert.Equal(t, 1, executed)
+}
+
+func TestLoaderDetectsCycles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.tau", "b.tau"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(""), 0o644))
+	}
+
+	// a imports b and b imports a
+	var loader *module.Loader
+	loader = module.NewLoader(func(filename string, program *ast.Program) (object.Environment, *object.Error) {
+		next := "b.tau"
+		if filepath.Base(filename) == "b.tau" {
+			next = "a.tau"
+		}
+		if err, ok := loader.Load(next, filename).(*object.Error); ok {
+			return nil, err
+		}
+		return object.NewEnvironment(), nil
+	})
+
+	result := loader.Load("a.tau", filepath.Join(dir, "main.tau"))
+	assert.Equal(t, &object.Error{Kind: object.ImportError, Message: "error in module a.tau: error in module b.tau: import cycle: a.tau -> b.tau -> a.tau"}, result)
+}
+
+func TestLoaderDetectsCyclesThroughTheEntry(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"main.tau", "b.tau"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(""), 0o644))
+	}
+	entry := filepath.Join(dir, "main.tau")
+
+	// main imports b and b imports main, which is already running
+	executed := 0
+	var loader *module.Loader
+	loader = module.NewLoaderWithEntry(entry, func(filename string, program *ast.Program) (object.Environment, *object.Error) {
+		executed++
+		if err, ok := loader.Load("main.tau", filename).(*object.Error); ok {
+			return nil, err
+		}
+		return object.NewEnvironment(), nil
+	})
+
+	result := loader.Load("b.tau", entry)
+	assert.Equal(t, &object.Error{Kind: object.ImportError, Message: "error in module b.tau: import cycle: main.tau -> b.tau -> main.tau"}, result)
+	assert.Equal(t, 1, executed)
+}
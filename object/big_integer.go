@@ -0,0 +1,22 @@
+package object
+
+import "math/big"
+
+// BigInteger holds integers that don't fit in an Integer. Arithmetic promotes
+// its result to a BigInteger on overflow and demotes results that fit again,
+// so a BigInteger never holds a value an Integer could
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (b *BigInteger) Type() Type {
+	return BIG_INTEGER_OBJ
+}
+
+func (b *BigInteger) Inspect() string {
+	return b.Value.String()
+}
+
+func (b *BigInteger) Hash() HashKey {
+	return HashKey{ObjectType: BIG_INTEGER_OBJ, Value: hash(b.Value.String())}
+}
@@ -0,0 +1,136 @@
+package object
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultMaxDepth bounds the call depth of runs without a MaxDepth, the tree
+// walker recurses natively for every call and would otherwise exhaust the Go
+// stack
+const DefaultMaxDepth = 10000
+
+// contextCheckInterval is the number of steps between checks of the context,
+// checking it on every step would slow every node down
+const contextCheckInterval = 256
+
+// Limits bound the resources a run of a program can use, zero fields don't
+// limit anything except MaxDepth, which is DefaultMaxDepth then
+type Limits struct {
+	// MaxSteps is the number of nodes that can be evaluated
+	MaxSteps int64
+	// MaxDepth is the number of function calls that can be nested
+	MaxDepth int
+	// MaxAllocation is the approximate number of bytes the arrays, strings and
+	// hash maps created by the run may take in total
+	MaxAllocation int64
+}
+
+// Budget tracks the resources used by a run against its limits. It is shared
+// by a file and all the files it imports, see ModuleContext.
+type Budget struct {
+	ctx       context.Context
+	limits    Limits
+	steps     int64
+	depth     int
+	allocated int64
+	// err is the error of the first exceeded limit or of the interruption,
+	// every later check fails with it so that catching it can't keep a run
+	// going
+	err *Error
+}
+
+func NewBudget(limits Limits) *Budget {
+	if limits.MaxDepth == 0 {
+		limits.MaxDepth = DefaultMaxDepth
+	}
+	return &Budget{ctx: context.Background(), limits: limits}
+}
+
+// Start resets the used resources for a new run, which is interrupted once
+// ctx is done
+func (b *Budget) Start(ctx context.Context) {
+	b.ctx = ctx
+	b.steps = 0
+	b.depth = 0
+	b.allocated = 0
+	b.err = nil
+}
+
+// Step accounts for the evaluation of a node
+func (b *Budget) Step() *Error {
+	if b.err != nil {
+		return b.err
+	}
+
+	b.steps++
+	if b.limits.MaxSteps > 0 && b.steps > b.limits.MaxSteps {
+		return b.fail(LimitError, "step limit of %d exceeded", b.limits.MaxSteps)
+	}
+
+	if b.steps%contextCheckInterval == 0 {
+		if err := b.ctx.Err(); err != nil {
+			return b.fail(InterruptedError, "execution interrupted: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// fail ends the run with an error of kind LimitError or InterruptedError
+func (b *Budget) fail(kind ErrorKind, messageTemplate string, args ...any) *Error {
+	b.err = &Error{Kind: kind, Message: fmt.Sprintf(messageTemplate, args...)}
+	return b.err
+}
+
+// Enter accounts for a function call, which must be left with Leave unless an
+// error is returned
+func (b *Budget) Enter() *Error {
+	if b.err != nil {
+		return b.err
+	}
+	if b.depth >= b.limits.MaxDepth {
+		return &Error{
+			Kind:    StackOverflowError,
+			Message: fmt.Sprintf("stack overflow: maximum call depth of %d exceeded", b.limits.MaxDepth),
+		}
+	}
+
+	b.depth++
+	return nil
+}
+
+func (b *Budget) Leave() {
+	b.depth--
+}
+
+// MaxDepth is the number of function calls that can be nested, for engines
+// that track the depth themselves
+func (b *Budget) MaxDepth() int {
+	return b.limits.MaxDepth
+}
+
+// CheckAllocation returns the error Allocate would return for size bytes
+// without accounting for them, it lets values be checked before they are built
+func (b *Budget) CheckAllocation(size int64) *Error {
+	if b.err != nil {
+		return b.err
+	}
+
+	// compared by subtracting, adding a huge size could overflow
+	if b.limits.MaxAllocation > 0 && size > b.limits.MaxAllocation-b.allocated {
+		return b.fail(LimitError, "allocation limit of %d bytes exceeded", b.limits.MaxAllocation)
+	}
+	return nil
+}
+
+// Allocate accounts for size bytes of memory, the memory is never given back
+// as the budget doesn't know when objects become garbage
+func (b *Budget) Allocate(size int64) *Error {
+	if err := b.CheckAllocation(size); err != nil {
+		return err
+	}
+
+	b.allocated += size
+	return nil
+}
@@ -1,9 +1,26 @@
 package object
 
-type BuiltinFunction func(args ...Object) Object
+// BuiltinContext gives builtins access to the engine running them
+type BuiltinContext interface {
+	// Call applies a function or builtin to the arguments, the result is an
+	// *Error when the call failed
+	Call(fn Object, args ...Object) Object
+	// Streams returns the streams of the program that called the builtin
+	Streams() *Streams
+	// CheckAllocation returns an error when the run can't allocate size more
+	// bytes, builtins that build large values check them before building them
+	CheckAllocation(size int64) *Error
+}
+
+type BuiltinFunction func(ctx BuiltinContext, args ...Object) Object
 
 type Builtin struct {
 	Fn BuiltinFunction
+	// Name is used in errors about calls of the builtin
+	Name string
+	// Capability must be allowed for the builtin to be called, builtins
+	// without one can always be called
+	Capability Capability
 }
 
 func (b *Builtin) Type() Type {
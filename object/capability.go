@@ -0,0 +1,90 @@
+package object
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Capability is a group of builtins that reach outside of the program, which
+// untrusted programs may not be allowed to use
+type Capability string
+
+const (
+	PrintCapability     Capability = "print"
+	InputCapability     Capability = "input"
+	FileReadCapability  Capability = "fs_read"
+	FileWriteCapability Capability = "fs_write"
+	EnvCapability       Capability = "env"
+	ClockCapability     Capability = "clock"
+	RandomCapability    Capability = "random"
+)
+
+var allCapabilities = []Capability{
+	PrintCapability,
+	InputCapability,
+	FileReadCapability,
+	FileWriteCapability,
+	EnvCapability,
+	ClockCapability,
+	RandomCapability,
+}
+
+// Capabilities is the set of capabilities a program is allowed to use, a nil
+// set allows all of them
+type Capabilities map[Capability]bool
+
+func NewCapabilities(capabilities ...Capability) Capabilities {
+	set := Capabilities{}
+	for _, capability := range capabilities {
+		set[capability] = true
+	}
+	return set
+}
+
+// Allows reports whether builtins of the capability can be called, builtins
+// without a capability can always be called
+func (c Capabilities) Allows(capability Capability) bool {
+	return c == nil || capability == "" || c[capability]
+}
+
+// Profiles are the named sets of capabilities accepted by ParseCapabilities
+var Profiles = map[string]Capabilities{
+	// full allows everything, it is the default
+	"full": NewCapabilities(allCapabilities...),
+	// safe allows the capabilities that can't read or change anything outside
+	// of the program
+	"safe": NewCapabilities(PrintCapability, ClockCapability, RandomCapability),
+	"none": NewCapabilities(),
+}
+
+// ParseCapabilities returns the capabilities given by the name of a profile or
+// by a comma separated list of capabilities, e.g. "print,clock"
+func ParseCapabilities(spec string) (Capabilities, error) {
+	if profile, ok := Profiles[spec]; ok {
+		return profile, nil
+	}
+
+	capabilities := NewCapabilities()
+	for _, name := range strings.Split(spec, ",") {
+		capability := Capability(strings.TrimSpace(name))
+		if !slices.Contains(allCapabilities, capability) {
+			return nil, fmt.Errorf("unknown capability or profile %q, expected one of %s", name, capabilityNames())
+		}
+		capabilities[capability] = true
+	}
+	return capabilities, nil
+}
+
+func capabilityNames() string {
+	var names []string
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, capability := range allCapabilities {
+		names = append(names, string(capability))
+	}
+	return strings.Join(names, ", ")
+}
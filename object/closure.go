@@ -0,0 +1,29 @@
+package object
+
+// Closure is a compiled function together with the free variables it captured
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+	// Unit the function was compiled in, it stays with the closure so that
+	// functions imported from another module keep using that module's globals
+	Unit *CompilationUnit
+}
+
+// Type is the same as for tree walking functions as the two are
+// interchangeable from the point of view of a program
+func (c *Closure) Type() Type {
+	return FUNCTION_OBJ
+}
+
+func (c *Closure) Inspect() string {
+	return c.Fn.Inspect()
+}
+
+// CompilationUnit is the runtime state of one compiled file
+type CompilationUnit struct {
+	Constants []Object
+	Globals   []Object
+	// Names of the globals by index, used in error messages
+	GlobalNames []string
+	Context     *ModuleContext
+}
@@ -0,0 +1,35 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"taulang/code"
+)
+
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	SourceMap     code.SourceMap
+	NumLocals     int
+	NumParameters int
+	// Parameters after the first NumRequired have defaults
+	NumRequired int
+	// Variadic functions collect extra arguments into the local after the parameters
+	Variadic bool
+	// Names of the local and free variables by index, used in error messages
+	LocalNames []string
+	FreeNames  []string
+	// Name is the name of the function in stack traces, empty if anonymous
+	Name string
+}
+
+func (c *CompiledFunction) Type() Type {
+	return COMPILED_FUNCTION_OBJ
+}
+
+func (c *CompiledFunction) Inspect() string {
+	params := c.LocalNames[:c.NumParameters]
+	if c.Variadic {
+		params = append(params[:len(params):len(params)], "..."+c.LocalNames[c.NumParameters])
+	}
+	return fmt.Sprintf("func(%s) { <compiled> }", strings.Join(params, ", "))
+}
@@ -1,19 +1,34 @@
 package object
 
+import "sort"
+
 type Environment interface {
 	Get(key string) (Object, bool)
+	// Set binds the key in this environment, shadowing any outer binding. A
+	// nil value declares the key without binding it, it is then not found
+	// until it is set, also not in outer environments.
 	Set(key string, value Object) Object
+	// Assign updates the binding of key in the innermost environment that
+	// declares it, it reports false when the key is not declared anywhere
+	Assign(key string, value Object) (Object, bool)
+	// Context returns the module the environment belongs to, nil when imports
+	// are not available
+	Context() *ModuleContext
+	// Names returns the sorted names bound in this and the outer environments
+	Names() []string
 }
 
 type environment struct {
 	store    map[string]Object
 	outerEnv Environment
+	context  *ModuleContext
 }
 
 func NewEnclosedEnvironment(outerEnv Environment) Environment {
 	return &environment{
 		store:    map[string]Object{},
 		outerEnv: outerEnv,
+		context:  outerEnv.Context(),
 	}
 }
 
@@ -23,15 +38,61 @@ func NewEnvironment() Environment {
 	}
 }
 
+// NewModuleEnvironment creates the top-level environment of a file
+func NewModuleEnvironment(context *ModuleContext) Environment {
+	return &environment{
+		store:   map[string]Object{},
+		context: context,
+	}
+}
+
 func (e *environment) Get(key string) (Object, bool) {
 	obj, ok := e.store[key]
 	if !ok && e.outerEnv != nil {
 		return e.outerEnv.Get(key)
 	}
-	return obj, ok
+	return obj, obj != nil
 }
 
 func (e *environment) Set(key string, value Object) Object {
 	e.store[key] = value
 	return value
 }
+
+func (e *environment) Assign(key string, value Object) (Object, bool) {
+	if obj, ok := e.store[key]; ok {
+		if obj == nil {
+			return nil, false
+		}
+		e.store[key] = value
+		return value, true
+	}
+	if e.outerEnv != nil {
+		return e.outerEnv.Assign(key, value)
+	}
+	return nil, false
+}
+
+func (e *environment) Context() *ModuleContext {
+	return e.context
+}
+
+func (e *environment) Names() []string {
+	var names []string
+	for name, obj := range e.store {
+		if obj != nil {
+			names = append(names, name)
+		}
+	}
+
+	if e.outerEnv != nil {
+		for _, name := range e.outerEnv.Names() {
+			if _, ok := e.store[name]; !ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -1,7 +1,60 @@
 package object
 
+import "taulang/token"
+
+// ErrorKind classifies runtime errors, e.g. so that they can be told apart
+// without matching on messages
+type ErrorKind string
+
+const (
+	TypeError          ErrorKind = "TypeError"
+	NameError          ErrorKind = "NameError"
+	ArgumentError      ErrorKind = "ArgumentError"
+	ValueError         ErrorKind = "ValueError"
+	IndexError         ErrorKind = "IndexError"
+	ZeroDivisionError  ErrorKind = "ZeroDivisionError"
+	OverflowError      ErrorKind = "OverflowError"
+	ImportError        ErrorKind = "ImportError"
+	SyntaxError        ErrorKind = "SyntaxError"
+	StackOverflowError ErrorKind = "StackOverflowError"
+	InternalError      ErrorKind = "InternalError"
+	// LimitError is the kind of errors of runs that exceed their limits
+	LimitError ErrorKind = "LimitError"
+	// InterruptedError is the kind of errors of runs that were cancelled or
+	// timed out
+	InterruptedError ErrorKind = "InterruptedError"
+	// PermissionError is the kind of errors of calls of builtins whose
+	// capability is not allowed
+	PermissionError ErrorKind = "PermissionError"
+	// ThrownError is the kind of errors thrown by programs with a message
+	ThrownError ErrorKind = "Error"
+)
+
 type Error struct {
+	Kind    ErrorKind
 	Message string
+	// Position of the innermost node whose evaluation produced the error
+	Position token.Position
+	// Stack holds the functions that were running when the error happened,
+	// innermost first. It is empty for errors outside of any function.
+	Stack []StackFrame
+}
+
+// StackFrame is a function that was running when an error happened, Position
+// is where its execution was
+type StackFrame struct {
+	// Function is the name the function was bound to, empty for anonymous
+	// functions and for the top-level code
+	Function string
+	// Filename of the module the function belongs to, empty for the main program
+	Filename string
+	Position token.Position
+}
+
+// Catchable reports whether try expressions can catch the error, runs that
+// exceeded their limits or were interrupted end with their error
+func (e *Error) Catchable() bool {
+	return e.Kind != LimitError && e.Kind != InterruptedError
 }
 
 func (e *Error) Type() Type {
@@ -9,5 +62,8 @@ func (e *Error) Type() Type {
 }
 
 func (e *Error) Inspect() string {
-	return e.Message
+	if e.Kind == "" {
+		return e.Message
+	}
+	return string(e.Kind) + ": " + e.Message
 }
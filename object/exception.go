@@ -0,0 +1,31 @@
+package object
+
+// Exception is a caught error as a value of the program, as opposed to an
+// *Error which unwinds the evaluation
+type Exception struct {
+	Error *Error
+}
+
+func (e *Exception) Type() Type {
+	return EXCEPTION_OBJ
+}
+
+func (e *Exception) Inspect() string {
+	return e.Error.Inspect()
+}
+
+// Member returns the field called name, e.g. the message of the error
+func (e *Exception) Member(name string) (Object, bool) {
+	switch name {
+	case "kind":
+		return &String{Value: string(e.Error.Kind)}, true
+	case "message":
+		return &String{Value: e.Error.Message}, true
+	case "line":
+		return &Integer{Value: int64(e.Error.Position.Line)}, true
+	case "column":
+		return &Integer{Value: int64(e.Error.Position.Column)}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,41 @@
+package object
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() Type {
+	return FLOAT_OBJ
+}
+
+func (f *Float) Inspect() string {
+	str := strconv.FormatFloat(f.Value, 'g', -1, 64)
+
+	// Keep a decimal point on whole numbers so that floats can be told apart
+	// from integers when printed, e.g. 3.0 instead of 3
+	if !strings.ContainsAny(str, ".eIN") {
+		str += ".0"
+	}
+
+	return str
+}
+
+// Hash is the hash of the equal integer for whole numbers, as they are equal
+// keys, so 1.0 and 1 are the same key and so are 0.0 and -0.0
+func (f *Float) Hash() HashKey {
+	if f.Value == math.Trunc(f.Value) && !math.IsInf(f.Value, 0) {
+		if f.Value >= math.MinInt64 && f.Value < math.MaxInt64 {
+			return (&Integer{Value: int64(f.Value)}).Hash()
+		}
+		value, _ := big.NewFloat(f.Value).Int(nil)
+		return (&BigInteger{Value: value}).Hash()
+	}
+	return HashKey{ObjectType: FLOAT_OBJ, Value: math.Float64bits(f.Value)}
+}
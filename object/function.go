@@ -7,8 +7,13 @@ import (
 
 type Function struct {
 	Params []*ast.Identifier
-	Body   *ast.BlockStatement
-	Env    Environment
+	// Defaults and Rest are the same as in ast.FunctionLiteral
+	Defaults []ast.Expression
+	Rest     *ast.Identifier
+	Body     *ast.BlockStatement
+	Env      Environment
+	// Name is the same as in ast.FunctionLiteral
+	Name string
 }
 
 func (f *Function) Type() Type {
@@ -18,8 +23,15 @@ func (f *Function) Type() Type {
 func (f *Function) Inspect() string {
 	var out strings.Builder
 	var params []string
-	for _, p := range f.Params {
-		params = append(params, p.String())
+	for idx, p := range f.Params {
+		if f.Defaults != nil && f.Defaults[idx] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[idx].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
 	}
 	out.WriteString("func")
 	out.WriteString("(")
@@ -2,6 +2,7 @@ package object
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -10,19 +11,73 @@ type HashPair struct {
 	Value Object
 }
 
+// HashMap keeps its pairs in the order their keys were first set, Order holds
+// the hashes of the keys in Pairs in that order
 type HashMap struct {
 	Pairs map[HashKey]HashPair
+	Order []HashKey
+}
+
+func NewHashMap() *HashMap {
+	return &HashMap{Pairs: make(map[HashKey]HashPair)}
 }
 
 func (h *HashMap) Type() Type {
 	return HASHMAP_OBJ
 }
 
+// Get returns the pair of the key with the hash
+func (h *HashMap) Get(hash HashKey) (HashPair, bool) {
+	pair, ok := h.Pairs[hash]
+	return pair, ok
+}
+
+// Set adds the pair or replaces the value of its key, which keeps its place
+func (h *HashMap) Set(hash HashKey, pair HashPair) {
+	if _, ok := h.Pairs[hash]; !ok {
+		h.Order = append(h.Order, hash)
+	}
+	h.Pairs[hash] = pair
+}
+
+// Delete removes the key with the hash and reports whether it was there
+func (h *HashMap) Delete(hash HashKey) bool {
+	if _, ok := h.Pairs[hash]; !ok {
+		return false
+	}
+
+	delete(h.Pairs, hash)
+	h.Order = slices.DeleteFunc(h.Order, func(k HashKey) bool { return k == hash })
+	return true
+}
+
+func (h *HashMap) Len() int {
+	return len(h.Order)
+}
+
+// Ordered returns the pairs in insertion order
+func (h *HashMap) Ordered() []HashPair {
+	pairs := make([]HashPair, len(h.Order))
+	for idx, hash := range h.Order {
+		pairs[idx] = h.Pairs[hash]
+	}
+	return pairs
+}
+
+// Copy returns a hash map with the same pairs that can be changed separately
+func (h *HashMap) Copy() *HashMap {
+	copied := NewHashMap()
+	for _, hash := range h.Order {
+		copied.Set(hash, h.Pairs[hash])
+	}
+	return copied
+}
+
 func (h *HashMap) Inspect() string {
 	var out strings.Builder
 
 	var pairs []string
-	for _, pair := range h.Pairs {
+	for _, pair := range h.Ordered() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -0,0 +1,36 @@
+package object
+
+// Iterator steps through the elements of an array, hash map, string or range
+// for a for-in loop. Next returns the key and value of the next element, the
+// key is the index for everything but hash maps, and false once there are no
+// more elements.
+type Iterator struct {
+	Next func() (key Object, value Object, ok bool)
+	// KeyOnly is set when a loop with one variable binds the key rather than
+	// the value, as hash maps are iterated by key
+	KeyOnly bool
+}
+
+func (i *Iterator) Type() Type {
+	return ITERATOR_OBJ
+}
+
+func (i *Iterator) Inspect() string {
+	return "iterator"
+}
+
+// Bind returns the values a loop with count variables binds for the next
+// element, and false once there are no more elements
+func (i *Iterator) Bind(count int) ([]Object, bool) {
+	key, value, ok := i.Next()
+	switch {
+	case !ok:
+		return nil, false
+	case count == 2:
+		return []Object{key, value}, true
+	case i.KeyOnly:
+		return []Object{key}, true
+	default:
+		return []Object{value}, true
+	}
+}
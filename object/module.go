@@ -0,0 +1,58 @@
+package object
+
+// Module is the namespace returned by an import, its members are the top-level
+// bindings of the imported file
+type Module struct {
+	Name string
+	Env  Environment
+}
+
+func (m *Module) Type() Type {
+	return MODULE_OBJ
+}
+
+func (m *Module) Inspect() string {
+	return "module(" + m.Name + ")"
+}
+
+// Member returns the top-level binding called name
+func (m *Module) Member(name string) (Object, bool) {
+	return m.Env.Get(name)
+}
+
+// ModuleLoader loads the module at path for an import in the file importer,
+// it returns a *Module or an *Error
+type ModuleLoader interface {
+	Load(path string, importer string) Object
+}
+
+// BuiltinLookup finds the builtins a host added to the program, see
+// evaluator.Registry
+type BuiltinLookup interface {
+	Lookup(name string) (Object, bool)
+}
+
+// ModuleContext is shared by all environments of a file, imports use it to
+// resolve paths relative to the file and to reach the loader of the program
+type ModuleContext struct {
+	Filename string
+	Loader   ModuleLoader
+	// Builtins are looked up after the core builtins, nil when the host
+	// didn't add any
+	Builtins BuiltinLookup
+	// Budget limits the resources of runs, nil when they are not limited
+	Budget *Budget
+	// Capabilities are the builtins the program may call, nil allows all
+	Capabilities Capabilities
+	// Streams are used by builtins like print, nil for the streams of the
+	// process
+	Streams *Streams
+}
+
+// ProgramStreams returns the streams of the program the context belongs to
+func (c *ModuleContext) ProgramStreams() *Streams {
+	if c == nil || c.Streams == nil {
+		return StdStreams
+	}
+	return c.Streams
+}
@@ -0,0 +1,39 @@
+package object
+
+import "fmt"
+
+// Range is the integers from Start up to but not including End, Step apart.
+// It is lazy, the integers are only made while iterating over it.
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() Type {
+	return RANGE_OBJ
+}
+
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.End)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
+// Len returns the number of integers in the range
+func (r *Range) Len() int64 {
+	switch {
+	case r.Step > 0 && r.Start < r.End:
+		return int64((uint64(r.End-r.Start)-1)/uint64(r.Step) + 1)
+	case r.Step < 0 && r.Start > r.End:
+		return int64((uint64(r.Start-r.End)-1)/uint64(-r.Step) + 1)
+	default:
+		return 0
+	}
+}
+
+// At returns the integer at the index, which must be less than Len
+func (r *Range) At(index int64) int64 {
+	return r.Start + index*r.Step
+}
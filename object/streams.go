@@ -0,0 +1,66 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Streams are the standard streams of a program, builtins like print and
+// input use them instead of the ones of the process. They can be shared by
+// programs running at the same time, every write and every line read happens
+// at once.
+type Streams struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// stdin is buffered once so that no input is lost between reads
+	stdin  *bufio.Reader
+	readMu sync.Mutex
+}
+
+// NewStreams creates the streams, a stdin that is a *bufio.Reader is used as
+// is so that the caller can keep reading from it
+func NewStreams(stdout io.Writer, stderr io.Writer, stdin io.Reader) *Streams {
+	// both writers share a lock, stdout and stderr are often the same file
+	writeMu := &sync.Mutex{}
+	return &Streams{
+		Stdout: &lockedWriter{mu: writeMu, w: stdout},
+		Stderr: &lockedWriter{mu: writeMu, w: stderr},
+		stdin:  bufio.NewReader(stdin),
+	}
+}
+
+// StdStreams are the streams of the process, used by programs whose module
+// context doesn't have any
+var StdStreams = NewStreams(os.Stdout, os.Stderr, os.Stdin)
+
+// ReadLine returns the next line of stdin without its line ending, it returns
+// io.EOF when there is no more input
+func (s *Streams) ReadLine() (string, error) {
+	s.readMu.Lock()
+	line, err := s.stdin.ReadString('\n')
+	s.readMu.Unlock()
+
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
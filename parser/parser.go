@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 	"taulang/ast"
+	"taulang/diagnostic"
 	"taulang/lexer"
 	"taulang/token"
 )
@@ -11,16 +15,25 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      // ==
 	LESSGREATER // > or <
+	BITWISE_OR  // |
+	BITWISE_XOR // ^
+	BITWISE_AND // &
+	SHIFT       // << or >>
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
+	POWER       // ** binds tighter than prefix operators, -2 ** 2 is -4
 	CALL        // myFunction(X)
 	INDEX       // myArr[1]
 )
 
 var precedences = map[token.Type]int{
+	token.OR:             LOGICAL_OR,
+	token.AND:            LOGICAL_AND,
 	token.EQUALS:         EQUALS,
 	token.NOT_EQUALS:     EQUALS,
 	token.LESSER_THAN:    LESSGREATER,
@@ -31,13 +44,23 @@ var precedences = map[token.Type]int{
 	token.SUBTRACTION:    SUM,
 	token.DIVISION:       PRODUCT,
 	token.MULTIPLICATION: PRODUCT,
+	token.MODULO:         PRODUCT,
+	token.POWER:          POWER,
+	token.BITWISE_OR:     BITWISE_OR,
+	token.BITWISE_XOR:    BITWISE_XOR,
+	token.BITWISE_AND:    BITWISE_AND,
+	token.LEFT_SHIFT:     SHIFT,
+	token.RIGHT_SHIFT:    SHIFT,
 	token.LEFT_PAREN:     CALL,
 	token.LEFT_BRACKET:   INDEX,
+	token.DOT:            INDEX,
 }
 
 type Parser interface {
 	Parse() *ast.Program
 	Errors() []string
+	// Diagnostics returns the same errors as Errors along with their source positions
+	Diagnostics() []diagnostic.Diagnostic
 }
 
 type (
@@ -47,7 +70,7 @@ type (
 
 type parser struct {
 	lexer  lexer.Lexer
-	errors []string
+	errors []diagnostic.Diagnostic
 
 	currToken token.Token
 	peekToken token.Token
@@ -59,7 +82,7 @@ type parser struct {
 func NewParser(l lexer.Lexer) Parser {
 	p := parser{
 		lexer:  l,
-		errors: []string{},
+		errors: []diagnostic.Diagnostic{},
 	}
 
 	p.prefixParseFunctions = make(map[token.Type]prefixParseFunction)
@@ -67,17 +90,23 @@ func NewParser(l lexer.Lexer) Parser {
 
 	p.prefixParseFunctions[token.IDENTIFIER] = p.parseIdentifier
 	p.prefixParseFunctions[token.NUMBER] = p.parseIntegerLiteral
+	p.prefixParseFunctions[token.FLOAT] = p.parseFloatLiteral
 	p.prefixParseFunctions[token.BANG] = p.parsePrefixExpression
 	p.prefixParseFunctions[token.SUBTRACTION] = p.parsePrefixExpression
+	p.prefixParseFunctions[token.BITWISE_NOT] = p.parsePrefixExpression
 	p.prefixParseFunctions[token.TRUE] = p.parseBoolean
 	p.prefixParseFunctions[token.FALSE] = p.parseBoolean
 	p.prefixParseFunctions[token.STRING] = p.parseString
+	p.prefixParseFunctions[token.INTERPOLATION_START] = p.parseInterpolatedString
 	p.prefixParseFunctions[token.LEFT_PAREN] = p.parseGroupedExpressions
 	p.prefixParseFunctions[token.FUNCTION] = p.parseFunctionLiteral
 	p.prefixParseFunctions[token.LEFT_BRACKET] = p.parseArrayLiteral
 	p.prefixParseFunctions[token.LEFT_BRACE] = p.parseHashLiteral
 	p.prefixParseFunctions[token.IF] = p.parseConditionalExpression
 	p.prefixParseFunctions[token.WHILE] = p.parseWhileLoop
+	p.prefixParseFunctions[token.FOR] = p.parseForInLoop
+	p.prefixParseFunctions[token.IMPORT] = p.parseImportExpression
+	p.prefixParseFunctions[token.TRY] = p.parseTryExpression
 
 	p.infixParseFunctions[token.EQUALS] = p.parseInfixExpression
 	p.infixParseFunctions[token.NOT_EQUALS] = p.parseInfixExpression
@@ -85,13 +114,23 @@ func NewParser(l lexer.Lexer) Parser {
 	p.infixParseFunctions[token.SUBTRACTION] = p.parseInfixExpression
 	p.infixParseFunctions[token.DIVISION] = p.parseInfixExpression
 	p.infixParseFunctions[token.MULTIPLICATION] = p.parseInfixExpression
+	p.infixParseFunctions[token.MODULO] = p.parseInfixExpression
+	p.infixParseFunctions[token.POWER] = p.parseInfixExpression
+	p.infixParseFunctions[token.BITWISE_AND] = p.parseInfixExpression
+	p.infixParseFunctions[token.BITWISE_OR] = p.parseInfixExpression
+	p.infixParseFunctions[token.BITWISE_XOR] = p.parseInfixExpression
+	p.infixParseFunctions[token.LEFT_SHIFT] = p.parseInfixExpression
+	p.infixParseFunctions[token.RIGHT_SHIFT] = p.parseInfixExpression
 	p.infixParseFunctions[token.LESSER_THAN] = p.parseInfixExpression
 	p.infixParseFunctions[token.LESSER_EQUALS] = p.parseInfixExpression
 	p.infixParseFunctions[token.GREATER_THAN] = p.parseInfixExpression
 	p.infixParseFunctions[token.GREATER_EQUALS] = p.parseInfixExpression
+	p.infixParseFunctions[token.AND] = p.parseInfixExpression
+	p.infixParseFunctions[token.OR] = p.parseInfixExpression
 
 	p.infixParseFunctions[token.LEFT_PAREN] = p.parseCallExpression
 	p.infixParseFunctions[token.LEFT_BRACKET] = p.parseIndexExpression
+	p.infixParseFunctions[token.DOT] = p.parseMemberExpression
 
 	// advancing tokens two times to populate both next and curr tokens
 	p.nextToken()
@@ -117,9 +156,21 @@ func (p *parser) Parse() *ast.Program {
 }
 
 func (p *parser) Errors() []string {
+	messages := []string{}
+	for _, e := range p.errors {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func (p *parser) Diagnostics() []diagnostic.Diagnostic {
 	return p.errors
 }
 
+func (p *parser) addError(position token.Position, messageTemplate string, args ...any) {
+	p.errors = append(p.errors, diagnostic.New(position, messageTemplate, args...))
+}
+
 func (p *parser) nextToken() {
 	p.currToken = p.peekToken
 
@@ -154,7 +205,7 @@ func (p *parser) peekTokenMismatchError(expected token.Type) {
 		msg += fmt.Sprintf(" (%s)", actual.Literal)
 	}
 
-	p.errors = append(p.errors, msg)
+	p.addError(actual.Position, "%s", msg)
 }
 
 func (p *parser) noPrefixParseFunctionError(tok token.Token) {
@@ -162,7 +213,7 @@ func (p *parser) noPrefixParseFunctionError(tok token.Token) {
 	if tok.Type == token.ILLEGAL {
 		msg += fmt.Sprintf(" (%s)", tok.Literal)
 	}
-	p.errors = append(p.errors, msg)
+	p.addError(tok.Position, "%s", msg)
 }
 
 func (p *parser) noInfixParseFunctionError(tok token.Token) {
@@ -170,11 +221,11 @@ func (p *parser) noInfixParseFunctionError(tok token.Token) {
 	if tok.Type == token.ILLEGAL {
 		msg += fmt.Sprintf(" (%s)", tok.Literal)
 	}
-	p.errors = append(p.errors, msg)
+	p.addError(tok.Position, "%s", msg)
 }
 
 func (p *parser) callExpressionPeekTokenMismatchError() {
-	p.errors = append(p.errors, fmt.Sprintf("expected next token to be , or ) but got %s", p.peekToken.Literal))
+	p.addError(p.peekToken.Position, "expected next token to be , or ) but got %s", p.peekToken.Literal)
 }
 
 func (p *parser) parseStatement() ast.Statement {
@@ -189,9 +240,11 @@ func (p *parser) parseStatement() ast.Statement {
 		return p.parseBreak()
 	case token.CONTINUE:
 		return p.parseContinue()
+	case token.THROW:
+		return p.parseThrowStatement()
 	case token.IDENTIFIER:
 		// Lookahead: if identifier followed by '=' that means it is assignment statement
-		if p.peekTokenIs(token.ASSIGNMENT) {
+		if p.peekTokenIs(token.ASSIGNMENT) || p.peekTokenIs(token.COMPOUND_ASSIGNMENT) {
 			return p.parseAssignmentStatement()
 		}
 		return p.parseExpressionStatement()
@@ -214,6 +267,9 @@ func (p *parser) parseLetStatement() ast.Statement {
 
 	p.nextToken()
 	statement.Value = p.parseExpression(LOWEST)
+	if function, ok := statement.Value.(*ast.FunctionLiteral); ok {
+		function.Name = statement.Name.Value
+	}
 
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -240,9 +296,11 @@ func (p *parser) parseAssignmentStatement() ast.Statement {
 
 	statement.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 
-	if !p.expectPeekToken(token.ASSIGNMENT) {
+	operator, ok := p.expectPeekAssignment()
+	if !ok {
 		return nil
 	}
+	statement.Operator = operator
 
 	p.nextToken()
 	statement.Value = p.parseExpression(LOWEST)
@@ -261,9 +319,11 @@ func (p *parser) parseIndexAssignmentStatement(indexExpr *ast.IndexExpression) a
 		Index:             indexExpr.Index,
 	}
 
-	if !p.expectPeekToken(token.ASSIGNMENT) {
+	operator, ok := p.expectPeekAssignment()
+	if !ok {
 		return nil
 	}
+	statement.Operator = operator
 
 	p.nextToken()
 	statement.Value = p.parseExpression(LOWEST)
@@ -275,13 +335,27 @@ func (p *parser) parseIndexAssignmentStatement(indexExpr *ast.IndexExpression) a
 	return &statement
 }
 
+// expectPeekAssignment advances over ne_bana_diye or a compound assignment and
+// returns the operator of the compound assignment, e.g. + for +=
+func (p *parser) expectPeekAssignment() (string, bool) {
+	if p.peekTokenIs(token.COMPOUND_ASSIGNMENT) {
+		p.nextToken()
+		return strings.TrimSuffix(p.currToken.Literal, "="), true
+	}
+	if !p.expectPeekToken(token.ASSIGNMENT) {
+		return "", false
+	}
+	return "", true
+}
+
 func (p *parser) parseExpressionStatement() ast.Statement {
 	statement := ast.ExpressionStatement{Token: p.currToken}
 
 	expression := p.parseExpression(LOWEST)
 
 	// Check if this is an index assignment: map["a"] = 1
-	if indexExpr, ok := expression.(*ast.IndexExpression); ok && p.peekTokenIs(token.ASSIGNMENT) {
+	if indexExpr, ok := expression.(*ast.IndexExpression); ok &&
+		(p.peekTokenIs(token.ASSIGNMENT) || p.peekTokenIs(token.COMPOUND_ASSIGNMENT)) {
 		return p.parseIndexAssignmentStatement(indexExpr)
 	}
 
@@ -347,10 +421,28 @@ func (p *parser) parseIdentifier() ast.Expression {
 func (p *parser) parseIntegerLiteral() ast.Expression {
 	expression := ast.IntegerLiteral{Token: p.currToken}
 
-	// TODO: Add support to parse decimal values
 	val, err := strconv.ParseInt(p.currToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if value, ok := new(big.Int).SetString(p.currToken.Literal, 0); ok {
+			expression.Big = value
+			return &expression
+		}
+	}
 	if err != nil {
-		p.errors = append(p.errors, fmt.Sprintf("could not parse %q as integer", p.currToken.Literal))
+		p.addError(p.currToken.Position, "could not parse %q as integer", p.currToken.Literal)
+		return nil
+	}
+	expression.Value = val
+
+	return &expression
+}
+
+func (p *parser) parseFloatLiteral() ast.Expression {
+	expression := ast.FloatLiteral{Token: p.currToken}
+
+	val, err := strconv.ParseFloat(p.currToken.Literal, 64)
+	if err != nil {
+		p.addError(p.currToken.Position, "could not parse %q as float", p.currToken.Literal)
 		return nil
 	}
 	expression.Value = val
@@ -366,6 +458,32 @@ func (p *parser) parseString() ast.Expression {
 	return &ast.String{Token: p.currToken, Value: p.currToken.Literal}
 }
 
+func (p *parser) parseInterpolatedString() ast.Expression {
+	expression := ast.InterpolatedString{Token: p.currToken, Strings: []string{p.currToken.Literal}}
+
+	for p.currTokenIs(token.INTERPOLATION_START) || p.currTokenIs(token.INTERPOLATION_MIDDLE) {
+		p.nextToken()
+		embedded := p.parseExpression(LOWEST)
+		if embedded == nil {
+			return nil
+		}
+		expression.Expressions = append(expression.Expressions, embedded)
+
+		if !p.peekTokenIs(token.INTERPOLATION_MIDDLE) && !p.peekTokenIs(token.INTERPOLATION_END) {
+			msg := fmt.Sprintf("expected } after interpolated expression, got %s", p.peekToken.Type)
+			if p.peekTokenIs(token.ILLEGAL) {
+				msg += fmt.Sprintf(" (%s)", p.peekToken.Literal)
+			}
+			p.addError(p.peekToken.Position, "%s", msg)
+			return nil
+		}
+		p.nextToken()
+		expression.Strings = append(expression.Strings, p.currToken.Literal)
+	}
+
+	return &expression
+}
+
 func (p *parser) parsePrefixExpression() ast.Expression {
 	expression := ast.PrefixExpression{Token: p.currToken, Operator: p.currToken.Literal}
 
@@ -380,6 +498,10 @@ func (p *parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	expression := ast.InfixExpression{Token: p.currToken, Left: left, Operator: p.currToken.Literal}
 
 	precedence := p.currPrecedence()
+	// ** is right associative, 2 ** 3 ** 2 is 2 ** (3 ** 2)
+	if expression.Operator == "**" {
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
@@ -422,34 +544,92 @@ func (p *parser) parseFunctionLiteral() ast.Expression {
 	if p.peekTokenIs(token.RIGHT_PAREN) {
 		p.nextToken()
 		expression.Parameters = []*ast.Identifier{}
-	} else {
-		var params []*ast.Identifier
-		for !p.currTokenIs(token.RIGHT_PAREN) {
-			p.nextToken()
+	} else if !p.parseFunctionParameters(&expression) {
+		return nil
+	}
+
+	if !p.expectPeekToken(token.LEFT_BRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	return &expression
+}
+
+// parseFunctionParameters parses required parameters, followed by parameters
+// with default values and at most one rest parameter at the end, e.g.
+// (a, b ne_bana_diye 2, ...rest). Every parameter needs its own name.
+func (p *parser) parseFunctionParameters(expression *ast.FunctionLiteral) bool {
+	var params []*ast.Identifier
+	names := map[string]bool{}
+	for !p.currTokenIs(token.RIGHT_PAREN) {
+		p.nextToken()
+
+		if expression.Rest != nil {
+			p.addError(p.currToken.Position, "rest parameter must be the last parameter")
+			return false
+		}
+
+		if p.currTokenIs(token.ELLIPSIS) {
+			if !p.expectPeekToken(token.IDENTIFIER) {
+				return false
+			}
+			expression.Rest = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+			if names[expression.Rest.Value] {
+				p.addError(expression.Rest.Position(), "duplicate parameter %s", expression.Rest.Value)
+				return false
+			}
+		} else {
 			param := p.parseExpression(LOWEST)
 			ident, ok := param.(*ast.Identifier)
 			if !ok {
-				p.errors = append(p.errors, fmt.Sprintf("expected IDENTIFIER in function parameters got: %s", param.String()))
-				return nil
+				p.addError(p.currToken.Position, "expected IDENTIFIER in function parameters got: %s", param.String())
+				return false
+			}
+			if names[ident.Value] {
+				p.addError(ident.Position(), "duplicate parameter %s", ident.Value)
+				return false
 			}
+			names[ident.Value] = true
 			params = append(params, ident)
 
-			if !p.peekTokenIs(token.COMMA) && !p.peekTokenIs(token.RIGHT_PAREN) {
-				p.callExpressionPeekTokenMismatchError()
-				return nil
+			if !p.parseParameterDefault(expression, ident, len(params)) {
+				return false
 			}
-			p.nextToken()
 		}
-		expression.Parameters = params
+
+		if !p.peekTokenIs(token.COMMA) && !p.peekTokenIs(token.RIGHT_PAREN) {
+			p.callExpressionPeekTokenMismatchError()
+			return false
+		}
+		p.nextToken()
 	}
+	expression.Parameters = params
 
-	if !p.expectPeekToken(token.LEFT_BRACE) {
-		return nil
+	return true
+}
+
+// parseParameterDefault parses the default value of the count-th parameter if
+// there is one, once a parameter has a default all following ones need one
+func (p *parser) parseParameterDefault(expression *ast.FunctionLiteral, param *ast.Identifier, count int) bool {
+	if !p.peekTokenIs(token.ASSIGNMENT) {
+		if expression.Defaults != nil {
+			p.addError(param.Position(), "parameter %s without default value follows parameter with default value", param.Value)
+			return false
+		}
+		return true
 	}
 
-	expression.Body = p.parseBlockStatement()
+	p.nextToken()
+	p.nextToken()
 
-	return &expression
+	if expression.Defaults == nil {
+		expression.Defaults = make([]ast.Expression, count-1)
+	}
+	expression.Defaults = append(expression.Defaults, p.parseExpression(LOWEST))
+
+	return true
 }
 
 func (p *parser) parseBlockStatement() *ast.BlockStatement {
@@ -464,7 +644,7 @@ func (p *parser) parseBlockStatement() *ast.BlockStatement {
 	}
 
 	if p.currTokenIs(token.EOF) {
-		p.errors = append(p.errors, "expected next token to be RIGHT_BRACE, found EOF")
+		p.addError(p.currToken.Position, "expected next token to be RIGHT_BRACE, found EOF")
 		return nil
 	}
 
@@ -530,6 +710,102 @@ func (p *parser) parseWhileLoop() ast.Expression {
 	return &expression
 }
 
+func (p *parser) parseForInLoop() ast.Expression {
+	expression := ast.ForInExpression{Token: p.currToken}
+
+	if !p.expectPeekToken(token.LEFT_PAREN) {
+		return nil
+	}
+
+	for {
+		if !p.expectPeekToken(token.IDENTIFIER) {
+			return nil
+		}
+		expression.Variables = append(expression.Variables,
+			&ast.Identifier{Token: p.currToken, Value: p.currToken.Literal})
+
+		if len(expression.Variables) == 2 || !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeekToken(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeekToken(token.RIGHT_PAREN) {
+		return nil
+	}
+
+	if !p.expectPeekToken(token.LEFT_BRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	return &expression
+}
+
+func (p *parser) parseTryExpression() ast.Expression {
+	expression := ast.TryExpression{Token: p.currToken}
+
+	if !p.expectPeekToken(token.LEFT_BRACE) {
+		return nil
+	}
+
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+		if !p.expectPeekToken(token.LEFT_PAREN) {
+			return nil
+		}
+		if !p.expectPeekToken(token.IDENTIFIER) {
+			return nil
+		}
+		expression.CatchParameter = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		if !p.expectPeekToken(token.RIGHT_PAREN) {
+			return nil
+		}
+		if !p.expectPeekToken(token.LEFT_BRACE) {
+			return nil
+		}
+		expression.CatchBlock = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+		if !p.expectPeekToken(token.LEFT_BRACE) {
+			return nil
+		}
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.CatchBlock == nil && expression.Finally == nil {
+		p.addError(p.peekToken.Position, "expected catch or finally after try block, got %s", p.peekToken.Literal)
+		return nil
+	}
+
+	return &expression
+}
+
+func (p *parser) parseThrowStatement() ast.Statement {
+	statement := ast.ThrowStatement{Token: p.currToken}
+
+	p.nextToken()
+	statement.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return &statement
+}
+
 func (p *parser) parseBreak() ast.Statement {
 	statement := ast.BreakStatement{Token: p.currToken}
 	if p.peekTokenIs(token.SEMICOLON) {
@@ -577,10 +853,38 @@ func (p *parser) parseExpressionList(end token.Type) []ast.Expression {
 func (p *parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	expression := ast.IndexExpression{Token: p.currToken, IndexedExpression: left}
 
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(expression.Token, left, nil)
+	}
+
 	p.nextToken()
 
 	expression.Index = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(expression.Token, left, expression.Index)
+	}
+
+	if !p.expectPeekToken(token.RIGHT_BRACKET) {
+		return nil
+	}
+
+	return &expression
+}
+
+// parseSliceExpression parses the rest of a slice from the colon on, start is
+// nil when it is left out
+func (p *parser) parseSliceExpression(tok token.Token, left ast.Expression, start ast.Expression) ast.Expression {
+	expression := ast.SliceExpression{Token: tok, Sliced: left, Start: start}
+
+	// skips the colon
+	p.nextToken()
+
+	if !p.peekTokenIs(token.RIGHT_BRACKET) {
+		p.nextToken()
+		expression.End = p.parseExpression(LOWEST)
+	}
+
 	if !p.expectPeekToken(token.RIGHT_BRACKET) {
 		return nil
 	}
@@ -588,6 +892,28 @@ func (p *parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return &expression
 }
 
+func (p *parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	expression := ast.MemberExpression{Token: p.currToken, Object: left}
+
+	if !p.expectPeekToken(token.IDENTIFIER) {
+		return nil
+	}
+
+	expression.Member = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	return &expression
+}
+
+func (p *parser) parseImportExpression() ast.Expression {
+	expression := ast.ImportExpression{Token: p.currToken}
+
+	p.nextToken()
+
+	expression.Path = p.parseExpression(PREFIX)
+
+	return &expression
+}
+
 func (p *parser) parseHashLiteral() ast.Expression {
 	expression := ast.HashLiteral{
 		Token: p.currToken,
@@ -1,7 +1,9 @@
 package parser_test
 
 import (
+	"reflect"
 	"taulang/ast"
+	"taulang/diagnostic"
 	"taulang/lexer"
 	"taulang/parser"
 	"taulang/token"
@@ -79,6 +81,31 @@ func TestParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "success - parse interpolated string",
+			input:          `$"a{x}b{1 + 2}";`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.INTERPOLATION_START, Literal: "a"},
+						Expression: &ast.InterpolatedString{
+							Token:   token.Token{Type: token.INTERPOLATION_START, Literal: "a"},
+							Strings: []string{"a", "b", ""},
+							Expressions: []ast.Expression{
+								&ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "x"}, Value: "x"},
+								&ast.InfixExpression{
+									Token:    token.Token{Type: token.ADDITION, Literal: "+"},
+									Left:     &ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "1"}, Value: 1},
+									Operator: "+",
+									Right:    &ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "2"}, Value: 2},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:           "success - parse let statement with identifier value",
 			input:          `sun_liyo_tau x ne_bana_diye y;`,
@@ -261,6 +288,7 @@ func TestParser(t *testing.T) {
 						Name:  &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "some_func"}, Value: "some_func"},
 						Value: &ast.FunctionLiteral{
 							Token: token.Token{Type: token.FUNCTION, Literal: "func"},
+							Name:  "some_func",
 							Parameters: []*ast.Identifier{
 								{Token: token.Token{Type: token.IDENTIFIER, Literal: "x"}, Value: "x"},
 							},
@@ -316,6 +344,7 @@ func TestParser(t *testing.T) {
 						Name:  &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "ourFunction"}, Value: "ourFunction"},
 						Value: &ast.FunctionLiteral{
 							Token: token.Token{Type: token.FUNCTION, Literal: "func"},
+							Name:  "ourFunction",
 							Parameters: []*ast.Identifier{
 								{Token: token.Token{Type: token.IDENTIFIER, Literal: "first"}, Value: "first"},
 							},
@@ -630,6 +659,35 @@ func TestParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "success - for-in loop",
+			input:          `har_ek (k, v mein h) { k }`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.FOR, Literal: "for"},
+						Expression: &ast.ForInExpression{
+							Token: token.Token{Type: token.FOR, Literal: "for"},
+							Variables: []*ast.Identifier{
+								{Token: token.Token{Type: token.IDENTIFIER, Literal: "k"}, Value: "k"},
+								{Token: token.Token{Type: token.IDENTIFIER, Literal: "v"}, Value: "v"},
+							},
+							Iterable: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "h"}, Value: "h"},
+							Body: &ast.BlockStatement{
+								Token: token.Token{Type: token.LEFT_BRACE, Literal: "{"},
+								Statements: []ast.Statement{
+									&ast.ExpressionStatement{
+										Token:      token.Token{Type: token.IDENTIFIER, Literal: "k"},
+										Expression: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "k"}, Value: "k"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:           "success - assignment statement 1",
 			input:          "x ne_bana_diye x + 1",
@@ -845,6 +903,31 @@ func TestParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "success - slice expression",
+			input:          "s[1:]; s[:n]",
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.IDENTIFIER, Literal: "s"},
+						Expression: &ast.SliceExpression{
+							Token:  token.Token{Type: token.LEFT_BRACKET, Literal: "["},
+							Sliced: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "s"}, Value: "s"},
+							Start:  &ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "1"}, Value: 1},
+						},
+					},
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.IDENTIFIER, Literal: "s"},
+						Expression: &ast.SliceExpression{
+							Token:  token.Token{Type: token.LEFT_BRACKET, Literal: "["},
+							Sliced: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "s"}, Value: "s"},
+							End:    &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "n"}, Value: "n"},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:           "success - index expression - array 2",
 			input:          "myArr[1]",
@@ -1122,6 +1205,240 @@ func TestParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "success - float literal",
+			input:          `sun_liyo_tau pi ne_bana_diye 3.14;`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.LetStatement{
+						Token: token.Token{Type: token.LET, Literal: "let"},
+						Name:  &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "pi"}, Value: "pi"},
+						Value: &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: "3.14"}, Value: 3.14},
+					},
+				},
+			},
+		},
+		{
+			name:           "success - negative float in infix expression",
+			input:          `-0.5 * 2`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.SUBTRACTION, Literal: "-"},
+						Expression: &ast.InfixExpression{
+							Token: token.Token{Type: token.MULTIPLICATION, Literal: "*"},
+							Left: &ast.PrefixExpression{
+								Token:    token.Token{Type: token.SUBTRACTION, Literal: "-"},
+								Operator: "-",
+								Operand:  &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: "0.5"}, Value: 0.5},
+							},
+							Operator: "*",
+							Right:    &ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "2"}, Value: 2},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "success - logical operators precedence",
+			input:          `a ya_phir b && c == d`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.IDENTIFIER, Literal: "a"},
+						Expression: &ast.InfixExpression{
+							Token:    token.Token{Type: token.OR, Literal: "||"},
+							Left:     &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "a"}, Value: "a"},
+							Operator: "||",
+							Right: &ast.InfixExpression{
+								Token:    token.Token{Type: token.AND, Literal: "&&"},
+								Left:     &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "b"}, Value: "b"},
+								Operator: "&&",
+								Right: &ast.InfixExpression{
+									Token:    token.Token{Type: token.EQUALS, Literal: "=="},
+									Left:     &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "c"}, Value: "c"},
+									Operator: "==",
+									Right:    &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "d"}, Value: "d"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "success - default and rest parameters",
+			input:          `tau_ka_jugaad(a, b ne_bana_diye 2, ...rest) { a }`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.FUNCTION, Literal: "func"},
+						Expression: &ast.FunctionLiteral{
+							Token: token.Token{Type: token.FUNCTION, Literal: "func"},
+							Parameters: []*ast.Identifier{
+								{Token: token.Token{Type: token.IDENTIFIER, Literal: "a"}, Value: "a"},
+								{Token: token.Token{Type: token.IDENTIFIER, Literal: "b"}, Value: "b"},
+							},
+							Defaults: []ast.Expression{
+								nil,
+								&ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "2"}, Value: 2},
+							},
+							Rest: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "rest"}, Value: "rest"},
+							Body: &ast.BlockStatement{
+								Token: token.Token{Type: token.LEFT_BRACE, Literal: "{"},
+								Statements: []ast.Statement{
+									&ast.ExpressionStatement{
+										Token:      token.Token{Type: token.IDENTIFIER, Literal: "a"},
+										Expression: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "a"}, Value: "a"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "success - import expression",
+			input:          `sun_liyo_tau m ne_bana_diye mangwa_lo "./math.tau";`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.LetStatement{
+						Token: token.Token{Type: token.LET, Literal: "let"},
+						Name:  &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "m"}, Value: "m"},
+						Value: &ast.ImportExpression{
+							Token: token.Token{Type: token.IMPORT, Literal: "import"},
+							Path:  &ast.String{Token: token.Token{Type: token.STRING, Literal: "./math.tau"}, Value: "./math.tau"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "success - member access binds tighter than calls and operators",
+			input:          `m.add(1) + m.pi`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.IDENTIFIER, Literal: "m"},
+						Expression: &ast.InfixExpression{
+							Token: token.Token{Type: token.ADDITION, Literal: "+"},
+							Left: &ast.CallExpression{
+								Token: token.Token{Type: token.LEFT_PAREN, Literal: "("},
+								Function: &ast.MemberExpression{
+									Token:  token.Token{Type: token.DOT, Literal: "."},
+									Object: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "m"}, Value: "m"},
+									Member: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "add"}, Value: "add"},
+								},
+								Arguments: []ast.Expression{
+									&ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "1"}, Value: 1},
+								},
+							},
+							Operator: "+",
+							Right: &ast.MemberExpression{
+								Token:  token.Token{Type: token.DOT, Literal: "."},
+								Object: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "m"}, Value: "m"},
+								Member: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "pi"}, Value: "pi"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "success - try catch finally",
+			input:          `koshish_karo { x } pakad_lo (e) { e } aakhir_mein { 1 }`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.TRY, Literal: "try"},
+						Expression: &ast.TryExpression{
+							Token: token.Token{Type: token.TRY, Literal: "try"},
+							Block: &ast.BlockStatement{
+								Token: token.Token{Type: token.LEFT_BRACE, Literal: "{"},
+								Statements: []ast.Statement{
+									&ast.ExpressionStatement{
+										Token:      token.Token{Type: token.IDENTIFIER, Literal: "x"},
+										Expression: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "x"}, Value: "x"},
+									},
+								},
+							},
+							CatchParameter: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "e"}, Value: "e"},
+							CatchBlock: &ast.BlockStatement{
+								Token: token.Token{Type: token.LEFT_BRACE, Literal: "{"},
+								Statements: []ast.Statement{
+									&ast.ExpressionStatement{
+										Token:      token.Token{Type: token.IDENTIFIER, Literal: "e"},
+										Expression: &ast.Identifier{Token: token.Token{Type: token.IDENTIFIER, Literal: "e"}, Value: "e"},
+									},
+								},
+							},
+							Finally: &ast.BlockStatement{
+								Token: token.Token{Type: token.LEFT_BRACE, Literal: "{"},
+								Statements: []ast.Statement{
+									&ast.ExpressionStatement{
+										Token:      token.Token{Type: token.NUMBER, Literal: "1"},
+										Expression: &ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "1"}, Value: 1},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "success - throw statement",
+			input:          `fenk_do "oops";`,
+			expectedErrors: []string{},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ThrowStatement{
+						Token: token.Token{Type: token.THROW, Literal: "throw"},
+						Value: &ast.String{Token: token.Token{Type: token.STRING, Literal: "oops"}, Value: "oops"},
+					},
+				},
+			},
+		},
+		{
+			name:  "failure - try without catch or finally",
+			input: `koshish_karo { 1 };`,
+			expectedErrors: []string{
+				"expected catch or finally after try block, got ;",
+			},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.TRY, Literal: "try"},
+					},
+				},
+			},
+		},
+		{
+			name:  "failure - member access requires an identifier",
+			input: `m.1`,
+			expectedErrors: []string{
+				"expected next token to be IDENTIFIER, got NUMBER",
+			},
+			expectedProgram: &ast.Program{
+				Statements: []ast.Statement{
+					&ast.ExpressionStatement{
+						Token: token.Token{Type: token.IDENTIFIER, Literal: "m"},
+					},
+					&ast.ExpressionStatement{
+						Token:      token.Token{Type: token.NUMBER, Literal: "1"},
+						Expression: &ast.IntegerLiteral{Token: token.Token{Type: token.NUMBER, Literal: "1"}, Value: 1},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -1134,8 +1451,233 @@ func TestParser(t *testing.T) {
 			p := parser.NewParser(l)
 			program := p.Parse()
 
+			clearPositions(reflect.ValueOf(program))
 			assert.Equal(t, tc.expectedProgram, program)
 			assert.Equal(t, tc.expectedErrors, p.Errors())
 		})
 	}
 }
+
+func TestParserFunctionParameterErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name:          "required parameter after default",
+			input:         "tau_ka_jugaad(a ne_bana_diye 1, b) { a }",
+			expectedError: "parameter b without default value follows parameter with default value",
+		},
+		{
+			name:          "parameter after rest",
+			input:         "tau_ka_jugaad(...rest, a) { a }",
+			expectedError: "rest parameter must be the last parameter",
+		},
+		{
+			name:          "duplicate parameter",
+			input:         "tau_ka_jugaad(a, b, a) { a }",
+			expectedError: "duplicate parameter a",
+		},
+		{
+			name:          "rest parameter named like a parameter",
+			input:         "tau_ka_jugaad(a, ...a) { a }",
+			expectedError: "duplicate parameter a",
+		},
+		{
+			name:          "rest without name",
+			input:         "tau_ka_jugaad(...) { 1 }",
+			expectedError: "expected next token to be IDENTIFIER, got RIGHT_PAREN",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			p.Parse()
+
+			errors := p.Errors()
+			assert.NotEmpty(t, errors)
+			if len(errors) > 0 {
+				assert.Equal(t, tc.expectedError, errors[0])
+			}
+		})
+	}
+}
+
+func TestParserOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "modulo binds like multiplication", input: "a + b % c * d", expected: "(a + ((b % c) * d));"},
+		{name: "power is right associative", input: "a ** b ** c", expected: "(a ** (b ** c));"},
+		{name: "power binds tighter than prefix operators", input: "-a ** b * c", expected: "((-(a ** b)) * c);"},
+		{name: "power of a prefix operand", input: "a ** -b", expected: "(a ** (-b));"},
+		{name: "shifts bind looser than sums", input: "a << b + c >> d", expected: "((a << (b + c)) >> d);"},
+		{name: "bitwise operators", input: "a | b ^ c & d << e", expected: "(a | (b ^ (c & (d << e))));"},
+		{name: "bitwise operators bind tighter than comparisons", input: "a & b == c | d", expected: "((a & b) == (c | d));"},
+		{name: "bitwise not", input: "~a & ~b", expected: "((~a) & (~b));"},
+		{name: "compound assignment", input: "x **= y + 1", expected: "x **= (y + 1);"},
+		{name: "compound index assignment", input: "a[i] <<= 2", expected: "a[i] <<= 2;"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			program := p.Parse()
+			assert.Empty(t, p.Errors())
+			assert.Equal(t, tc.expected, program.String())
+		})
+	}
+}
+
+func TestParserPositions(t *testing.T) {
+	input := `sun_liyo_tau add ne_bana_diye tau_ka_jugaad(a, b) {
+	laadle_ye_le a + b;
+};`
+
+	l, err := lexer.NewLexer(input)
+	assert.NoError(t, err)
+
+	p := parser.NewParser(l)
+	program := p.Parse()
+	assert.Empty(t, p.Errors())
+
+	let := program.Statements[0].(*ast.LetStatement)
+	assert.Equal(t, token.Position{Line: 1, Column: 1, Offset: 0}, let.Position())
+	assert.Equal(t, token.Position{Line: 1, Column: 14, Offset: 13}, let.Name.Position())
+
+	function := let.Value.(*ast.FunctionLiteral)
+	assert.Equal(t, token.Position{Line: 1, Column: 31, Offset: 30}, function.Position())
+
+	ret := function.Body.Statements[0].(*ast.ReturnStatement)
+	assert.Equal(t, token.Position{Line: 2, Column: 2, Offset: 53}, ret.Position())
+
+	sum := ret.ReturnValue.(*ast.InfixExpression)
+	assert.Equal(t, token.Position{Line: 2, Column: 17, Offset: 68}, sum.Position())
+	assert.Equal(t, token.Position{Line: 2, Column: 15, Offset: 66}, sum.Left.Position())
+}
+
+func TestParserDiagnostics(t *testing.T) {
+	tests := []struct {
+		name                string
+		input               string
+		expectedDiagnostics []diagnostic.Diagnostic
+	}{
+		{
+			name:  "peek token mismatch points at the unexpected token",
+			input: "sun_liyo_tau x ne_bana_diye (1 + 2;",
+			expectedDiagnostics: []diagnostic.Diagnostic{
+				{
+					Position: token.Position{Line: 1, Column: 35, Offset: 34},
+					Message:  "expected next token to be RIGHT_PAREN, got SEMICOLON",
+				},
+			},
+		},
+		{
+			name:  "missing prefix parse function points at the token",
+			input: "sun_liyo_tau x ne_bana_diye 1;\n  ;",
+			expectedDiagnostics: []diagnostic.Diagnostic{
+				{
+					Position: token.Position{Line: 2, Column: 3, Offset: 33},
+					Message:  "no prefix parse function found for SEMICOLON",
+				},
+			},
+		},
+		{
+			name:  "unclosed interpolated expression points at the token after it",
+			input: `$"a {x y}"`,
+			expectedDiagnostics: []diagnostic.Diagnostic{
+				{
+					Position: token.Position{Line: 1, Column: 8, Offset: 7},
+					Message:  "expected } after interpolated expression, got IDENTIFIER",
+				},
+				{
+					Position: token.Position{Line: 1, Column: 9, Offset: 8},
+					Message:  "no prefix parse function found for INTERPOLATION_END",
+				},
+			},
+		},
+		{
+			name:  "for-in loop without mein",
+			input: "har_ek (x, y, z mein arr) {}",
+			expectedDiagnostics: []diagnostic.Diagnostic{
+				{
+					Position: token.Position{Line: 1, Column: 13, Offset: 12},
+					Message:  "expected next token to be IN, got COMMA",
+				},
+				{
+					Position: token.Position{Line: 1, Column: 13, Offset: 12},
+					Message:  "no prefix parse function found for COMMA",
+				},
+				{
+					Position: token.Position{Line: 1, Column: 17, Offset: 16},
+					Message:  "no prefix parse function found for IN",
+				},
+				{
+					Position: token.Position{Line: 1, Column: 25, Offset: 24},
+					Message:  "no prefix parse function found for RIGHT_PAREN",
+				},
+			},
+		},
+		{
+			name:  "unterminated block points at EOF",
+			input: "jab_tak (saccha) {\n  rok_diye;",
+			expectedDiagnostics: []diagnostic.Diagnostic{
+				{
+					Position: token.Position{Line: 2, Column: 12, Offset: 30},
+					Message:  "expected next token to be RIGHT_BRACE, found EOF",
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			p.Parse()
+
+			assert.Equal(t, tc.expectedDiagnostics, p.Diagnostics())
+		})
+	}
+}
+
+// clearPositions zeroes every token position in the tree so that the expected
+// programs above can be described by their shape alone
+func clearPositions(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			clearPositions(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			clearPositions(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(token.Position{}) {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			clearPositions(v.Field(i))
+		}
+	}
+}
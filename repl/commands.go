@@ -0,0 +1,201 @@
+package repl
+
+import (
+	"log"
+	"os"
+	"strings"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/token"
+	"time"
+)
+
+// session is the state of a running REPL, commands may replace the executor
+type session struct {
+	engine       Engine
+	capabilities object.Capabilities
+	streams      *object.Streams
+	exec         executor
+	logger       *log.Logger
+}
+
+// command is a colon-prefixed REPL command, e.g. `:type 1 + 2`
+type command struct {
+	name        string
+	usage       string
+	description string
+	// run handles the command with the text after its name, it reports true
+	// when the REPL should quit
+	run func(s *session, args string) bool
+}
+
+var commands []command
+
+func init() {
+	// commands is filled in init as :help refers to it
+	commands = []command{
+		{"help", ":help", "show the REPL commands", (*session).help},
+		{"env", ":env", "list the bindings with their types", (*session).env},
+		{"type", ":type <expr>", "show the type of the value of an expression", (*session).typeOf},
+		{"ast", ":ast <input>", "print the parsed AST of the input", (*session).ast},
+		{"tokens", ":tokens <input>", "print the tokens of the input", (*session).tokens},
+		{"load", ":load <file>", "run a .tau file in the session", (*session).load},
+		{"reset", ":reset", "forget all bindings", (*session).reset},
+		{"time", ":time <expr>", "run the input and show how long it took", (*session).time},
+		{"quit", ":quit", "exit the REPL", func(*session, string) bool { return true }},
+	}
+}
+
+// isCommand reports whether the line is a REPL command rather than code
+func isCommand(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), ":")
+}
+
+// runCommand runs the command on the line, it reports true when the REPL
+// should quit
+func (s *session) runCommand(line string) bool {
+	name, args, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), ":"), " ")
+	args = strings.TrimSpace(args)
+
+	for _, c := range commands {
+		if c.name == name {
+			return c.run(s, args)
+		}
+	}
+
+	s.logger.Printf("unknown command :%s, type :help for the REPL commands\n", name)
+	return false
+}
+
+func (s *session) help(string) bool {
+	for _, c := range commands {
+		s.logger.Printf("  %-16s %s\n", c.usage, c.description)
+	}
+	return false
+}
+
+func (s *session) env(string) bool {
+	env := s.exec.environment()
+	for _, name := range env.Names() {
+		value, _ := env.Get(name)
+		s.logger.Printf("%s: %s\n", name, value.Type())
+	}
+	return false
+}
+
+func (s *session) typeOf(args string) bool {
+	if !s.requireArgs("type", args) {
+		return false
+	}
+
+	program, ok := parseInput(replFilename, args, s.logger)
+	if !ok {
+		return false
+	}
+
+	output := s.exec.execute(program)
+	if output.Type() == object.ERROR_OBJ {
+		printOutput(replFilename, args, s.logger, output)
+		return false
+	}
+
+	s.logger.Println(output.Type())
+	return false
+}
+
+func (s *session) ast(args string) bool {
+	if !s.requireArgs("ast", args) {
+		return false
+	}
+
+	program, ok := parseInput(replFilename, args, s.logger)
+	if !ok {
+		return false
+	}
+
+	for _, statement := range program.Statements {
+		s.logger.Println(statement.String())
+	}
+	return false
+}
+
+func (s *session) tokens(args string) bool {
+	if !s.requireArgs("tokens", args) {
+		return false
+	}
+
+	l, err := lexer.NewLexer(args)
+	if err != nil {
+		s.logger.Println(err)
+		return false
+	}
+
+	for {
+		tok := l.NextToken()
+		s.logger.Printf("%s %s %q\n", tok.Position, tok.Type, tok.Literal)
+
+		// the lexer doesn't recover from illegal input
+		if tok.Type == token.EOF || tok.Type == token.ILLEGAL {
+			return false
+		}
+	}
+}
+
+func (s *session) load(args string) bool {
+	if !s.requireArgs("load", args) {
+		return false
+	}
+
+	content, err := os.ReadFile(args)
+	if err != nil {
+		s.logger.Printf("cannot load %s: %v\n", args, err)
+		return false
+	}
+
+	// the file runs in the session but imports relative to its own directory
+	context := s.exec.environment().Context()
+	filename := context.Filename
+	context.Filename = args
+	defer func() { context.Filename = filename }()
+
+	executeInputWithExecutor(args, string(content), s.logger, s.exec)
+	return false
+}
+
+func (s *session) reset(string) bool {
+	s.exec = newExecutor(s.engine, "", s.capabilities, s.streams)
+	s.logger.Println("environment reset")
+	return false
+}
+
+func (s *session) time(args string) bool {
+	if !s.requireArgs("time", args) {
+		return false
+	}
+
+	program, ok := parseInput(replFilename, args, s.logger)
+	if !ok {
+		return false
+	}
+
+	start := time.Now()
+	output := s.exec.execute(program)
+	elapsed := time.Since(start)
+
+	printOutput(replFilename, args, s.logger, output)
+	s.logger.Printf("took %s\n", elapsed)
+	return false
+}
+
+// requireArgs reports a missing argument of the command name
+func (s *session) requireArgs(name string, args string) bool {
+	if args == "" {
+		for _, c := range commands {
+			if c.name == name {
+				s.logger.Printf("usage: %s\n", c.usage)
+			}
+		}
+		return false
+	}
+	return true
+}
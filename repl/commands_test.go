@@ -0,0 +1,119 @@
+package repl
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lib.tau")
+	err := os.WriteFile(file, []byte("sun_liyo_tau loaded ne_bana_diye 7;"), 0o644)
+	assert.NoError(t, err)
+
+	importer := filepath.Join(dir, "sub", "a.tau")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(importer), 0o755))
+	assert.NoError(t, os.WriteFile(importer, []byte(`sun_liyo_tau b ne_bana_diye mangwa_lo "b.tau";`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.tau"), []byte("sun_liyo_tau x ne_bana_diye 1;"), 0o644))
+
+	tests := []struct {
+		name     string
+		input    []string
+		expected string
+	}{
+		{
+			name:     "env lists bindings with types",
+			input:    []string{"sun_liyo_tau a ne_bana_diye 1;", "sun_liyo_tau b ne_bana_diye \"x\";", ":env"},
+			expected: "a: INTEGER\nb: STRING\n",
+		},
+		{
+			name:     "type of an expression",
+			input:    []string{":type [1, 2]"},
+			expected: "ARRAY\n",
+		},
+		{
+			name:     "ast of an expression",
+			input:    []string{":ast 1 + 2 * 3"},
+			expected: "(1 + (2 * 3));\n",
+		},
+		{
+			name:     "tokens of the input",
+			input:    []string{":tokens x + 1"},
+			expected: "1:1 IDENTIFIER \"x\"\n1:3 ADDITION \"+\"\n1:5 NUMBER \"1\"\n1:6 EOF \"\"\n",
+		},
+		{
+			name:     "load runs a file in the session",
+			input:    []string{":load " + file, ":type loaded"},
+			expected: "\nINTEGER\n",
+		},
+		{
+			name:     "imports of a loaded file are relative to it",
+			input:    []string{":load " + importer, ":type b.x"},
+			expected: "\nINTEGER\n",
+		},
+		{
+			name:     "reset forgets bindings",
+			input:    []string{"sun_liyo_tau a ne_bana_diye 1;", ":reset", ":env"},
+			expected: "environment reset\n",
+		},
+		{
+			name:     "missing argument shows the usage",
+			input:    []string{":type"},
+			expected: "usage: :type <expr>\n",
+		},
+		{
+			name:     "unknown command",
+			input:    []string{":nope"},
+			expected: "unknown command :nope, type :help for the REPL commands\n",
+		},
+	}
+
+	for _, engine := range []Engine{EngineEval, EngineVM} {
+		for _, tt := range tests {
+			t.Run(string(engine)+"/"+tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				var out bytes.Buffer
+				s := &session{engine: engine, exec: newExecutor(engine, "", nil, nil), logger: log.New(&out, "", 0)}
+				for _, line := range tt.input {
+					if isCommand(line) {
+						s.runCommand(line)
+					} else {
+						executeInputWithExecutor(replFilename, line, s.logger, s.exec)
+						out.Reset()
+					}
+				}
+
+				assert.Equal(t, tt.expected, out.String())
+			})
+		}
+	}
+}
+
+func TestRunCommandTime(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	s := &session{engine: EngineEval, exec: newExecutor(EngineEval, "", nil, nil), logger: log.New(&out, "", 0)}
+
+	assert.False(t, s.runCommand(":time 1 + 2"))
+	assert.Regexp(t, `^3\ntook .+\n$`, out.String())
+	assert.True(t, s.runCommand(":quit"))
+}
+
+func TestExecuteInputPrintsToLogger(t *testing.T) {
+	for _, engine := range []Engine{EngineEval, EngineVM} {
+		t.Run(string(engine), func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			ExecuteInput("main.tau", `print("tau"); printf("%d\n", 42); 1`, log.New(&out, "", 0), engine, nil)
+			assert.Equal(t, "tau\n42\n1\n", out.String())
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package repl
+
+import (
+	"sort"
+	"strings"
+	"taulang/evaluator"
+	"taulang/object"
+	"taulang/token"
+)
+
+// completions returns the sorted keywords, builtins and names bound in env
+// that start with prefix
+func completions(prefix string, env object.Environment) []string {
+	seen := map[string]bool{}
+	var candidates []string
+	add := func(name string) {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+
+	for keyword := range token.Keywords {
+		add(keyword)
+	}
+	for _, name := range evaluator.BuiltinNames() {
+		add(name)
+	}
+	for _, name := range env.Names() {
+		add(name)
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
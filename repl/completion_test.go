@@ -0,0 +1,20 @@
+package repl
+
+import (
+	"taulang/object"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletions(t *testing.T) {
+	t.Parallel()
+
+	env := object.NewEnvironment()
+	env.Set("laddoo", &object.Integer{Value: 1})
+	env.Set("total", &object.Integer{Value: 2})
+
+	assert.Equal(t, []string{"laadle_ye_le", "laddoo", "last"}, completions("la", env))
+	assert.Equal(t, []string{"total"}, completions("to", env))
+	assert.Empty(t, completions("zzz", env))
+}
@@ -0,0 +1,407 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted is returned by ReadLine when the input is cancelled with Ctrl-C
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads the input of the REPL one line at a time
+type lineReader interface {
+	// ReadLine shows the prompt and returns the line without the newline, or
+	// io.EOF when the input has ended
+	ReadLine(prompt string) (string, error)
+}
+
+// pipeReader reads lines from input that is not a terminal, e.g. a pipe. The
+// programs read their input from the same reader, so that the lines they read
+// are not taken for code.
+type pipeReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *pipeReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// key is a key press, runes for characters and control keys and negative
+// values for the keys that terminals send as escape sequences
+type key rune
+
+const (
+	keyUnknown key = -(iota + 1)
+	keyLeft
+	keyRight
+	keyUp
+	keyDown
+	keyHome
+	keyEnd
+	keyDelete
+)
+
+const (
+	keyTab       key = '\t'
+	keyEnter     key = '\r'
+	keyNewline   key = '\n'
+	keyEscape    key = 27
+	keyBackspace key = 127
+)
+
+func ctrl(r rune) key {
+	return key(r & 0x1f)
+}
+
+// lineEditor reads lines from a terminal in raw mode with cursor movement,
+// history recall, reverse search and tab completion
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	history  *history
+	complete func(prefix string) []string
+}
+
+func newLineEditor(in io.Reader, out io.Writer, history *history, complete func(prefix string) []string) *lineEditor {
+	return &lineEditor{in: bufio.NewReader(in), out: out, history: history, complete: complete}
+}
+
+// editState is the line being edited by one ReadLine call
+type editState struct {
+	editor *lineEditor
+	prompt string
+	line   []rune
+	cursor int
+
+	// historyIndex is the entry shown, len(entries) for the new line whose
+	// text is kept in draft while browsing
+	historyIndex int
+	draft        []rune
+
+	// searching is set during reverse search, searchIndex is the entry that
+	// matches query or -1
+	searching   bool
+	query       []rune
+	searchIndex int
+	// original is the line before the search, restored when it is cancelled
+	original []rune
+}
+
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	s := &editState{editor: e, prompt: prompt, historyIndex: len(e.history.entries)}
+	s.render()
+
+	for {
+		k, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		if s.searching && s.handleSearchKey(k) {
+			s.render()
+			continue
+		}
+
+		switch k {
+		case keyEnter, keyNewline:
+			fmt.Fprint(e.out, "\r\n")
+			line := string(s.line)
+			e.history.add(line)
+			return line, nil
+		case ctrl('C'):
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case ctrl('D'):
+			if len(s.line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			s.deleteAt(s.cursor)
+		case keyBackspace, ctrl('H'):
+			if s.cursor > 0 {
+				s.cursor--
+				s.deleteAt(s.cursor)
+			}
+		case keyDelete:
+			s.deleteAt(s.cursor)
+		case keyLeft, ctrl('B'):
+			s.cursor = max(s.cursor-1, 0)
+		case keyRight, ctrl('F'):
+			s.cursor = min(s.cursor+1, len(s.line))
+		case keyHome, ctrl('A'):
+			s.cursor = 0
+		case keyEnd, ctrl('E'):
+			s.cursor = len(s.line)
+		case keyUp, ctrl('P'):
+			s.showHistory(s.historyIndex - 1)
+		case keyDown, ctrl('N'):
+			s.showHistory(s.historyIndex + 1)
+		case ctrl('K'):
+			s.line = s.line[:s.cursor]
+		case ctrl('U'):
+			s.line = s.line[s.cursor:]
+			s.cursor = 0
+		case ctrl('W'):
+			end := s.cursor
+			for end > 0 && unicode.IsSpace(s.line[end-1]) {
+				end--
+			}
+			start := s.wordStart(end, func(r rune) bool { return !unicode.IsSpace(r) })
+			s.line = append(s.line[:start], s.line[s.cursor:]...)
+			s.cursor = start
+		case ctrl('L'):
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case ctrl('R'):
+			s.searching = true
+			s.query = nil
+			s.searchIndex = -1
+			s.original = s.line
+		case keyTab:
+			s.completeWord()
+		default:
+			if k > 0 && unicode.IsPrint(rune(k)) {
+				s.insert(rune(k))
+			}
+		}
+
+		s.render()
+	}
+}
+
+// readKey reads a key press, decoding the escape sequences of special keys
+func (e *lineEditor) readKey() (key, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if key(r) != keyEscape {
+		return key(r), nil
+	}
+
+	// a lone escape key press isn't followed by anything
+	if e.in.Buffered() == 0 {
+		return keyEscape, nil
+	}
+
+	introducer, err := e.in.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if introducer != '[' && introducer != 'O' {
+		return keyUnknown, nil
+	}
+
+	// parameters are digits and semicolons, the sequence ends with a letter or ~
+	var params strings.Builder
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			return decodeEscapeSequence(params.String(), b), nil
+		}
+		params.WriteByte(b)
+	}
+}
+
+func decodeEscapeSequence(params string, final byte) key {
+	switch final {
+	case 'A':
+		return keyUp
+	case 'B':
+		return keyDown
+	case 'C':
+		return keyRight
+	case 'D':
+		return keyLeft
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case '~':
+		switch params {
+		case "1", "7":
+			return keyHome
+		case "4", "8":
+			return keyEnd
+		case "3":
+			return keyDelete
+		}
+	}
+	return keyUnknown
+}
+
+// render redraws the line and moves the terminal cursor to the edit cursor
+func (s *editState) render() {
+	prompt := s.prompt
+	if s.searching {
+		prompt = fmt.Sprintf("(reverse-i-search)`%s': ", string(s.query))
+	}
+
+	var out strings.Builder
+	out.WriteString("\r")
+	out.WriteString(prompt)
+	out.WriteString(string(s.line))
+	out.WriteString("\x1b[K\r")
+	if column := len([]rune(prompt)) + s.cursor; column > 0 {
+		fmt.Fprintf(&out, "\x1b[%dC", column)
+	}
+
+	fmt.Fprint(s.editor.out, out.String())
+}
+
+func (s *editState) insert(runes ...rune) {
+	line := make([]rune, 0, len(s.line)+len(runes))
+	line = append(line, s.line[:s.cursor]...)
+	line = append(line, runes...)
+	line = append(line, s.line[s.cursor:]...)
+
+	s.line = line
+	s.cursor += len(runes)
+}
+
+func (s *editState) deleteAt(idx int) {
+	if idx < len(s.line) {
+		s.line = append(s.line[:idx], s.line[idx+1:]...)
+	}
+}
+
+// wordStart returns where the word ending at end starts, words are made of
+// the runes accepted by inWord
+func (s *editState) wordStart(end int, inWord func(rune) bool) int {
+	start := end
+	for start > 0 && inWord(s.line[start-1]) {
+		start--
+	}
+	return start
+}
+
+func (s *editState) showHistory(idx int) {
+	entries := s.editor.history.entries
+	if idx < 0 || idx > len(entries) {
+		return
+	}
+
+	if s.historyIndex == len(entries) {
+		s.draft = s.line
+	}
+
+	s.historyIndex = idx
+	if idx == len(entries) {
+		s.line = s.draft
+	} else {
+		s.line = []rune(entries[idx])
+	}
+	s.cursor = len(s.line)
+}
+
+// handleSearchKey handles a key during reverse search, it reports false for
+// keys that end the search and are then handled as usual
+func (s *editState) handleSearchKey(k key) bool {
+	entries := s.editor.history.entries
+
+	switch {
+	case k == ctrl('R'):
+		// finds an older match
+		before := len(entries)
+		if s.searchIndex != -1 {
+			before = s.searchIndex
+		}
+		s.findMatch(before)
+		return true
+	case k == ctrl('G'):
+		s.searching = false
+		s.line = s.original
+		s.cursor = len(s.line)
+		return true
+	case k == keyBackspace || k == ctrl('H'):
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.findMatch(len(entries))
+		}
+		return true
+	case k > 0 && unicode.IsPrint(rune(k)):
+		s.query = append(s.query, rune(k))
+		// the current match is kept as long as it still matches
+		from := len(entries)
+		if s.searchIndex != -1 {
+			from = s.searchIndex + 1
+		}
+		s.findMatch(from)
+		return true
+	default:
+		s.searching = false
+		return false
+	}
+}
+
+func (s *editState) findMatch(before int) {
+	if idx := s.editor.history.search(string(s.query), before); idx != -1 {
+		s.showMatch(idx)
+	}
+}
+
+// showMatch shows the entry idx with the cursor at the start of the match
+func (s *editState) showMatch(idx int) {
+	entry := s.editor.history.entries[idx]
+
+	s.searchIndex = idx
+	s.line = []rune(entry)
+	s.cursor = len([]rune(entry[:strings.Index(entry, string(s.query))]))
+}
+
+// completeWord completes the identifier before the cursor, with the common
+// prefix of the candidates when there are several of them. Candidates are
+// listed when the word can't be completed further.
+func (s *editState) completeWord() {
+	if s.editor.complete == nil {
+		return
+	}
+
+	start := s.wordStart(s.cursor, isIdentifierRune)
+	prefix := s.line[start:s.cursor]
+	if len(prefix) == 0 {
+		return
+	}
+
+	candidates := s.editor.complete(string(prefix))
+	if len(candidates) == 0 {
+		return
+	}
+
+	common := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, string(common)) {
+			common = common[:len(common)-1]
+		}
+	}
+
+	if len(common) > len(prefix) {
+		s.insert(common[len(prefix):]...)
+		return
+	}
+
+	if len(candidates) > 1 {
+		fmt.Fprintf(s.editor.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
+
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_'
+}
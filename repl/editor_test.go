@@ -0,0 +1,138 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineEditor(t *testing.T) {
+	tests := []struct {
+		name          string
+		history       []string
+		input         string
+		expectedLines []string
+	}{
+		{
+			name:          "plain lines",
+			input:         "1 + 2\rlen(\"ab\")\r",
+			expectedLines: []string{"1 + 2", `len("ab")`},
+		},
+		{
+			name:          "insert after moving the cursor",
+			input:         "1 2\x1b[D+ \x1b[D\x1b[D\x1b[C\r",
+			expectedLines: []string{"1 + 2"},
+		},
+		{
+			name:          "home, end and delete",
+			input:         "bc\x1b[Ha\x1b[Fd\x01\x1b[3~\r",
+			expectedLines: []string{"bcd"},
+		},
+		{
+			name:          "backspace and kill keys",
+			input:         "abc\x7f\x7fxy\x02\x0b\rfoo bar\x17baz\r12345\x02\x02\x15\r",
+			expectedLines: []string{"ax", "foo baz", "45"},
+		},
+		{
+			name:          "history recall",
+			history:       []string{"first", "second"},
+			input:         "draft\x1b[A\x1b[A\x1b[A\rnew\x1b[A\x1b[B\x1b[B\r",
+			expectedLines: []string{"first", "new"},
+		},
+		{
+			name:          "reverse search",
+			history:       []string{"sun_liyo_tau a ne_bana_diye 1;", "print(a)", "sun_liyo_tau b ne_bana_diye 2;"},
+			input:         "\x12sun\x12\r",
+			expectedLines: []string{"sun_liyo_tau a ne_bana_diye 1;"},
+		},
+		{
+			name:          "reverse search cancelled",
+			history:       []string{"print(a)"},
+			input:         "x\x12pr\x07y\r",
+			expectedLines: []string{"xy"},
+		},
+		{
+			name:          "reverse search ends on editing keys",
+			history:       []string{"print(a)"},
+			input:         "\x12pri\x1b[F;\r",
+			expectedLines: []string{"print(a);"},
+		},
+		{
+			name:          "tab completion",
+			input:         "sun_l\t x\rjab\t\rle\t(\"\")\r",
+			expectedLines: []string{"sun_liyo_tau x", "jab_tak", "len(\"\")"},
+		},
+		{
+			name:          "tab completion of the common prefix",
+			input:         "la\t\t\r",
+			expectedLines: []string{"la"},
+		},
+		{
+			name:          "interrupted input",
+			input:         "abc\x03def\r",
+			expectedLines: []string{"", "def"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &history{entries: tc.history}
+			complete := func(prefix string) []string {
+				var candidates []string
+				for _, name := range []string{"jab_tak", "laadle_ye_le", "last", "len", "sun_liyo_tau"} {
+					if strings.HasPrefix(name, prefix) {
+						candidates = append(candidates, name)
+					}
+				}
+				return candidates
+			}
+			editor := newLineEditor(strings.NewReader(tc.input), &bytes.Buffer{}, h, complete)
+
+			var lines []string
+			for {
+				line, err := editor.ReadLine(">> ")
+				if err == io.EOF {
+					break
+				}
+				if err != nil && err != errInterrupted {
+					t.Fatal(err)
+				}
+				lines = append(lines, line)
+			}
+
+			assert.Equal(t, tc.expectedLines, lines)
+		})
+	}
+}
+
+func TestLineEditorEndOfInput(t *testing.T) {
+	t.Parallel()
+
+	editor := newLineEditor(strings.NewReader("\x04"), &bytes.Buffer{}, &history{}, nil)
+	_, err := editor.ReadLine(">> ")
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestPipedInputIsSharedWithPrograms(t *testing.T) {
+	for _, engine := range []Engine{EngineEval, EngineVM} {
+		t.Run(string(engine), func(t *testing.T) {
+			t.Parallel()
+
+			stdin := bufio.NewReader(strings.NewReader("sun_liyo_tau naam ne_bana_diye input();\ntau\nnaam\n"))
+			var out bytes.Buffer
+			logger := log.New(&out, "", 0)
+			streams := newStreams(logger, stdin)
+			s := &session{engine: engine, streams: streams, exec: newExecutor(engine, "", nil, streams), logger: logger}
+
+			s.run(&pipeReader{in: stdin, out: io.Discard})
+			assert.Equal(t, "\ntau\nExiting REPL. Goodbye!\n", out.String())
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taulang/ast"
+	"taulang/compiler"
+	"taulang/diagnostic"
+	"taulang/evaluator"
+	"taulang/object"
+	"taulang/vm"
+)
+
+// Engine selects how programs are executed
+type Engine string
+
+const (
+	// EngineEval walks the AST, this is the default
+	EngineEval Engine = "eval"
+	// EngineVM compiles to bytecode and runs it on the virtual machine
+	EngineVM Engine = "vm"
+)
+
+// ParseEngine validates an engine name given on the command line
+func ParseEngine(name string) (Engine, error) {
+	switch engine := Engine(name); engine {
+	case EngineEval, EngineVM:
+		return engine, nil
+	default:
+		return "", fmt.Errorf("unknown engine %q, expected %q or %q", name, EngineEval, EngineVM)
+	}
+}
+
+// executor runs programs and keeps the global state between them, so that
+// REPL inputs can refer to earlier definitions
+type executor interface {
+	execute(program *ast.Program) object.Object
+	// environment returns the bindings made by the programs so far
+	environment() object.Environment
+}
+
+// newExecutor creates an executor for the file filename, which is used to
+// resolve imports and may be empty for input that doesn't come from a file.
+// The programs may only call the builtins the capabilities allow, and print
+// and read through the streams.
+func newExecutor(engine Engine, filename string, capabilities object.Capabilities, streams *object.Streams) executor {
+	if engine == EngineVM {
+		symbolTable := compiler.NewSymbolTable()
+		for idx, name := range evaluator.BuiltinNames() {
+			symbolTable.DefineBuiltin(idx, name)
+		}
+
+		context := vm.NewModuleContext(filename)
+		context.Capabilities = capabilities
+		context.Streams = streams
+
+		return &vmExecutor{
+			symbolTable: symbolTable,
+			constants:   []object.Object{},
+			globals:     make([]object.Object, vm.GlobalsSize),
+			context:     context,
+		}
+	}
+
+	context := evaluator.NewModuleContext(filename)
+	context.Capabilities = capabilities
+	context.Streams = streams
+	return &evalExecutor{env: object.NewModuleEnvironment(context)}
+}
+
+type evalExecutor struct {
+	env object.Environment
+}
+
+func (e *evalExecutor) execute(program *ast.Program) object.Object {
+	return evaluator.EvalContext(context.Background(), program, e.env)
+}
+
+func (e *evalExecutor) environment() object.Environment {
+	return e.env
+}
+
+type vmExecutor struct {
+	symbolTable *compiler.SymbolTable
+	constants   []object.Object
+	globals     []object.Object
+	context     *object.ModuleContext
+}
+
+func (e *vmExecutor) execute(program *ast.Program) object.Object {
+	c := compiler.NewCompilerWithState(e.symbolTable, e.constants)
+	if err := c.Compile(program); err != nil {
+		var d diagnostic.Diagnostic
+		if errors.As(err, &d) {
+			return &object.Error{Kind: object.SyntaxError, Message: d.Message, Position: d.Position}
+		}
+		return &object.Error{Kind: object.SyntaxError, Message: err.Error()}
+	}
+
+	bytecode := c.Bytecode()
+	e.constants = bytecode.Constants
+
+	return vm.NewVMWithState(bytecode, e.globals, e.context).RunContext(context.Background())
+}
+
+func (e *vmExecutor) environment() object.Environment {
+	return vm.NewGlobalsEnvironment(e.symbolTable.Names(), e.globals, e.context)
+}
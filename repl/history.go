@@ -0,0 +1,100 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is the number of lines kept in the history
+const maxHistory = 1000
+
+// historyFilename is the name of the history file in the home directory
+const historyFilename = ".taulang_history"
+
+// history holds the lines entered in the REPL, oldest first. When it has a
+// file, lines are appended to it so that they are recalled in the next session.
+type history struct {
+	entries  []string
+	filename string
+}
+
+// newHistory loads the history from filename, the history is only kept in
+// memory if filename is empty or can't be read
+func newHistory(filename string) *history {
+	h := &history{filename: filename}
+	if filename == "" {
+		return h
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return h
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+
+	// the file is compacted when it has grown past the limit
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+		_ = os.WriteFile(filename, []byte(strings.Join(h.entries, "\n")+"\n"), 0o600)
+	}
+
+	return h
+}
+
+// defaultHistoryFilename returns the history file in the home directory of
+// the user, or an empty string if there is none
+func defaultHistoryFilename() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, historyFilename)
+}
+
+// add appends the line to the history, blank lines and repetitions of the
+// last line are skipped
+func (h *history) add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+		return
+	}
+
+	h.entries = append(h.entries, line)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[1:]
+	}
+
+	if h.filename == "" {
+		return
+	}
+
+	file, err := os.OpenFile(h.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	_, _ = file.WriteString(line + "\n")
+}
+
+// search returns the index of the newest entry before index that contains
+// query, or -1
+func (h *history) search(query string, before int) int {
+	for idx := min(before, len(h.entries)) - 1; idx >= 0; idx-- {
+		if strings.Contains(h.entries[idx], query) {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,58 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryPersistsEntries(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), historyFilename)
+
+	h := newHistory(filename)
+	h.add("sun_liyo_tau x ne_bana_diye 1;")
+	h.add("x")
+	h.add("x")
+	h.add("   ")
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "sun_liyo_tau x ne_bana_diye 1;\nx\n", string(content))
+
+	assert.Equal(t, []string{"sun_liyo_tau x ne_bana_diye 1;", "x"}, newHistory(filename).entries)
+}
+
+func TestHistoryIsCompactedWhenLoaded(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), historyFilename)
+	lines := make([]string, maxHistory+10)
+	for idx := range lines {
+		lines[idx] = strings.Repeat("a", idx+1)
+	}
+	assert.NoError(t, os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+
+	h := newHistory(filename)
+	assert.Len(t, h.entries, maxHistory)
+	assert.Equal(t, lines[10], h.entries[0])
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, maxHistory, strings.Count(string(content), "\n"))
+}
+
+func TestHistorySearch(t *testing.T) {
+	t.Parallel()
+
+	h := &history{entries: []string{"len(a)", "print(a)", "len(b)"}}
+
+	assert.Equal(t, 2, h.search("len", 3))
+	assert.Equal(t, 0, h.search("len", 2))
+	assert.Equal(t, -1, h.search("len", 0))
+	assert.Equal(t, -1, h.search("push", 3))
+}
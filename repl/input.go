@@ -0,0 +1,35 @@
+package repl
+
+import (
+	"taulang/lexer"
+	"taulang/token"
+)
+
+// isIncomplete reports whether the input ends inside of a string or with
+// unclosed braces, brackets or parentheses, in which case the REPL reads more
+// lines before running it
+func isIncomplete(input string) bool {
+	l, err := lexer.NewLexer(input)
+	if err != nil {
+		return false
+	}
+
+	depth := 0
+	for {
+		tok := l.NextToken()
+		switch tok.Type {
+		case token.LEFT_BRACE, token.LEFT_BRACKET, token.LEFT_PAREN:
+			depth++
+		case token.RIGHT_BRACE, token.RIGHT_BRACKET, token.RIGHT_PAREN:
+			depth--
+		case token.ILLEGAL:
+			if tok.Literal == lexer.ErrUnterminatedString.Error() {
+				return true
+			}
+		case token.EOF:
+			// more closing than opening delimiters can't be fixed by reading
+			// more, the parser reports it
+			return depth > 0
+		}
+	}
+}
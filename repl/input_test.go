@@ -0,0 +1,69 @@
+package repl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "complete statement",
+			input:    "sun_liyo_tau x ne_bana_diye 1;",
+			expected: false,
+		},
+		{
+			name:     "empty input",
+			input:    "",
+			expected: false,
+		},
+		{
+			name:     "unclosed function body",
+			input:    "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(x) {",
+			expected: true,
+		},
+		{
+			name:     "unclosed nested delimiters",
+			input:    "jab_tak (saccha) {\n\tsun_liyo_tau a ne_bana_diye [1, 2,\n",
+			expected: true,
+		},
+		{
+			name:     "unclosed interpolated expression",
+			input:    "sun_liyo_tau s ne_bana_diye $\"{f(\n",
+			expected: true,
+		},
+		{
+			name:     "closed over several lines",
+			input:    "jab_tak (saccha) {\n\trok_diye;\n}",
+			expected: false,
+		},
+		{
+			name:     "unterminated string",
+			input:    `sun_liyo_tau s ne_bana_diye "abc`,
+			expected: true,
+		},
+		{
+			name:     "delimiters inside strings and comments",
+			input:    "\"{\" // (\n",
+			expected: false,
+		},
+		{
+			name:     "too many closing delimiters",
+			input:    "1 }",
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, isIncomplete(tc.input))
+		})
+	}
+}
@@ -2,9 +2,15 @@ package repl
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	goio "io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"taulang/ast"
+	"taulang/diagnostic"
 	"taulang/evaluator"
 	"taulang/io"
 	"taulang/lexer"
@@ -12,35 +18,145 @@ import (
 	"taulang/parser"
 )
 
-func StartREPL(logger *log.Logger) {
+// StartREPL reads and runs inputs until exit, the programs may only use the
+// builtins the capabilities allow
+func StartREPL(logger *log.Logger, engine Engine, capabilities object.Capabilities) {
 	logger.Println("Welcome to TauLang REPL!")
-	logger.Println("Type 'exit' to quit.")
+	logger.Println("Type 'exit' to quit or ':help' for the REPL commands.")
 	logger.Println("")
 
-	scanner := bufio.NewScanner(os.Stdin)
-	env := object.NewEnvironment()
+	// the REPL and the programs read stdin through the same buffer
+	stdin := bufio.NewReader(os.Stdin)
+	streams := newStreams(logger, stdin)
+	s := &session{
+		engine:       engine,
+		capabilities: capabilities,
+		streams:      streams,
+		exec:         newExecutor(engine, "", capabilities, streams),
+		logger:       logger,
+	}
+	s.run(newLineReader(s, stdin))
+}
+
+// run reads and runs inputs from the reader until exit
+func (s *session) run(reader lineReader) {
+	logger := s.logger
+
+	// input collects the lines of a statement that spans several lines
+	var input strings.Builder
 	for {
-		fmt.Print(">> ")
-		if scanner.Scan() {
-			input := scanner.Text()
-			if input == "exit" {
+		currentPrompt := prompt
+		if input.Len() != 0 {
+			currentPrompt = continuationPrompt
+		}
+
+		line, err := reader.ReadLine(currentPrompt)
+		if errors.Is(err, errInterrupted) {
+			input.Reset()
+			continue
+		}
+		if errors.Is(err, goio.EOF) {
+			logger.Println("Exiting REPL. Goodbye!")
+			break
+		}
+		if err != nil {
+			io.OutputFatalErrorAndExit(logger, err)
+		}
+
+		if input.Len() == 0 && line == "exit" {
+			logger.Println("Exiting REPL. Goodbye!")
+			break
+		}
+
+		if input.Len() == 0 && isCommand(line) {
+			if quit := s.runCommand(line); quit {
 				logger.Println("Exiting REPL. Goodbye!")
 				break
 			}
-			executeInputWithEnvironment(input, logger, env)
+			continue
+		}
+
+		// an empty line runs incomplete input anyway, to get out of a typo
+		if input.Len() != 0 && strings.TrimSpace(line) == "" {
+			executeInputWithExecutor(replFilename, input.String(), logger, s.exec)
+			input.Reset()
+			continue
+		}
+
+		input.WriteString(line)
+		input.WriteString("\n")
+		if isIncomplete(input.String()) {
+			continue
 		}
+
+		executeInputWithExecutor(replFilename, input.String(), logger, s.exec)
+		input.Reset()
 	}
-	if err := scanner.Err(); err != nil {
-		io.OutputFatalErrorAndExit(logger, err)
+}
+
+// newLineReader uses the line editor when stdin is a terminal
+func newLineReader(s *session, stdin *bufio.Reader) lineReader {
+	fd := int(os.Stdin.Fd())
+	if !isTerminal(fd) {
+		return &pipeReader{in: stdin, out: os.Stdout}
 	}
+
+	editor := newLineEditor(stdin, os.Stdout, newHistory(defaultHistoryFilename()), func(prefix string) []string {
+		return completions(prefix, s.exec.environment())
+	})
+	return &terminalReader{fd: fd, editor: editor}
 }
 
-func ExecuteInput(input string, logger *log.Logger) {
-	env := object.NewEnvironment()
-	executeInputWithEnvironment(input, logger, env)
+// terminalReader switches the terminal to raw mode while a line is edited, the
+// output of programs is written in the normal mode
+type terminalReader struct {
+	fd     int
+	editor *lineEditor
 }
 
-func executeInputWithEnvironment(input string, logger *log.Logger, env object.Environment) {
+func (t *terminalReader) ReadLine(prompt string) (string, error) {
+	restore, err := enableRawMode(t.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	return t.editor.ReadLine(prompt)
+}
+
+const (
+	prompt = ">> "
+	// continuationPrompt is shown while a statement spans several lines
+	continuationPrompt = ".. "
+)
+
+// replFilename is used in place of a file name when reporting errors for REPL input
+const replFilename = "<repl>"
+
+// ExecuteInput runs the input in a fresh environment with the given engine,
+// filename is only used for reporting errors
+func ExecuteInput(filename string, input string, logger *log.Logger, engine Engine, capabilities object.Capabilities) {
+	executeInputWithExecutor(filename, input, logger, newExecutor(engine, filename, capabilities, newStreams(logger, os.Stdin)))
+}
+
+// newStreams makes programs print where the logger writes, so that their
+// output is interleaved with the results in order
+func newStreams(logger *log.Logger, stdin goio.Reader) *object.Streams {
+	return object.NewStreams(logger.Writer(), os.Stderr, stdin)
+}
+
+func executeInputWithExecutor(filename string, input string, logger *log.Logger, exec executor) {
+	program, ok := parseInput(filename, input, logger)
+	if !ok {
+		return
+	}
+
+	printOutput(filename, input, logger, exec.execute(program))
+}
+
+// parseInput parses the input and reports the parse errors, it reports false
+// if there were any as the AST may be incomplete then
+func parseInput(filename string, input string, logger *log.Logger) (*ast.Program, bool) {
 	l, err := lexer.NewLexer(input)
 	if err != nil {
 		io.OutputFatalErrorAndExit(logger, err)
@@ -48,21 +164,98 @@ func executeInputWithEnvironment(input string, logger *log.Logger, env object.En
 	p := parser.NewParser(l)
 
 	program := p.Parse()
-	errors := p.Errors()
+	errors := p.Diagnostics()
 
-	if errors != nil && len(errors) != 0 {
+	if len(errors) != 0 {
 		logger.Println("encountered errors while parsing: ")
 		for _, e := range errors {
-			logger.Println(e)
+			logger.Println(diagnostic.Render(input, filename, e))
 		}
 		logger.Print("\n\n")
+		return nil, false
 	}
 
-	output := evaluator.Eval(program, env)
+	return program, true
+}
 
-	if output == evaluator.NULL {
-		logger.Println("")
-	} else {
-		logger.Println(output.Inspect())
+func printOutput(filename string, input string, logger *log.Logger, output object.Object) {
+	switch output := output.(type) {
+	case *object.Error:
+		logger.Println(renderError(input, filename, output))
+	default:
+		if output == evaluator.NULL {
+			logger.Println("")
+		} else {
+			logger.Println(output.Inspect())
+		}
 	}
 }
+
+// renderError formats a runtime error like a diagnostic, preceded by the stack
+// trace when it happened inside of a function, e.g.
+//
+//	Traceback (most recent call last):
+//	  main.tau:5:1 in <main>
+//	  main.tau:2:5 in add
+//	main.tau:2:5: TypeError: type mismatch: INTEGER + STRING
+//	   2 |   x + "a"
+//	     |     ^
+func renderError(source string, filename string, err *object.Error) string {
+	var out strings.Builder
+
+	// The outermost frame is always the input itself, frames of other files
+	// are reported by their name
+	frameFilename := func(frame object.StackFrame) string {
+		if frame.Filename == "" || frame.Filename == err.Stack[len(err.Stack)-1].Filename {
+			return filename
+		}
+		return filepath.Base(frame.Filename)
+	}
+
+	if len(err.Stack) != 0 {
+		out.WriteString("Traceback (most recent call last):\n")
+
+		// runs of the same frame, e.g. of deep recursion, are shown once
+		var previous string
+		repeated := 0
+		reportRepeated := func() {
+			if repeated > 0 {
+				fmt.Fprintf(&out, "  [previous line repeated %d more times]\n", repeated)
+			}
+			repeated = 0
+		}
+
+		for idx := len(err.Stack) - 1; idx >= 0; idx-- {
+			frame := err.Stack[idx]
+
+			function := frame.Function
+			if idx == len(err.Stack)-1 {
+				function = "<main>"
+			} else if function == "" {
+				function = "<anonymous>"
+			}
+
+			line := fmt.Sprintf("  %s:%s in %s\n", frameFilename(frame), frame.Position, function)
+			if line == previous {
+				repeated++
+				continue
+			}
+			reportRepeated()
+			out.WriteString(line)
+			previous = line
+		}
+		reportRepeated()
+
+		// The source of other files isn't at hand, so their line is not shown
+		if innermost := err.Stack[0]; frameFilename(innermost) != filename {
+			source, filename = "", frameFilename(innermost)
+		}
+	}
+
+	out.WriteString(diagnostic.Render(source, filename, diagnostic.Diagnostic{
+		Position: err.Position,
+		Message:  err.Inspect(),
+	}))
+
+	return out.String()
+}
@@ -0,0 +1,33 @@
+package repl
+
+import (
+	"taulang/object"
+	"taulang/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderErrorCollapsesRepeatedFrames(t *testing.T) {
+	t.Parallel()
+
+	source := "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(n) { f(n + 1) };\nf(0);"
+	recursion := object.StackFrame{Function: "f", Position: token.Position{Line: 1, Column: 48, Offset: 47}}
+
+	stack := []object.StackFrame{{Function: "f", Position: recursion.Position}}
+	for range 5 {
+		stack = append(stack, recursion)
+	}
+	stack = append(stack, object.StackFrame{Position: token.Position{Line: 2, Column: 2, Offset: 61}})
+
+	rendered := renderError(source, "main.tau", &object.Error{
+		Kind:     object.StackOverflowError,
+		Message:  "stack overflow: maximum call depth of 6 exceeded",
+		Position: recursion.Position,
+		Stack:    stack,
+	})
+	assert.Contains(t, rendered, "Traceback (most recent call last):\n"+
+		"  main.tau:2:2 in <main>\n"+
+		"  main.tau:1:48 in f\n"+
+		"  [previous line repeated 5 more times]\n")
+}
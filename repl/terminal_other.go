@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package repl
+
+import "errors"
+
+// isTerminal is always false where raw mode is not supported, the REPL then
+// reads plain lines
+func isTerminal(fd int) bool {
+	return false
+}
+
+func enableRawMode(fd int) (func(), error) {
+	return nil, errors.New("raw mode is not supported on this platform")
+}
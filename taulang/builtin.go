@@ -0,0 +1,211 @@
+package taulang
+
+import (
+	"fmt"
+	"reflect"
+	"taulang/evaluator"
+	"taulang/object"
+)
+
+var (
+	errorType          = reflect.TypeFor[error]()
+	builtinContextType = reflect.TypeFor[object.BuiltinContext]()
+)
+
+// RegisterBuiltin makes fn callable as name by the programs of the interpreter,
+// fn is wrapped with WrapFunc. The name must not be taken by a core builtin.
+// Host builtins can always be called unless fn is an *object.Builtin with a
+// Capability.
+func (i *Interpreter) RegisterBuiltin(name string, fn any) error {
+	builtin, err := WrapFunc(name, fn)
+	if err != nil {
+		return err
+	}
+
+	return i.builtins.Register(name, builtin)
+}
+
+// RegisterNamespace makes the functions callable as members of the namespace,
+// e.g. `geo.distance(a, b)`, so that their names can't collide with the core
+// builtins. The functions are wrapped with WrapFunc.
+func (i *Interpreter) RegisterNamespace(namespace string, functions map[string]any) error {
+	builtins := make(map[string]*object.Builtin, len(functions))
+	for name, fn := range functions {
+		builtin, err := WrapFunc(namespace+"."+name, fn)
+		if err != nil {
+			return err
+		}
+		builtins[name] = builtin
+	}
+
+	return i.builtins.RegisterNamespace(namespace, builtins)
+}
+
+// WrapFunc turns a Go function into a builtin called name. Builtins and
+// object.BuiltinFunction values are used as they are, other functions are
+// called through reflection:
+//
+//   - the arguments are converted to the parameter types with Decode, calls
+//     with the wrong number or types of arguments fail like the ones of core
+//     builtins
+//   - a first parameter of type object.BuiltinContext receives the context of
+//     the call and isn't counted as an argument
+//   - variadic functions take any number of further arguments
+//   - the function returns nothing, a value, an error, or a value and an error.
+//     Values are converted with ToObject, errors are raised as TauLang errors
+//     that programs can catch.
+//   - a panic of the function is raised as an InternalError
+func WrapFunc(name string, fn any) (*object.Builtin, error) {
+	switch fn := fn.(type) {
+	case *object.Builtin:
+		builtin := *fn
+		if builtin.Name == "" {
+			builtin.Name = name
+		}
+		return &builtin, nil
+	case object.BuiltinFunction:
+		return &object.Builtin{Fn: fn, Name: name}, nil
+	case func(object.BuiltinContext, ...object.Object) object.Object:
+		return &object.Builtin{Fn: fn, Name: name}, nil
+	}
+
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return nil, fmt.Errorf("builtin %s must be a function, got %T", name, fn)
+	}
+
+	fnType := value.Type()
+	if err := checkResults(fnType); err != nil {
+		return nil, fmt.Errorf("builtin %s: %w", name, err)
+	}
+
+	withContext := fnType.NumIn() > 0 && fnType.In(0) == builtinContextType
+	params := make([]reflect.Type, 0, fnType.NumIn())
+	for idx := range fnType.NumIn() {
+		if idx > 0 || !withContext {
+			params = append(params, fnType.In(idx))
+		}
+	}
+
+	// the types are checked while decoding, where e.g. an INTEGER can be
+	// passed for a float parameter
+	spec := evaluator.ArgumentSpec{Name: name}
+	if fnType.IsVariadic() {
+		spec.Rest = evaluator.AnyType
+		params[len(params)-1] = params[len(params)-1].Elem()
+		spec.Required = anyTypes(len(params) - 1)
+	} else {
+		spec.Required = anyTypes(len(params))
+	}
+
+	return &object.Builtin{
+		Name: name,
+		Fn: func(ctx object.BuiltinContext, args ...object.Object) (result object.Object) {
+			// a panicking host function fails the call instead of the host
+			defer func() {
+				if r := recover(); r != nil {
+					result = &object.Error{Kind: object.InternalError, Message: fmt.Sprintf("`%s` panicked: %v", name, r)}
+				}
+			}()
+
+			if err := spec.Check(args); err != nil {
+				return err
+			}
+
+			in := make([]reflect.Value, 0, len(args)+1)
+			if withContext {
+				in = append(in, reflect.ValueOf(&ctx).Elem())
+			}
+			for idx, arg := range args {
+				param := params[min(idx, len(params)-1)]
+				argValue := reflect.New(param).Elem()
+				if err := decode(arg, argValue); err != nil {
+					return argumentError(name, idx, arg, param, err)
+				}
+				in = append(in, argValue)
+			}
+
+			return resultObject(name, value.Call(in))
+		},
+	}, nil
+}
+
+// checkResults checks that a function returns nothing, a value, an error, or a
+// value and an error
+func checkResults(fnType reflect.Type) error {
+	switch {
+	case fnType.NumOut() > 2:
+		return fmt.Errorf("must return at most a value and an error, returns %d values", fnType.NumOut())
+	case fnType.NumOut() == 2 && fnType.Out(1) != errorType:
+		return fmt.Errorf("second result must be an error, got %s", fnType.Out(1))
+	default:
+		return nil
+	}
+}
+
+func resultObject(name string, results []reflect.Value) object.Object {
+	if len(results) != 0 {
+		last := results[len(results)-1]
+		if last.Type() == errorType {
+			if !last.IsNil() {
+				return &object.Error{Kind: object.ThrownError, Message: last.Interface().(error).Error()}
+			}
+			results = results[:len(results)-1]
+		}
+	}
+
+	if len(results) == 0 {
+		return evaluator.NULL
+	}
+
+	obj, err := toObject(results[0])
+	if err != nil {
+		return &object.Error{Kind: object.TypeError, Message: fmt.Sprintf("result of `%s`: %s", name, err)}
+	}
+	return obj
+}
+
+func argumentError(name string, idx int, arg object.Object, param reflect.Type, err error) *object.Error {
+	if want := tauType(param); want != evaluator.AnyType && want != arg.Type() {
+		return &object.Error{
+			Kind:    object.TypeError,
+			Message: fmt.Sprintf("argument %d to `%s` must be %s, got %s", idx+1, name, want, arg.Type()),
+		}
+	}
+
+	return &object.Error{Kind: object.TypeError, Message: fmt.Sprintf("argument %d to `%s`: %s", idx+1, name, err)}
+}
+
+// tauType returns the type of the objects that are decoded into values of the
+// Go type, it is evaluator.AnyType for types that take objects of any type
+func tauType(goType reflect.Type) object.Type {
+	if goType == bigIntType {
+		return object.INTEGER_OBJ
+	}
+
+	switch goType.Kind() {
+	case reflect.Bool:
+		return object.BOOLEAN_OBJ
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.INTEGER_OBJ
+	case reflect.Float32, reflect.Float64:
+		return object.FLOAT_OBJ
+	case reflect.String:
+		return object.STRING_OBJ
+	case reflect.Slice, reflect.Array:
+		return object.ARRAY_OBJ
+	case reflect.Map, reflect.Struct:
+		return object.HASHMAP_OBJ
+	default:
+		return evaluator.AnyType
+	}
+}
+
+func anyTypes(count int) []object.Type {
+	types := make([]object.Type, count)
+	for idx := range types {
+		types[idx] = evaluator.AnyType
+	}
+	return types
+}
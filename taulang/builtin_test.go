@@ -0,0 +1,125 @@
+package taulang_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"taulang/object"
+	"taulang/taulang"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X float64 `tau:"x"`
+	Y float64 `tau:"y"`
+}
+
+func newHostInterpreter(t *testing.T) *taulang.Interpreter {
+	interpreter := taulang.NewInterpreter()
+
+	assert.NoError(t, interpreter.RegisterBuiltin("shout", strings.ToUpper))
+	assert.NoError(t, interpreter.RegisterBuiltin("sum", func(first int, rest ...int) int {
+		for _, n := range rest {
+			first += n
+		}
+		return first
+	}))
+	assert.NoError(t, interpreter.RegisterBuiltin("half", func(n float64) float64 { return n / 2 }))
+	assert.NoError(t, interpreter.RegisterBuiltin("head", func(values []int) int { return values[0] }))
+	assert.NoError(t, interpreter.RegisterBuiltin("lookup", func(key string) (string, error) {
+		if key == "" {
+			return "", errors.New("empty key")
+		}
+		return "value of " + key, nil
+	}))
+	assert.NoError(t, interpreter.RegisterBuiltin("apply_twice", func(ctx object.BuiltinContext, fn object.Object, arg object.Object) object.Object {
+		return ctx.Call(fn, ctx.Call(fn, arg))
+	}))
+	assert.NoError(t, interpreter.RegisterBuiltin("raw", func(_ object.BuiltinContext, args ...object.Object) object.Object {
+		return &object.Integer{Value: int64(len(args))}
+	}))
+	assert.NoError(t, interpreter.RegisterNamespace("geo", map[string]any{
+		"midpoint": func(a point, b point) point {
+			return point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+		},
+		// members can share names with core builtins
+		"len": func(p point) float64 { return p.X + p.Y },
+	}))
+
+	return interpreter
+}
+
+func TestRegisteredBuiltins(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected any
+	}{
+		{name: "wrapped function", input: `shout("tau")`, expected: "TAU"},
+		{name: "variadic function", input: `sum(1, 2, 3)`, expected: int64(6)},
+		{name: "integers are passed for floats", input: `half(5)`, expected: 2.5},
+		{name: "result with a nil error", input: `lookup("a")`, expected: "value of a"},
+		{name: "error is raised", input: `koshish_karo { lookup("") } pakad_lo (e) { e.message }`, expected: "empty key"},
+		{name: "panic is raised", input: `koshish_karo { head([]) } pakad_lo (e) { e.kind }`, expected: "InternalError"},
+		{name: "builtin context", input: `apply_twice(tau_ka_jugaad(x) { x * 3 }, 2)`, expected: int64(18)},
+		{name: "builtin function", input: `raw(1, 2)`, expected: int64(2)},
+		{name: "namespace", input: `geo.midpoint({"x": 0, "y": 0}, {"x": 2, "y": 4})`, expected: map[string]any{"x": 1.0, "y": 2.0}},
+		{name: "namespace member named like a core builtin", input: `[geo.len({"x": 1, "y": 2}), len("ab")]`, expected: []any{3.0, int64(2)}},
+	}
+
+	interpreter := newHostInterpreter(t)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := interpreter.Eval("", tc.input, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestRegisteredBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "too few arguments", input: `shout()`, expected: "ArgumentError: wrong number of arguments. got=0, want=1"},
+		{name: "too few arguments of a variadic function", input: `sum()`, expected: "ArgumentError: wrong number of arguments. got=0, want at least 1"},
+		{name: "wrong type", input: `shout(1)`, expected: "TypeError: argument 1 to `shout` must be STRING, got INTEGER"},
+		{name: "wrong type of a variadic argument", input: `sum(1, "2")`, expected: "TypeError: argument 2 to `sum` must be INTEGER, got STRING"},
+		{name: "wrong type inside a hash map", input: `geo.len({"x": "1"})`, expected: "TypeError: argument 1 to `geo.len`: x: cannot decode STRING into float64"},
+		{name: "unknown namespace member", input: `geo.area()`, expected: "NameError: module geo has no member: area"},
+		{name: "panic", input: `head([])`, expected: "InternalError: `head` panicked: runtime error: index out of range [0] with length 0"},
+	}
+
+	interpreter := newHostInterpreter(t)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := interpreter.Eval("", tc.input, nil)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestRegisterBuiltinErrors(t *testing.T) {
+	t.Parallel()
+
+	interpreter := taulang.NewInterpreter()
+	assert.EqualError(t, interpreter.RegisterBuiltin("len", strings.ToUpper), "len is already a core builtin")
+	assert.EqualError(t, interpreter.RegisterBuiltin("upper", 42), "builtin upper must be a function, got int")
+	assert.EqualError(t, interpreter.RegisterBuiltin("parse", func() (int, int) { return 0, 0 }),
+		"builtin parse: second result must be an error, got int")
+
+	assert.NoError(t, interpreter.RegisterNamespace("geo", map[string]any{"origin": func() int { return 0 }}))
+	assert.EqualError(t, interpreter.RegisterBuiltin("geo", fmt.Sprint), "geo is already registered")
+
+	// builtins are registered per interpreter
+	_, err := taulang.NewInterpreter().Eval("", "geo.origin()", nil)
+	assert.ErrorContains(t, err, "identifier not found: geo")
+}
@@ -0,0 +1,558 @@
+package taulang
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+	"taulang/evaluator"
+	"taulang/object"
+)
+
+var (
+	objectType = reflect.TypeFor[object.Object]()
+	bigIntType = reflect.TypeFor[big.Int]()
+)
+
+// ToObject converts a Go value to a TauLang object. Numbers, strings and
+// booleans become the matching objects, slices and arrays become arrays, maps
+// and structs become hash maps and nil becomes null. Struct fields are named
+// by their `tau` tag, e.g.
+//
+//	type Order struct {
+//		ID    int     `tau:"id"`
+//		Total float64 `tau:"total,omitempty"`
+//		Notes string  `tau:"-"`
+//	}
+//
+// Values that are already objects are returned as they are.
+func ToObject(value any) (object.Object, error) {
+	if value == nil {
+		return evaluator.NULL, nil
+	}
+
+	return toObject(reflect.ValueOf(value))
+}
+
+func toObject(value reflect.Value) (object.Object, error) {
+	return visiting{}.toObject(value)
+}
+
+func (v visiting) toObject(value reflect.Value) (object.Object, error) {
+	// only pointers, slices and maps can lead back to the value itself
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if !value.IsNil() {
+			key := goReference{pointer: value.Pointer(), typ: value.Type()}
+			if value.Kind() == reflect.Slice {
+				key.len = value.Len()
+			}
+			if !v.enter(key) {
+				return nil, fmt.Errorf("cannot convert %s that contains itself", value.Type())
+			}
+			defer v.leave(key)
+		}
+	}
+
+	if value.Type().Implements(objectType) {
+		if value.Kind() == reflect.Pointer && value.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return value.Interface().(object.Object), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return evaluator.TRUE, nil
+		}
+		return evaluator.FALSE, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: value.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt64 {
+			return &object.BigInteger{Value: new(big.Int).SetUint64(value.Uint())}, nil
+		}
+		return &object.Integer{Value: int64(value.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: value.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: value.String()}, nil
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return v.toObject(value.Elem())
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return evaluator.NULL, nil
+		}
+
+		elements := make([]object.Object, value.Len())
+		for idx := range value.Len() {
+			element, err := v.toObject(value.Index(idx))
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", idx, err)
+			}
+			elements[idx] = element
+		}
+		return &object.Array{Elements: elements}, nil
+	case reflect.Map:
+		if value.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return v.mapToObject(value)
+	case reflect.Struct:
+		if value.Type() == bigIntType {
+			integer := value.Interface().(big.Int)
+			if integer.IsInt64() {
+				return &object.Integer{Value: integer.Int64()}, nil
+			}
+			return &object.BigInteger{Value: new(big.Int).Set(&integer)}, nil
+		}
+		return v.structToObject(value)
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a TauLang value", value.Type())
+	}
+}
+
+func (v visiting) mapToObject(value reflect.Value) (object.Object, error) {
+	hashMap := object.NewHashMap()
+
+	// Go maps are unordered, sorting the keys keeps the order of the hash map
+	// the same between runs
+	pairs := make([]object.HashPair, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		objectKey, err := v.toObject(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %v: %w", key, err)
+		}
+		if _, ok := objectKey.(object.Hashable); !ok {
+			return nil, fmt.Errorf("key %v: %s can't be used as a hash map key", key, objectKey.Type())
+		}
+
+		element, err := v.toObject(value.MapIndex(key))
+		if err != nil {
+			return nil, fmt.Errorf("[%v]: %w", key, err)
+		}
+		pairs = append(pairs, object.HashPair{Key: objectKey, Value: element})
+	}
+	slices.SortFunc(pairs, func(a object.HashPair, b object.HashPair) int {
+		return strings.Compare(a.Key.Inspect(), b.Key.Inspect())
+	})
+
+	for _, pair := range pairs {
+		hashMap.Set(pair.Key.(object.Hashable).Hash(), pair)
+	}
+
+	return hashMap, nil
+}
+
+func (v visiting) structToObject(value reflect.Value) (object.Object, error) {
+	hashMap := object.NewHashMap()
+
+	for _, field := range structFields(value.Type()) {
+		// fields promoted through a nil embedded pointer don't exist
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil {
+			continue
+		}
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		element, err := v.toObject(fieldValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.name, err)
+		}
+
+		key := &object.String{Value: field.name}
+		hashMap.Set(key.Hash(), object.HashPair{Key: key, Value: element})
+	}
+
+	return hashMap, nil
+}
+
+// FromObject converts a TauLang object to a Go value. Integers become int64,
+// or *big.Int when they don't fit, floats become float64, arrays become []any
+// and null becomes nil. Hash maps become map[string]any when all their keys
+// are strings and map[any]any otherwise. Functions, builtins and modules can't
+// be converted.
+func FromObject(obj object.Object) (any, error) {
+	return visiting{}.fromObject(obj)
+}
+
+func (v visiting) fromObject(obj object.Object) (any, error) {
+	switch obj.(type) {
+	case *object.Array, *object.HashMap:
+		if !v.enter(obj) {
+			return nil, fmt.Errorf("cannot convert %s that contains itself", obj.Type())
+		}
+		defer v.leave(obj)
+	}
+
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.BigInteger:
+		return new(big.Int).Set(obj.Value), nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		elements := make([]any, len(obj.Elements))
+		for idx, element := range obj.Elements {
+			value, err := v.fromObject(element)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", idx, err)
+			}
+			elements[idx] = value
+		}
+		return elements, nil
+	case *object.HashMap:
+		return v.hashMapFromObject(obj)
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a Go value", obj.Type())
+	}
+}
+
+func (v visiting) hashMapFromObject(hashMap *object.HashMap) (any, error) {
+	pairs := hashMap.Ordered()
+
+	stringKeys := true
+	for _, pair := range pairs {
+		if _, ok := pair.Key.(*object.String); !ok {
+			stringKeys = false
+			break
+		}
+	}
+
+	if stringKeys {
+		values := make(map[string]any, len(pairs))
+		for _, pair := range pairs {
+			key := pair.Key.(*object.String).Value
+			value, err := v.fromObject(pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("[%q]: %w", key, err)
+			}
+			values[key] = value
+		}
+		return values, nil
+	}
+
+	values := make(map[any]any, len(pairs))
+	for _, pair := range pairs {
+		key, err := v.fromObject(pair.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+		}
+		value, err := v.fromObject(pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("[%s]: %w", pair.Key.Inspect(), err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Decode converts a TauLang object into the Go value target points to, hash
+// maps can be decoded into structs using the same `tau` tags as ToObject. Keys
+// of the hash map without a matching field are ignored.
+func Decode(obj object.Object, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", target)
+	}
+
+	return decode(obj, value.Elem())
+}
+
+func decode(obj object.Object, target reflect.Value) error {
+	return visiting{}.decode(obj, target)
+}
+
+func (v visiting) decode(obj object.Object, target reflect.Value) error {
+	if target.Type() == objectType {
+		target.Set(reflect.ValueOf(obj))
+		return nil
+	}
+
+	if obj.Type() == object.NULL_OBJ {
+		switch target.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			target.SetZero()
+			return nil
+		}
+	}
+
+	if target.Type() == bigIntType {
+		switch obj := obj.(type) {
+		case *object.Integer:
+			target.Set(reflect.ValueOf(big.NewInt(obj.Value)).Elem())
+			return nil
+		case *object.BigInteger:
+			target.Set(reflect.ValueOf(new(big.Int).Set(obj.Value)).Elem())
+			return nil
+		}
+		return decodeError(obj, target)
+	}
+
+	switch target.Kind() {
+	case reflect.Interface:
+		if target.NumMethod() != 0 {
+			return decodeError(obj, target)
+		}
+		value, err := v.fromObject(obj)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			target.Set(reflect.ValueOf(value))
+		} else {
+			target.SetZero()
+		}
+		return nil
+	case reflect.Pointer:
+		value := reflect.New(target.Type().Elem())
+		if err := v.decode(obj, value.Elem()); err != nil {
+			return err
+		}
+		target.Set(value)
+		return nil
+	case reflect.Bool:
+		boolean, ok := obj.(*object.Boolean)
+		if !ok {
+			return decodeError(obj, target)
+		}
+		target.SetBool(boolean.Value)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return decodeError(obj, target)
+		}
+		if target.OverflowInt(integer.Value) {
+			return fmt.Errorf("%d overflows %s", integer.Value, target.Type())
+		}
+		target.SetInt(integer.Value)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		integer, err := decodeUint(obj, target)
+		if err != nil {
+			return err
+		}
+		target.SetUint(integer)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch obj := obj.(type) {
+		case *object.Float:
+			target.SetFloat(obj.Value)
+		case *object.Integer:
+			target.SetFloat(float64(obj.Value))
+		default:
+			return decodeError(obj, target)
+		}
+		return nil
+	case reflect.String:
+		str, ok := obj.(*object.String)
+		if !ok {
+			return decodeError(obj, target)
+		}
+		target.SetString(str.Value)
+		return nil
+	case reflect.Slice, reflect.Array:
+		return v.decodeArray(obj, target)
+	case reflect.Map:
+		return v.decodeMap(obj, target)
+	case reflect.Struct:
+		return v.decodeStruct(obj, target)
+	default:
+		return decodeError(obj, target)
+	}
+}
+
+func decodeUint(obj object.Object, target reflect.Value) (uint64, error) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		if obj.Value < 0 || target.OverflowUint(uint64(obj.Value)) {
+			return 0, fmt.Errorf("%d overflows %s", obj.Value, target.Type())
+		}
+		return uint64(obj.Value), nil
+	case *object.BigInteger:
+		if !obj.Value.IsUint64() || target.OverflowUint(obj.Value.Uint64()) {
+			return 0, fmt.Errorf("%s overflows %s", obj.Value, target.Type())
+		}
+		return obj.Value.Uint64(), nil
+	default:
+		return 0, decodeError(obj, target)
+	}
+}
+
+func (v visiting) decodeArray(obj object.Object, target reflect.Value) error {
+	array, ok := obj.(*object.Array)
+	if !ok {
+		return decodeError(obj, target)
+	}
+
+	if !v.enter(obj) {
+		return fmt.Errorf("cannot decode %s that contains itself", obj.Type())
+	}
+	defer v.leave(obj)
+
+	if target.Kind() == reflect.Array {
+		if target.Len() != len(array.Elements) {
+			return fmt.Errorf("cannot decode ARRAY of length %d into %s", len(array.Elements), target.Type())
+		}
+	} else {
+		target.Set(reflect.MakeSlice(target.Type(), len(array.Elements), len(array.Elements)))
+	}
+
+	for idx, element := range array.Elements {
+		if err := v.decode(element, target.Index(idx)); err != nil {
+			return fmt.Errorf("[%d]: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+func (v visiting) decodeMap(obj object.Object, target reflect.Value) error {
+	hashMap, ok := obj.(*object.HashMap)
+	if !ok {
+		return decodeError(obj, target)
+	}
+
+	if !v.enter(obj) {
+		return fmt.Errorf("cannot decode %s that contains itself", obj.Type())
+	}
+	defer v.leave(obj)
+
+	values := reflect.MakeMapWithSize(target.Type(), hashMap.Len())
+	for _, pair := range hashMap.Ordered() {
+		key := reflect.New(target.Type().Key()).Elem()
+		if err := v.decode(pair.Key, key); err != nil {
+			return fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+		}
+
+		value := reflect.New(target.Type().Elem()).Elem()
+		if err := v.decode(pair.Value, value); err != nil {
+			return fmt.Errorf("[%s]: %w", pair.Key.Inspect(), err)
+		}
+
+		values.SetMapIndex(key, value)
+	}
+
+	target.Set(values)
+	return nil
+}
+
+func (v visiting) decodeStruct(obj object.Object, target reflect.Value) error {
+	hashMap, ok := obj.(*object.HashMap)
+	if !ok {
+		return decodeError(obj, target)
+	}
+
+	if !v.enter(obj) {
+		return fmt.Errorf("cannot decode %s that contains itself", obj.Type())
+	}
+	defer v.leave(obj)
+
+	for _, field := range structFields(target.Type()) {
+		key := &object.String{Value: field.name}
+		pair, ok := hashMap.Get(key.Hash())
+		if !ok {
+			continue
+		}
+
+		fieldValue, ok := fieldByIndex(target, field.index)
+		if !ok {
+			continue
+		}
+		if err := v.decode(pair.Value, fieldValue); err != nil {
+			return fmt.Errorf("%s: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndex is FieldByIndex for decoding, nil embedded pointers on the way
+// to a promoted field are allocated. It reports false for fields promoted
+// through unexported embedded pointers, which can't be allocated.
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for idx, fieldIndex := range index {
+		if idx > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !value.CanSet() {
+					return reflect.Value{}, false
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value, true
+}
+
+func decodeError(obj object.Object, target reflect.Value) error {
+	return fmt.Errorf("cannot decode %s into %s", obj.Type(), target.Type())
+}
+
+// visiting holds the values being converted, a value that is reached again
+// while converting its elements contains itself and can't be converted
+type visiting map[any]bool
+
+// goReference identifies a Go pointer, slice or map, the type tells a struct
+// from its first field and the length a slice from its prefixes
+type goReference struct {
+	pointer uintptr
+	typ     reflect.Type
+	len     int
+}
+
+// enter marks the value as being converted, it reports false if it already is
+func (v visiting) enter(key any) bool {
+	if v[key] {
+		return false
+	}
+	v[key] = true
+	return true
+}
+
+func (v visiting) leave(key any) {
+	delete(v, key)
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields returns the exported fields of the struct type with the names
+// given by their `tau` tags, fields tagged with "-" are left out
+func structFields(structType reflect.Type) []structField {
+	var fields []structField
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("tau"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, structField{name: name, index: field.Index, omitEmpty: options == "omitempty"})
+	}
+	return fields
+}
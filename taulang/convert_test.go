@@ -0,0 +1,198 @@
+package taulang_test
+
+import (
+	"math"
+	"math/big"
+	"taulang/object"
+	"taulang/taulang"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `tau:"city"`
+}
+
+type customer struct {
+	Name     string            `tau:"name"`
+	Age      int               `tau:"age,omitempty"`
+	Tags     []string          `tau:"tags"`
+	Address  *address          `tau:"address"`
+	Limits   map[string]uint8  `tau:"limits"`
+	Password string            `tau:"-"`
+	Extra    map[string]any    `tau:"extra,omitempty"`
+	Balance  big.Int           `tau:"balance"`
+	Notes    map[int64]float64 `tau:"notes,omitempty"`
+}
+
+type Base struct {
+	ID int `tau:"id"`
+}
+
+type embedding struct {
+	*Base
+	Name string `tau:"name"`
+}
+
+type node struct {
+	Next *node `tau:"next"`
+}
+
+type tree []tree
+
+func TestToObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "integer", value: int8(-3), expected: "-3"},
+		{name: "unsigned integer too large for an integer", value: uint64(math.MaxUint64), expected: "18446744073709551615"},
+		{name: "float", value: float32(1.5), expected: "1.5"},
+		{name: "string", value: "tau", expected: "tau"},
+		{name: "nil", value: nil, expected: "null"},
+		{name: "nil pointer", value: (*address)(nil), expected: "null"},
+		{name: "slice", value: []any{1, "a", true}, expected: "[1, a, true]"},
+		{name: "map keys are sorted", value: map[string]int{"b": 2, "a": 1}, expected: "{a: 1, b: 2}"},
+		{name: "object", value: &object.Integer{Value: 7}, expected: "7"},
+		{name: "fields of a nil embedded pointer are left out", value: embedding{Name: "asha"}, expected: "{name: asha}"},
+		{name: "embedded pointer", value: embedding{Base: &Base{ID: 1}, Name: "asha"}, expected: "{id: 1, name: asha}"},
+		{name: "shared values", value: func() any { shared := []int{1}; return []any{shared, shared} }(), expected: "[[1], [1]]"},
+		{
+			name: "struct with tags",
+			value: customer{
+				Name:     "asha",
+				Tags:     []string{"vip"},
+				Address:  &address{City: "pune"},
+				Password: "secret",
+				Balance:  *new(big.Int).Lsh(big.NewInt(1), 70),
+			},
+			expected: "{name: asha, tags: [vip], address: {city: pune}, limits: null, balance: 1180591620717411303424}",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := taulang.ToObject(tc.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, obj.Inspect())
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	interpreter := taulang.NewInterpreter()
+	program, err := interpreter.Compile("", `{
+	"name": "asha",
+	"age": 31,
+	"tags": ["vip", "early"],
+	"address": {"city": "pune"},
+	"limits": {"daily": 200},
+	"balance": 2 ** 70,
+	"notes": {1: 2.5},
+	"ignored": saccha
+}`)
+	assert.NoError(t, err)
+
+	result, err := interpreter.RunObject(program, nil)
+	assert.NoError(t, err)
+
+	var decoded customer
+	assert.NoError(t, taulang.Decode(result, &decoded))
+	assert.Equal(t, "asha", decoded.Name)
+	assert.Equal(t, 31, decoded.Age)
+	assert.Equal(t, []string{"vip", "early"}, decoded.Tags)
+	assert.Equal(t, &address{City: "pune"}, decoded.Address)
+	assert.Equal(t, map[string]uint8{"daily": 200}, decoded.Limits)
+	assert.Equal(t, "1180591620717411303424", decoded.Balance.String())
+	assert.Equal(t, map[int64]float64{1: 2.5}, decoded.Notes)
+
+	var generic any
+	assert.NoError(t, taulang.Decode(result, &generic))
+	assert.Equal(t, "asha", generic.(map[string]any)["name"])
+}
+
+func TestDecodeAllocatesEmbeddedPointers(t *testing.T) {
+	t.Parallel()
+
+	obj, err := taulang.ToObject(map[string]any{"id": 1, "name": "asha"})
+	assert.NoError(t, err)
+
+	var decoded embedding
+	assert.NoError(t, taulang.Decode(obj, &decoded))
+	assert.Equal(t, embedding{Base: &Base{ID: 1}, Name: "asha"}, decoded)
+}
+
+func TestConvertValuesThatContainThemselves(t *testing.T) {
+	t.Parallel()
+
+	n := &node{}
+	n.Next = n
+	_, err := taulang.ToObject(n)
+	assert.EqualError(t, err, "next: cannot convert *taulang_test.node that contains itself")
+
+	slice := []any{nil}
+	slice[0] = slice
+	_, err = taulang.ToObject(slice)
+	assert.EqualError(t, err, "[0]: cannot convert []interface {} that contains itself")
+
+	hashMap := map[string]any{}
+	hashMap["self"] = hashMap
+	_, err = taulang.ToObject(hashMap)
+	assert.EqualError(t, err, "[self]: cannot convert map[string]interface {} that contains itself")
+
+	array := &object.Array{}
+	array.Elements = []object.Object{array}
+	_, err = taulang.FromObject(array)
+	assert.EqualError(t, err, "[0]: cannot convert ARRAY that contains itself")
+
+	var decoded tree
+	assert.EqualError(t, taulang.Decode(array, &decoded), "[0]: cannot decode ARRAY that contains itself")
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      object.Object
+		target   any
+		expected string
+	}{
+		{
+			name:     "target is not a pointer",
+			obj:      &object.Integer{Value: 1},
+			target:   1,
+			expected: "decode target must be a non-nil pointer, got int",
+		},
+		{
+			name:     "type mismatch",
+			obj:      &object.String{Value: "a"},
+			target:   new(int),
+			expected: "cannot decode STRING into int",
+		},
+		{
+			name:     "integer overflows the target",
+			obj:      &object.Integer{Value: 300},
+			target:   new(uint8),
+			expected: "300 overflows uint8",
+		},
+		{
+			name:     "error inside an array",
+			obj:      &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Float{Value: 1.5}}},
+			target:   new([]int),
+			expected: "[1]: cannot decode FLOAT into int",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.EqualError(t, taulang.Decode(tc.obj, tc.target), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package taulang
+
+import (
+	"strings"
+	"taulang/diagnostic"
+	"taulang/object"
+)
+
+// SyntaxError is returned when a source is not a valid program
+type SyntaxError struct {
+	Filename string
+	Source   string
+	// Diagnostics holds the parser errors, it is empty when the source couldn't
+	// be tokenized and Message describes the problem instead
+	Diagnostics []diagnostic.Diagnostic
+	Message     string
+}
+
+func (e *SyntaxError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return withFilename(e.Filename, diagnostic.Diagnostic{Message: e.Message})
+	}
+
+	messages := make([]string, len(e.Diagnostics))
+	for idx, d := range e.Diagnostics {
+		messages[idx] = withFilename(e.Filename, d)
+	}
+	return strings.Join(messages, "\n")
+}
+
+// RuntimeError is returned when a program fails while running, Err.Kind tells
+// what kind of error it was
+type RuntimeError struct {
+	Filename string
+	Source   string
+	Err      *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return withFilename(e.Filename, diagnostic.Diagnostic{
+		Position: e.Err.Position,
+		Message:  e.Err.Inspect(),
+	})
+}
+
+// withFilename formats the diagnostic like the first line of diagnostic.Render
+func withFilename(filename string, d diagnostic.Diagnostic) string {
+	switch {
+	case filename == "":
+		return d.Error()
+	case d.Position.IsValid():
+		return filename + ":" + d.Error()
+	default:
+		return filename + ": " + d.Error()
+	}
+}
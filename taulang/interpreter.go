@@ -0,0 +1,140 @@
+// Package taulang embeds the TauLang interpreter in Go programs. Sources are
+// compiled once into a Program, which can be run any number of times with
+// different globals, e.g.
+//
+//	interpreter := taulang.NewInterpreter()
+//	program, err := interpreter.Compile("rule.tau", "price * 2")
+//	...
+//	result, err := interpreter.Run(program, map[string]any{"price": 21})
+package taulang
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"taulang/ast"
+	"taulang/evaluator"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/parser"
+)
+
+// Interpreter compiles and runs TauLang programs
+type Interpreter struct {
+	// builtins are added by the host, see RegisterBuiltin
+	builtins     *evaluator.Registry
+	limits       object.Limits
+	capabilities object.Capabilities
+	streams      *object.Streams
+}
+
+// NewInterpreter creates an interpreter whose programs can use all builtins,
+// see NewInterpreterWithCapabilities for running untrusted programs
+func NewInterpreter() *Interpreter {
+	return NewInterpreterWithCapabilities(object.Profiles["full"])
+}
+
+// NewInterpreterWithCapabilities creates an interpreter whose programs can
+// only call the builtins the capabilities allow, other calls fail with an
+// error of kind object.PermissionError
+func NewInterpreterWithCapabilities(capabilities object.Capabilities) *Interpreter {
+	return &Interpreter{builtins: evaluator.NewRegistry(), capabilities: capabilities}
+}
+
+// SetLimits bounds the resources every run of a program can use, a run that
+// exceeds them fails with an error of kind object.LimitError or
+// object.StackOverflowError
+func (i *Interpreter) SetLimits(limits object.Limits) {
+	i.limits = limits
+}
+
+// SetStreams redirects what programs print and read, by default they use the
+// standard streams of the process. Concurrent runs share the streams, every
+// line of stdin is read by one of them and their writes are not interleaved.
+func (i *Interpreter) SetStreams(stdout, stderr io.Writer, stdin io.Reader) {
+	i.streams = object.NewStreams(stdout, stderr, stdin)
+}
+
+// Program is a compiled source, it doesn't hold any state of its runs and can
+// be run concurrently
+type Program struct {
+	// Filename is used for resolving imports and reporting errors, it may be
+	// empty for sources that don't come from a file
+	Filename string
+	Source   string
+	program  *ast.Program
+}
+
+// Compile parses the source, the error is a *SyntaxError when the source is
+// not a valid program
+func (i *Interpreter) Compile(filename string, source string) (*Program, error) {
+	l, err := lexer.NewLexer(source)
+	if err != nil {
+		return nil, &SyntaxError{Filename: filename, Source: source, Message: err.Error()}
+	}
+
+	p := parser.NewParser(l)
+	program := p.Parse()
+	if diagnostics := p.Diagnostics(); len(diagnostics) != 0 {
+		return nil, &SyntaxError{Filename: filename, Source: source, Diagnostics: diagnostics}
+	}
+
+	return &Program{Filename: filename, Source: source, program: program}, nil
+}
+
+// Run runs the program in a fresh environment where the globals are bound, and
+// returns the value of its last statement converted with FromObject. The error
+// is a *RuntimeError when the program failed.
+func (i *Interpreter) Run(program *Program, globals map[string]any) (any, error) {
+	return i.RunContext(context.Background(), program, globals)
+}
+
+// RunContext is Run for a run that is interrupted once ctx is done, it then
+// fails with an error of kind object.InterruptedError
+func (i *Interpreter) RunContext(ctx context.Context, program *Program, globals map[string]any) (any, error) {
+	result, err := i.RunObjectContext(ctx, program, globals)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromObject(result)
+}
+
+// RunObject is Run without converting the result to a Go value
+func (i *Interpreter) RunObject(program *Program, globals map[string]any) (object.Object, error) {
+	return i.RunObjectContext(context.Background(), program, globals)
+}
+
+// RunObjectContext is RunContext without converting the result to a Go value
+func (i *Interpreter) RunObjectContext(ctx context.Context, program *Program, globals map[string]any) (object.Object, error) {
+	moduleContext := evaluator.NewModuleContextWithBuiltins(program.Filename, i.builtins)
+	moduleContext.Budget = object.NewBudget(i.limits)
+	moduleContext.Capabilities = i.capabilities
+	moduleContext.Streams = i.streams
+
+	env := object.NewModuleEnvironment(moduleContext)
+	for name, value := range globals {
+		obj, err := ToObject(value)
+		if err != nil {
+			return nil, fmt.Errorf("global %s: %w", name, err)
+		}
+		env.Set(name, obj)
+	}
+
+	result := evaluator.EvalContext(ctx, program.program, env)
+	if err, ok := result.(*object.Error); ok {
+		return nil, &RuntimeError{Filename: program.Filename, Source: program.Source, Err: err}
+	}
+
+	return result, nil
+}
+
+// Eval compiles and runs the source, see Compile and Run
+func (i *Interpreter) Eval(filename string, source string, globals map[string]any) (any, error) {
+	program, err := i.Compile(filename, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.Run(program, globals)
+}
@@ -0,0 +1,281 @@
+package taulang_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"taulang/object"
+	"taulang/taulang"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		globals  map[string]any
+		expected any
+	}{
+		{
+			name:     "last statement is the result",
+			input:    "sun_liyo_tau x ne_bana_diye 20; x + 1",
+			expected: int64(21),
+		},
+		{
+			name:     "globals are bound",
+			input:    `price * quantity`,
+			globals:  map[string]any{"price": 2.5, "quantity": 4},
+			expected: 10.0,
+		},
+		{
+			name:     "hash maps with string keys become maps",
+			input:    `{"name": name, "tags": ["a", "b"]}`,
+			globals:  map[string]any{"name": "tau"},
+			expected: map[string]any{"name": "tau", "tags": []any{"a", "b"}},
+		},
+		{
+			name:     "null becomes nil",
+			input:    `sun_liyo_tau x ne_bana_diye 1;`,
+			expected: nil,
+		},
+	}
+
+	interpreter := taulang.NewInterpreter()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := interpreter.Eval("rule.tau", tc.input, tc.globals)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestInterpreterProgramIsReusable(t *testing.T) {
+	t.Parallel()
+
+	interpreter := taulang.NewInterpreter()
+	program, err := interpreter.Compile("double.tau", "sun_liyo_tau y ne_bana_diye x * 2; y")
+	assert.NoError(t, err)
+
+	for _, x := range []int{1, 2, 3} {
+		result, err := interpreter.Run(program, map[string]any{"x": x})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(x*2), result)
+	}
+}
+
+func TestInterpreterErrors(t *testing.T) {
+	t.Parallel()
+
+	interpreter := taulang.NewInterpreter()
+
+	_, err := interpreter.Eval("broken.tau", "sun_liyo_tau = 1;", nil)
+	var syntaxErr *taulang.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr), "expected a syntax error, got %v", err)
+	assert.Equal(t, "broken.tau:1:14: expected next token to be IDENTIFIER, got ILLEGAL (=)\n"+
+		"broken.tau:1:14: no prefix parse function found for ILLEGAL (=)", err.Error())
+
+	_, err = interpreter.Eval("broken.tau", `"unterminated`, nil)
+	assert.True(t, errors.As(err, &syntaxErr), "expected a syntax error, got %v", err)
+
+	_, err = interpreter.Eval("fail.tau", "1 + nahi_hai", nil)
+	var runtimeErr *taulang.RuntimeError
+	assert.True(t, errors.As(err, &runtimeErr), "expected a runtime error, got %v", err)
+	assert.Equal(t, object.NameError, runtimeErr.Err.Kind)
+	assert.Equal(t, "fail.tau:1:5: NameError: identifier not found: nahi_hai", err.Error())
+
+	_, err = interpreter.Eval("", "1", map[string]any{"ch": make(chan int)})
+	assert.EqualError(t, err, "global ch: cannot convert chan int to a TauLang value")
+
+	_, err = interpreter.Eval("", "tau_ka_jugaad() { 1 }", nil)
+	assert.EqualError(t, err, "cannot convert FUNCTION to a Go value")
+}
+
+func TestInterpreterLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   object.Limits
+		timeout  time.Duration
+		input    string
+		expected string
+	}{
+		{
+			name:     "timeout",
+			timeout:  10 * time.Millisecond,
+			input:    `jab_tak (saccha) { }`,
+			expected: "InterruptedError: execution interrupted: context deadline exceeded",
+		},
+		{
+			name:     "timeout can't be caught",
+			timeout:  10 * time.Millisecond,
+			input:    `jab_tak (saccha) { koshish_karo { jab_tak (saccha) { } } pakad_lo (e) { } }`,
+			expected: "InterruptedError: execution interrupted: context deadline exceeded",
+		},
+		{
+			name:     "allocation limit can't be caught",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `jab_tak (saccha) { koshish_karo { repeat("ab", 1 << 29) } pakad_lo (e) { } }`,
+			expected: "LimitError: allocation limit of 1048576 bytes exceeded",
+		},
+		{
+			name:     "step limit",
+			limits:   object.Limits{MaxSteps: 1000},
+			input:    `jab_tak (saccha) { }`,
+			expected: "LimitError: step limit of 1000 exceeded",
+		},
+		{
+			name:     "step limit can't be caught",
+			limits:   object.Limits{MaxSteps: 1000},
+			input:    `jab_tak (saccha) { koshish_karo { jab_tak (saccha) { } } pakad_lo (e) { } }`,
+			expected: "LimitError: step limit of 1000 exceeded",
+		},
+		{
+			name:     "call depth",
+			limits:   object.Limits{MaxDepth: 50},
+			input:    `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(n) { f(n + 1) }; f(0)`,
+			expected: "StackOverflowError: stack overflow: maximum call depth of 50 exceeded",
+		},
+		{
+			name:     "default call depth",
+			input:    `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(n) { f(n + 1) }; f(0)`,
+			expected: "StackOverflowError: stack overflow: maximum call depth of 10000 exceeded",
+		},
+		{
+			name:     "call depth through a default parameter",
+			input:    `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(d ne_bana_diye f()) { d }; f()`,
+			expected: "StackOverflowError: stack overflow: maximum call depth of 10000 exceeded",
+		},
+		{
+			name:     "allocation of strings",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `sun_liyo_tau s ne_bana_diye "ab"; jab_tak (saccha) { s += s; }`,
+			expected: "LimitError: allocation limit of 1048576 bytes exceeded",
+		},
+		{
+			name:     "allocation of arrays by builtins",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `sun_liyo_tau a ne_bana_diye [0]; jab_tak (saccha) { a ne_bana_diye push(a, 0); }`,
+			expected: "LimitError: allocation limit of 1048576 bytes exceeded",
+		},
+		{
+			name:     "allocation is checked before repeat builds the string",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `repeat("ab", 1 << 29)`,
+			expected: "LimitError: allocation limit of 1048576 bytes exceeded",
+		},
+		{
+			name:     "allocation is checked before strings are concatenated",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `sun_liyo_tau s ne_bana_diye repeat("a", 600000); s + s`,
+			expected: "LimitError: allocation limit of 1048576 bytes exceeded",
+		},
+		{
+			name:     "allocation of array growth by index assignment",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `sun_liyo_tau a ne_bana_diye []; a[1000000000000] ne_bana_diye 1`,
+			expected: "LimitError: allocation limit of 1048576 bytes exceeded",
+		},
+		{
+			name:     "allocation of hash maps",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `sun_liyo_tau h ne_bana_diye {}; sun_liyo_tau i ne_bana_diye 0; jab_tak (saccha) { h[i] ne_bana_diye i; i += 1; }`,
+			expected: "LimitError: allocation limit of 1048576 bytes exceeded",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			interpreter := taulang.NewInterpreter()
+			interpreter.SetLimits(tc.limits)
+			program, err := interpreter.Compile("", tc.input)
+			assert.NoError(t, err)
+
+			ctx := context.Background()
+			if tc.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			_, err = interpreter.RunContext(ctx, program, nil)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestInterpreterCapabilities(t *testing.T) {
+	t.Parallel()
+
+	sandboxed := taulang.NewInterpreterWithCapabilities(object.Profiles["safe"])
+	assert.NoError(t, sandboxed.RegisterBuiltin("host_env", &object.Builtin{
+		Capability: object.EnvCapability,
+		Fn: func(_ object.BuiltinContext, _ ...object.Object) object.Object {
+			return &object.String{Value: "secret"}
+		},
+	}))
+
+	result, err := sandboxed.Eval("", `random_int(1, 2)`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+
+	_, err = sandboxed.Eval("snippet.tau", `env("HOME")`, nil)
+	var runtimeErr *taulang.RuntimeError
+	assert.True(t, errors.As(err, &runtimeErr), "expected a runtime error, got %v", err)
+	assert.Equal(t, object.PermissionError, runtimeErr.Err.Kind)
+	assert.EqualError(t, err, "snippet.tau:1:4: PermissionError: permission denied: `env` needs the env capability")
+
+	_, err = sandboxed.Eval("", `host_env()`, nil)
+	assert.ErrorContains(t, err, "permission denied: `host_env` needs the env capability")
+
+	_, err = taulang.NewInterpreter().Eval("", `env("HOME")`, nil)
+	assert.NoError(t, err)
+}
+
+func TestInterpreterStreams(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr strings.Builder
+	interpreter := taulang.NewInterpreter()
+	interpreter.SetStreams(&stdout, &stderr, strings.NewReader("tau\n"))
+
+	result, err := interpreter.Eval("", `printf("hi %s\n", input("? ")); eprint("done"); read_line()`, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "? hi tau\n", stdout.String())
+	assert.Equal(t, "done\n", stderr.String())
+
+	_, err = taulang.NewInterpreterWithCapabilities(object.Profiles["safe"]).Eval("", `input()`, nil)
+	assert.ErrorContains(t, err, "permission denied: `input` needs the input capability")
+}
+
+func TestInterpreterStreamsOfConcurrentRuns(t *testing.T) {
+	t.Parallel()
+
+	var stdout strings.Builder
+	interpreter := taulang.NewInterpreter()
+	interpreter.SetStreams(&stdout, io.Discard, strings.NewReader(strings.Repeat("tau\n", 100)))
+	program, err := interpreter.Compile("", `jab_tak (read_line()) { print("line") }`)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := interpreter.Run(program, nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, strings.Repeat("line\n", 100), stdout.String())
+}
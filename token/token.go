@@ -1,13 +1,23 @@
 package token
 
+import "fmt"
+
 type Type string
 
 const (
 	// identifiers + literals
 	NUMBER     Type = "NUMBER"
+	FLOAT      Type = "FLOAT"
 	STRING     Type = "STRING"
 	IDENTIFIER Type = "IDENTIFIER"
 
+	// an interpolated string $"a{x}b{y}c" is lexed as INTERPOLATION_START "a",
+	// the tokens of x, INTERPOLATION_MIDDLE "b", the tokens of y and
+	// INTERPOLATION_END "c"
+	INTERPOLATION_START  Type = "INTERPOLATION_START"
+	INTERPOLATION_MIDDLE Type = "INTERPOLATION_MIDDLE"
+	INTERPOLATION_END    Type = "INTERPOLATION_END"
+
 	// operators
 	ASSIGNMENT     Type = "ASSIGNMENT"     // ne_bana_diye
 	BANG           Type = "BANG"           // !
@@ -21,6 +31,20 @@ const (
 	SUBTRACTION    Type = "SUBTRACTION"    // -
 	MULTIPLICATION Type = "MULTIPLICATION" // *
 	DIVISION       Type = "DIVISION"       // /
+	MODULO         Type = "MODULO"         // %
+	POWER          Type = "POWER"          // **
+	BITWISE_AND    Type = "BITWISE_AND"    // &
+	BITWISE_OR     Type = "BITWISE_OR"     // |
+	BITWISE_XOR    Type = "BITWISE_XOR"    // ^
+	BITWISE_NOT    Type = "BITWISE_NOT"    // ~
+	LEFT_SHIFT     Type = "LEFT_SHIFT"     // <<
+	RIGHT_SHIFT    Type = "RIGHT_SHIFT"    // >>
+	AND            Type = "AND"            // && or aur
+	OR             Type = "OR"             // || or ya_phir
+
+	// the literal of a compound assignment is the operator followed by =,
+	// e.g. += or <<=
+	COMPOUND_ASSIGNMENT Type = "COMPOUND_ASSIGNMENT"
 
 	// keywords
 	LET      Type = "LET"
@@ -33,11 +57,20 @@ const (
 	WHILE    Type = "WHILE"
 	BREAK    Type = "BREAK"
 	CONTINUE Type = "CONTINUE"
+	FOR      Type = "FOR"
+	IN       Type = "IN"
+	IMPORT   Type = "IMPORT"
+	TRY      Type = "TRY"
+	CATCH    Type = "CATCH"
+	FINALLY  Type = "FINALLY"
+	THROW    Type = "THROW"
 
 	// delimiters
 	COMMA     Type = "COMMA"     // ,
 	SEMICOLON Type = "SEMICOLON" // ;
 	COLON     Type = "COLON"     // :
+	DOT       Type = "DOT"       // .
+	ELLIPSIS  Type = "ELLIPSIS"  // ...
 
 	// parenthesis
 	LEFT_BRACE    Type = "LEFT_BRACE"    // {
@@ -52,9 +85,30 @@ const (
 	ILLEGAL Type = "ILLEGAL"
 )
 
+// Position is a location in the source text. Line and Column are 1-based and
+// Column counts runes, Offset is the 0-based byte offset into the source.
+type Position struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+// IsValid reports whether the position has been set by the lexer.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
-	Type    Type
-	Literal string
+	Type     Type
+	Literal  string
+	Position Position
 }
 
 var Keywords = map[string]Type{
@@ -68,7 +122,16 @@ var Keywords = map[string]Type{
 	"jab_tak":       WHILE,
 	"rok_diye":      BREAK,
 	"jaan_de":       CONTINUE,
+	"har_ek":        FOR,
+	"mein":          IN,
 	"ne_bana_diye":  ASSIGNMENT,
+	"aur":           AND,
+	"ya_phir":       OR,
+	"mangwa_lo":     IMPORT,
+	"koshish_karo":  TRY,
+	"pakad_lo":      CATCH,
+	"aakhir_mein":   FINALLY,
+	"fenk_do":       THROW,
 }
 
 var ReverseKeywords = map[Type]string{
@@ -82,7 +145,16 @@ var ReverseKeywords = map[Type]string{
 	WHILE:      "while",
 	BREAK:      "break",
 	CONTINUE:   "continue",
+	FOR:        "for",
+	IN:         "in",
 	ASSIGNMENT: "=",
+	AND:        "&&",
+	OR:         "||",
+	IMPORT:     "import",
+	TRY:        "try",
+	CATCH:      "catch",
+	FINALLY:    "finally",
+	THROW:      "throw",
 }
 
 func GetTokenForIdentifierOrKeyword(value string) Token {
@@ -79,6 +79,48 @@ func TestLookupIdentifier(t *testing.T) {
 			expected:        IDENTIFIER,
 			expectedLiteral: "myVariable",
 		},
+		{
+			name:            "lookup AND keyword",
+			input:           "aur",
+			expected:        AND,
+			expectedLiteral: "&&",
+		},
+		{
+			name:            "lookup OR keyword",
+			input:           "ya_phir",
+			expected:        OR,
+			expectedLiteral: "||",
+		},
+		{
+			name:            "lookup IMPORT keyword",
+			input:           "mangwa_lo",
+			expected:        IMPORT,
+			expectedLiteral: "import",
+		},
+		{
+			name:            "lookup TRY keyword",
+			input:           "koshish_karo",
+			expected:        TRY,
+			expectedLiteral: "try",
+		},
+		{
+			name:            "lookup CATCH keyword",
+			input:           "pakad_lo",
+			expected:        CATCH,
+			expectedLiteral: "catch",
+		},
+		{
+			name:            "lookup FINALLY keyword",
+			input:           "aakhir_mein",
+			expected:        FINALLY,
+			expectedLiteral: "finally",
+		},
+		{
+			name:            "lookup THROW keyword",
+			input:           "fenk_do",
+			expected:        THROW,
+			expectedLiteral: "throw",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,22 @@
+package vm
+
+import "taulang/object"
+
+const CELL_OBJ = "CELL"
+
+// cell boxes a variable captured by a closure, the stack slot of the variable
+// and every closure capturing it refer to the same cell
+type cell struct {
+	value object.Object
+}
+
+func (c *cell) Type() object.Type {
+	return CELL_OBJ
+}
+
+func (c *cell) Inspect() string {
+	if c.value == nil {
+		return "null"
+	}
+	return c.value.Inspect()
+}
@@ -0,0 +1,20 @@
+package vm
+
+import (
+	"taulang/code"
+	"taulang/object"
+)
+
+type frame struct {
+	cl          *object.Closure
+	ip          int
+	basePointer int
+}
+
+func newFrame(cl *object.Closure, basePointer int) *frame {
+	return &frame{cl: cl, ip: -1, basePointer: basePointer}
+}
+
+func (f *frame) instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
@@ -0,0 +1,112 @@
+package vm
+
+import (
+	"errors"
+	"sort"
+	"taulang/ast"
+	"taulang/compiler"
+	"taulang/diagnostic"
+	"taulang/module"
+	"taulang/object"
+)
+
+// NewModuleContext returns the context for running the file filename, the
+// files it imports are compiled and run once per program on their own vm. Runs
+// are limited by the budget of the context, which has the default limits.
+func NewModuleContext(filename string) *object.ModuleContext {
+	context := &object.ModuleContext{Filename: filename, Budget: object.NewBudget(object.Limits{})}
+
+	// imported files share the budget, the capabilities and the streams of the
+	// program, which can be replaced after the context is created
+	context.Loader = module.NewLoaderWithEntry(filename, func(filename string, program *ast.Program) (object.Environment, *object.Error) {
+		c := compiler.NewCompiler()
+		if err := c.Compile(program); err != nil {
+			var d diagnostic.Diagnostic
+			if errors.As(err, &d) {
+				return nil, &object.Error{Message: d.Message, Position: d.Position}
+			}
+			return nil, newError(object.SyntaxError, "%s", err)
+		}
+
+		bytecode := c.Bytecode()
+		moduleContext := &object.ModuleContext{
+			Filename:     filename,
+			Loader:       context.Loader,
+			Budget:       context.Budget,
+			Capabilities: context.Capabilities,
+			Streams:      context.Streams,
+		}
+		globals := make([]object.Object, GlobalsSize)
+
+		if err, ok := NewVMWithState(bytecode, globals, moduleContext).Run().(*object.Error); ok {
+			return nil, err
+		}
+
+		return NewGlobalsEnvironment(bytecode.GlobalNames, globals, moduleContext), nil
+	})
+
+	return context
+}
+
+// globalsEnvironment exposes the globals of a module as an environment, so
+// that members of compiled modules are looked up like evaluated ones
+type globalsEnvironment struct {
+	indexes map[string]int
+	globals []object.Object
+	context *object.ModuleContext
+}
+
+// NewGlobalsEnvironment exposes the globals of a program, names are the global
+// names by index as in compiler.Bytecode
+func NewGlobalsEnvironment(names []string, globals []object.Object, context *object.ModuleContext) object.Environment {
+	indexes := make(map[string]int, len(names))
+	for idx, name := range names {
+		// the slots of catch parameters have no name
+		if name != "" {
+			indexes[name] = idx
+		}
+	}
+
+	return &globalsEnvironment{indexes: indexes, globals: globals, context: context}
+}
+
+func (g *globalsEnvironment) Get(key string) (object.Object, bool) {
+	idx, ok := g.indexes[key]
+	if !ok || g.globals[idx] == nil {
+		return nil, false
+	}
+	return g.globals[idx], true
+}
+
+func (g *globalsEnvironment) Set(key string, value object.Object) object.Object {
+	idx, ok := g.indexes[key]
+	if !ok {
+		idx = len(g.indexes)
+		g.indexes[key] = idx
+	}
+	g.globals[idx] = value
+	return value
+}
+
+func (g *globalsEnvironment) Assign(key string, value object.Object) (object.Object, bool) {
+	if _, ok := g.Get(key); !ok {
+		return nil, false
+	}
+	return g.Set(key, value), true
+}
+
+func (g *globalsEnvironment) Context() *object.ModuleContext {
+	return g.context
+}
+
+func (g *globalsEnvironment) Names() []string {
+	var names []string
+	for name, idx := range g.indexes {
+		if g.globals[idx] != nil {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
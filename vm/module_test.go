@@ -0,0 +1,162 @@
+package vm_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"taulang/compiler"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/parser"
+	"taulang/vm"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVMImports(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := map[string]string{
+		"math.tau": `sun_liyo_tau pi ne_bana_diye 3;
+sun_liyo_tau add ne_bana_diye tau_ka_jugaad(a, b) { a + b };
+sun_liyo_tau double ne_bana_diye tau_ka_jugaad(a) { add(a, a) };`,
+		"lib/geometry.tau": `sun_liyo_tau math ne_bana_diye mangwa_lo "../math";
+sun_liyo_tau circumference ne_bana_diye tau_ka_jugaad(r) { 2 * math.pi * r };`,
+		"broken.tau":  `agar_maan_lo (saccha) { rok_diye; };`,
+		"cycle_a.tau": `mangwa_lo "./cycle_b.tau";`,
+		"cycle_b.tau": `mangwa_lo "./cycle_a.tau";`,
+	}
+	for name, content := range fixtures {
+		filename := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+		assert.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	}
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedObject object.Object
+	}{
+		{
+			name:           "success - imported functions use the globals of their module",
+			input:          `sun_liyo_tau m ne_bana_diye mangwa_lo "./math.tau"; sun_liyo_tau add ne_bana_diye 0; m.double(m.pi);`,
+			expectedObject: &object.Integer{Value: 6},
+		},
+		{
+			name:           "success - nested imports",
+			input:          `(mangwa_lo "lib/geometry").circumference(2);`,
+			expectedObject: &object.Integer{Value: 12},
+		},
+		{
+			name:           "failure - compile error inside module",
+			input:          `mangwa_lo "./broken.tau";`,
+			expectedObject: &object.Error{Message: "error in module broken.tau at 1:25: found break statement outside of loop"},
+		},
+		{
+			name:           "failure - import cycle",
+			input:          `mangwa_lo "./cycle_a.tau";`,
+			expectedObject: &object.Error{Message: "error in module cycle_a.tau at 1:1: error in module cycle_b.tau at 1:1: import cycle: cycle_a.tau -> cycle_b.tau -> cycle_a.tau"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := lexer.NewLexer(tc.input)
+			assert.NoError(t, err)
+
+			p := parser.NewParser(l)
+			program := p.Parse()
+			assert.Empty(t, p.Errors())
+
+			c := compiler.NewCompiler()
+			assert.NoError(t, c.Compile(program))
+
+			context := vm.NewModuleContext(filepath.Join(dir, "main.tau"))
+			o := vm.NewVMWithState(c.Bytecode(), make([]object.Object, vm.GlobalsSize), context).Run()
+
+			if expectedError, ok := tc.expectedObject.(*object.Error); ok {
+				actualError, ok := o.(*object.Error)
+				assert.True(t, ok, "expected error, got %s", o.Inspect())
+				if ok {
+					assert.Equal(t, expectedError.Message, actualError.Message)
+				}
+				return
+			}
+
+			assert.Equal(t, tc.expectedObject, o)
+		})
+	}
+}
+
+func TestVMCapabilitiesOfImports(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "shout.tau"),
+		[]byte(`sun_liyo_tau shout ne_bana_diye tau_ka_jugaad(x) { print(x) };`), 0o644))
+
+	l, err := lexer.NewLexer(`(mangwa_lo "./shout.tau").shout("tau");`)
+	assert.NoError(t, err)
+
+	c := compiler.NewCompiler()
+	assert.NoError(t, c.Compile(parser.NewParser(l).Parse()))
+
+	context := vm.NewModuleContext(filepath.Join(dir, "main.tau"))
+	context.Capabilities = object.NewCapabilities(object.FileReadCapability)
+	o := vm.NewVMWithState(c.Bytecode(), make([]object.Object, vm.GlobalsSize), context).Run()
+
+	actualError, ok := o.(*object.Error)
+	assert.True(t, ok, "expected error, got %s", o.Inspect())
+	if ok {
+		assert.Equal(t, object.PermissionError, actualError.Kind)
+		assert.Equal(t, "permission denied: `print` needs the print capability", actualError.Message)
+	}
+}
+
+func TestVMImportsNeedFileReadCapability(t *testing.T) {
+	t.Parallel()
+
+	l, err := lexer.NewLexer(`mangwa_lo "/etc/host.conf";`)
+	assert.NoError(t, err)
+
+	c := compiler.NewCompiler()
+	assert.NoError(t, c.Compile(parser.NewParser(l).Parse()))
+
+	context := vm.NewModuleContext("main.tau")
+	context.Capabilities = object.Profiles["safe"]
+	o := vm.NewVMWithState(c.Bytecode(), make([]object.Object, vm.GlobalsSize), context).Run()
+
+	actualError, ok := o.(*object.Error)
+	assert.True(t, ok, "expected error, got %s", o.Inspect())
+	if ok {
+		assert.Equal(t, object.PermissionError, actualError.Kind)
+		assert.Equal(t, "permission denied: `mangwa_lo` needs the fs_read capability", actualError.Message)
+	}
+}
+
+func TestVMImportCycleThroughTheEntryFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	source := `print("c1"); mangwa_lo "./c2.tau";`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c1.tau"), []byte(source), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c2.tau"), []byte(`mangwa_lo "./c1.tau";`), 0o644))
+
+	l, err := lexer.NewLexer(source)
+	assert.NoError(t, err)
+
+	c := compiler.NewCompiler()
+	assert.NoError(t, c.Compile(parser.NewParser(l).Parse()))
+
+	var stdout strings.Builder
+	context := vm.NewModuleContext(filepath.Join(dir, "c1.tau"))
+	context.Streams = object.NewStreams(&stdout, io.Discard, strings.NewReader(""))
+	o := vm.NewVMWithState(c.Bytecode(), make([]object.Object, vm.GlobalsSize), context).Run()
+
+	// the entry file doesn't run again when it is imported
+	assert.Equal(t, "c1\n", stdout.String())
+	assert.Equal(t, "ImportError: error in module c2.tau at 1:1: import cycle: c1.tau -> c2.tau -> c1.tau", o.Inspect())
+}
@@ -0,0 +1,925 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"taulang/code"
+	"taulang/compiler"
+	"taulang/evaluator"
+	"taulang/object"
+)
+
+const (
+	// StackSize is the initial size of the stack, it grows up to MaxStackSize
+	// for deep calls and large literals
+	StackSize    = 2048
+	MaxStackSize = 1 << 20
+	// GlobalsSize is the number of globals the 2 byte operands of the global
+	// instructions can address, the compiler rejects programs with more
+	GlobalsSize = 65536
+)
+
+// Integers in this range are preallocated so that loop counters and other
+// small arithmetic results don't allocate
+const (
+	minCachedInteger = -128
+	maxCachedInteger = 1024
+)
+
+var integerCache = func() []*object.Integer {
+	cache := make([]*object.Integer, maxCachedInteger-minCachedInteger+1)
+	for idx := range cache {
+		cache[idx] = &object.Integer{Value: int64(idx + minCachedInteger)}
+	}
+	return cache
+}()
+
+var infixOperators = map[code.Opcode]string{
+	code.OpAdd:          "+",
+	code.OpSub:          "-",
+	code.OpMul:          "*",
+	code.OpDiv:          "/",
+	code.OpMod:          "%",
+	code.OpPow:          "**",
+	code.OpBitAnd:       "&",
+	code.OpBitOr:        "|",
+	code.OpBitXor:       "^",
+	code.OpShiftLeft:    "<<",
+	code.OpShiftRight:   ">>",
+	code.OpEqual:        "==",
+	code.OpNotEqual:     "!=",
+	code.OpGreaterThan:  ">",
+	code.OpGreaterEqual: ">=",
+	code.OpLessThan:     "<",
+	code.OpLessEqual:    "<=",
+}
+
+var prefixOperators = map[code.Opcode]string{
+	code.OpMinus:  "-",
+	code.OpBang:   "!",
+	code.OpBitNot: "~",
+}
+
+type VM interface {
+	// Run executes the bytecode and returns the value of the program, or an
+	// *object.Error if it failed
+	Run() object.Object
+	// RunContext is Run for a new run, which stops with an error once ctx is
+	// done or the run exceeds the limits of the budget of the module context
+	RunContext(ctx context.Context) object.Object
+}
+
+type vm struct {
+	builtins []*object.Builtin
+
+	stack []object.Object
+	sp    int // always points to the next free slot, top of stack is stack[sp-1]
+
+	frames      []*frame
+	framesIndex int
+
+	// budget limits the run like the evaluator's, nil for runs without a
+	// module context. Calls are limited by the number of frames.
+	budget    *object.Budget
+	maxFrames int
+
+	// handlers registered by try expressions, innermost last
+	handlers []handler
+
+	// returnDepth is the number of frames below the function called by a
+	// builtin, whose return ends the run loop, 0 when no builtin is calling
+	returnDepth int
+}
+
+// handler is where execution continues when an error is raised inside of a
+// try expression
+type handler struct {
+	ip          int
+	framesIndex int
+	sp          int
+	// finally handlers receive the error itself instead of an exception, they
+	// raise it again after running the finally block
+	finally bool
+}
+
+func NewVM(bytecode *compiler.Bytecode) VM {
+	return NewVMWithState(bytecode, make([]object.Object, GlobalsSize), nil)
+}
+
+// NewVMWithState creates a vm that shares the globals of a previous run, as
+// done by the REPL for every input. The context is used for imports, without
+// it imports fail.
+func NewVMWithState(bytecode *compiler.Bytecode, globals []object.Object, context *object.ModuleContext) VM {
+	mainClosure := &object.Closure{
+		Fn: bytecode.MainFunction,
+		Unit: &object.CompilationUnit{
+			Constants:   bytecode.Constants,
+			Globals:     globals,
+			GlobalNames: bytecode.GlobalNames,
+			Context:     context,
+		},
+	}
+
+	frames := []*frame{newFrame(mainClosure, 0)}
+
+	var budget *object.Budget
+	if context != nil {
+		budget = context.Budget
+	}
+	maxFrames := object.DefaultMaxDepth
+	if budget != nil {
+		maxFrames = budget.MaxDepth()
+	}
+
+	var builtins []*object.Builtin
+	for _, name := range evaluator.BuiltinNames() {
+		builtin, _ := evaluator.LookupBuiltin(name)
+		builtins = append(builtins, builtin)
+	}
+
+	return &vm{
+		builtins:    builtins,
+		stack:       make([]object.Object, StackSize),
+		sp:          0,
+		frames:      frames,
+		framesIndex: 1,
+		budget:      budget,
+		maxFrames:   maxFrames,
+	}
+}
+
+func (v *vm) Run() object.Object {
+	return v.runUntilReturn(0)
+}
+
+func (v *vm) RunContext(ctx context.Context) object.Object {
+	if v.budget != nil {
+		v.budget.Start(ctx)
+	}
+	return v.Run()
+}
+
+// runUntilReturn runs until the frame above depth returns, or the main
+// function for depth 0. Errors are only handled by the try expressions
+// entered during the run, others are left to the caller.
+func (v *vm) runUntilReturn(depth int) object.Object {
+	outerDepth := v.returnDepth
+	v.returnDepth = depth
+	defer func() { v.returnDepth = outerDepth }()
+
+	handlersBase := len(v.handlers)
+	for {
+		result, err := v.run()
+		if err == nil {
+			return result
+		}
+
+		// positions are attached here as the instruction that failed is known,
+		// errors raised again by finally blocks already have them
+		if !err.Position.IsValid() {
+			f := v.currentFrame()
+			err.Position = f.cl.Fn.SourceMap.Lookup(f.ip)
+		}
+		if err.Stack == nil && v.framesIndex > 1 {
+			err.Stack = v.stackTrace()
+		}
+
+		// errors of runs that exceeded their limits end the run
+		if len(v.handlers) == handlersBase || !err.Catchable() {
+			v.handlers = v.handlers[:handlersBase]
+			return err
+		}
+		v.handleError(err)
+	}
+}
+
+// handleError unwinds to the innermost handler and continues there with the
+// error on the stack
+func (v *vm) handleError(err *object.Error) {
+	h := v.handlers[len(v.handlers)-1]
+	v.handlers = v.handlers[:len(v.handlers)-1]
+
+	v.framesIndex = h.framesIndex
+	v.sp = h.sp
+	v.currentFrame().ip = h.ip - 1
+
+	// the stack can't overflow as it is back to where the try expression started
+	if h.finally {
+		_ = v.push(err)
+	} else {
+		_ = v.push(&object.Exception{Error: err})
+	}
+}
+
+// stackTrace lists the frames that are running, innermost first
+func (v *vm) stackTrace() []object.StackFrame {
+	stack := make([]object.StackFrame, 0, v.framesIndex)
+	for idx := v.framesIndex - 1; idx >= 0; idx-- {
+		f := v.frames[idx]
+
+		var filename string
+		if context := f.cl.Unit.Context; context != nil {
+			filename = context.Filename
+		}
+
+		stack = append(stack, object.StackFrame{
+			Function: f.cl.Fn.Name,
+			Filename: filename,
+			Position: f.cl.Fn.SourceMap.Lookup(f.ip),
+		})
+	}
+	return stack
+}
+
+func (v *vm) run() (object.Object, *object.Error) {
+	for {
+		f := v.currentFrame()
+		f.ip++
+		ins := f.instructions()
+		if f.ip >= len(ins) {
+			return nil, newError(object.InternalError, "unexpected end of instructions")
+		}
+
+		if v.budget != nil {
+			if err := v.budget.Step(); err != nil {
+				return nil, err
+			}
+		}
+
+		ip := f.ip
+		op := code.Opcode(ins[ip])
+
+		switch op {
+		case code.OpConstant:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			f.ip += 2
+
+			if err := v.push(f.cl.Unit.Constants[constIndex]); err != nil {
+				return nil, err
+			}
+
+		case code.OpPop:
+			v.pop()
+
+		case code.OpTrue:
+			if err := v.push(evaluator.TRUE); err != nil {
+				return nil, err
+			}
+
+		case code.OpFalse:
+			if err := v.push(evaluator.FALSE); err != nil {
+				return nil, err
+			}
+
+		case code.OpNull:
+			if err := v.push(evaluator.NULL); err != nil {
+				return nil, err
+			}
+
+		case code.OpDuplicate:
+			count := int(code.ReadUint8(ins[ip+1:]))
+			f.ip += 1
+
+			for _, value := range v.stack[v.sp-count : v.sp] {
+				if err := v.push(value); err != nil {
+					return nil, err
+				}
+			}
+
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod, code.OpPow,
+			code.OpBitAnd, code.OpBitOr, code.OpBitXor, code.OpShiftLeft, code.OpShiftRight,
+			code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpGreaterEqual,
+			code.OpLessThan, code.OpLessEqual:
+			if err := v.executeInfixOperation(op); err != nil {
+				return nil, err
+			}
+
+		case code.OpMinus, code.OpBang, code.OpBitNot:
+			result := evaluator.EvalPrefixOperator(prefixOperators[op], v.pop())
+			if err, ok := result.(*object.Error); ok {
+				return nil, err
+			}
+			if err := v.push(result); err != nil {
+				return nil, err
+			}
+
+		case code.OpJump:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			f.ip = pos - 1
+
+		case code.OpJumpNotTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			f.ip += 2
+
+			if !evaluator.IsTruthy(v.pop()) {
+				f.ip = pos - 1
+			}
+
+		case code.OpIter:
+			iterator, err := evaluator.NewIterator(v.pop())
+			if err != nil {
+				return nil, err
+			}
+			if err := v.push(iterator); err != nil {
+				return nil, err
+			}
+
+		case code.OpIterNext:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			numVariables := int(code.ReadUint8(ins[ip+3:]))
+			f.ip += 3
+
+			// the iterator is below the value of the loop
+			iterator := v.stack[v.sp-2].(*object.Iterator)
+			values, ok := iterator.Bind(numVariables)
+			if !ok {
+				v.stack[v.sp-2] = v.stack[v.sp-1]
+				v.sp--
+				f.ip = pos - 1
+				break
+			}
+
+			v.sp--
+			for _, value := range values {
+				if err := v.push(value); err != nil {
+					return nil, err
+				}
+			}
+
+		case code.OpJumpIfBound:
+			localIndex := int(code.ReadUint8(ins[ip+1:]))
+			pos := int(code.ReadUint16(ins[ip+2:]))
+			f.ip += 3
+
+			value := v.stack[f.basePointer+localIndex]
+			if c, ok := value.(*cell); ok {
+				value = c.value
+			}
+			if value != nil {
+				f.ip = pos - 1
+			}
+
+		case code.OpGetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			f.ip += 2
+
+			value := f.cl.Unit.Globals[globalIndex]
+			if value == nil {
+				return nil, newError(object.NameError, "identifier not found: %s", f.cl.Unit.GlobalNames[globalIndex])
+			}
+			if err := v.push(value); err != nil {
+				return nil, err
+			}
+
+		case code.OpSetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			f.ip += 2
+
+			f.cl.Unit.Globals[globalIndex] = v.pop()
+
+		case code.OpGetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			value := v.stack[f.basePointer+int(localIndex)]
+			if c, ok := value.(*cell); ok {
+				value = c.value
+			}
+			if value == nil {
+				return nil, newError(object.NameError, "identifier not found: %s", f.cl.Fn.LocalNames[localIndex])
+			}
+			if err := v.push(value); err != nil {
+				return nil, err
+			}
+
+		case code.OpSetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			slot := &v.stack[f.basePointer+int(localIndex)]
+			if c, ok := (*slot).(*cell); ok {
+				c.value = v.pop()
+			} else {
+				*slot = v.pop()
+			}
+
+		case code.OpGetFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			value := f.cl.Free[freeIndex].(*cell).value
+			if value == nil {
+				return nil, newError(object.NameError, "identifier not found: %s", f.cl.Fn.FreeNames[freeIndex])
+			}
+			if err := v.push(value); err != nil {
+				return nil, err
+			}
+
+		case code.OpAssignGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			f.ip += 2
+
+			if f.cl.Unit.Globals[globalIndex] == nil {
+				return nil, newError(object.NameError, "cannot assign to undeclared identifier: %s", f.cl.Unit.GlobalNames[globalIndex])
+			}
+			f.cl.Unit.Globals[globalIndex] = v.pop()
+
+		case code.OpAssignLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			slot := &v.stack[f.basePointer+int(localIndex)]
+			c, isCell := (*slot).(*cell)
+			if *slot == nil || (isCell && c.value == nil) {
+				return nil, newError(object.NameError, "cannot assign to undeclared identifier: %s", f.cl.Fn.LocalNames[localIndex])
+			}
+			if isCell {
+				c.value = v.pop()
+			} else {
+				*slot = v.pop()
+			}
+
+		case code.OpAssignFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			c := f.cl.Free[freeIndex].(*cell)
+			if c.value == nil {
+				return nil, newError(object.NameError, "cannot assign to undeclared identifier: %s", f.cl.Fn.FreeNames[freeIndex])
+			}
+			c.value = v.pop()
+
+		case code.OpGetBuiltin:
+			builtinIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			if err := v.push(v.builtins[builtinIndex]); err != nil {
+				return nil, err
+			}
+
+		case code.OpCaptureLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			// The local is boxed the first time a closure captures it, from then
+			// on the frame and all closures share the cell
+			slot := &v.stack[f.basePointer+int(localIndex)]
+			c, ok := (*slot).(*cell)
+			if !ok {
+				c = &cell{value: *slot}
+				*slot = c
+			}
+			if err := v.push(c); err != nil {
+				return nil, err
+			}
+
+		case code.OpCaptureFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			f.ip += 1
+
+			if err := v.push(f.cl.Free[freeIndex]); err != nil {
+				return nil, err
+			}
+
+		case code.OpArray:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			f.ip += 2
+
+			elements := make([]object.Object, numElements)
+			copy(elements, v.stack[v.sp-numElements:v.sp])
+			v.sp -= numElements
+
+			if err := v.pushAllocated(&object.Array{Elements: elements}); err != nil {
+				return nil, err
+			}
+
+		case code.OpInterpolate:
+			numParts := int(code.ReadUint16(ins[ip+1:]))
+			f.ip += 2
+
+			var str strings.Builder
+			for _, part := range v.stack[v.sp-numParts : v.sp] {
+				str.WriteString(part.Inspect())
+			}
+			v.sp -= numParts
+
+			if err := v.pushAllocated(&object.String{Value: str.String()}); err != nil {
+				return nil, err
+			}
+
+		case code.OpHash:
+			numPairs := int(code.ReadUint16(ins[ip+1:]))
+			f.ip += 2
+
+			hashMap, err := v.buildHashMap(v.sp-numPairs*2, v.sp)
+			if err != nil {
+				return nil, err
+			}
+			v.sp -= numPairs * 2
+
+			if err := v.pushAllocated(hashMap); err != nil {
+				return nil, err
+			}
+
+		case code.OpIndex:
+			index := v.pop()
+			indexed := v.pop()
+
+			result := evaluator.EvalIndex(indexed, index)
+			if err, ok := result.(*object.Error); ok {
+				return nil, err
+			}
+			if err := v.push(result); err != nil {
+				return nil, err
+			}
+
+		case code.OpSlice:
+			end := v.pop()
+			start := v.pop()
+			sliced := v.pop()
+
+			result := evaluator.EvalSlice(sliced, start, end)
+			if err, ok := result.(*object.Error); ok {
+				return nil, err
+			}
+			if err := v.pushAllocated(result); err != nil {
+				return nil, err
+			}
+
+		case code.OpSetIndex:
+			value := v.pop()
+			index := v.pop()
+			indexed := v.pop()
+
+			result := evaluator.AssignIndexWithBudget(indexed, index, value, v.budget)
+			if err, ok := result.(*object.Error); ok {
+				return nil, err
+			}
+
+		case code.OpCall:
+			numArgs := int(code.ReadUint8(ins[ip+1:]))
+			f.ip += 1
+
+			if err := v.executeCall(numArgs); err != nil {
+				return nil, err
+			}
+
+		case code.OpReturnValue:
+			returnValue := v.pop()
+
+			if v.framesIndex == 1 {
+				return returnValue, nil
+			}
+
+			f := v.popFrame()
+			// also drops the function being called which sits below the locals
+			v.sp = f.basePointer - 1
+
+			if v.framesIndex == v.returnDepth {
+				return returnValue, nil
+			}
+
+			if err := v.push(returnValue); err != nil {
+				return nil, err
+			}
+
+		case code.OpImport:
+			path := v.pop()
+
+			pathString, ok := path.(*object.String)
+			if !ok {
+				return nil, newError(object.TypeError, "import path must be a STRING, got %s", path.Type())
+			}
+
+			result := evaluator.ImportModule(pathString.Value, f.cl.Unit.Context)
+			if err, ok := result.(*object.Error); ok {
+				return nil, err
+			}
+			if err := v.push(result); err != nil {
+				return nil, err
+			}
+
+		case code.OpSetupCatch, code.OpSetupFinally:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			f.ip += 2
+
+			v.handlers = append(v.handlers, handler{
+				ip:          pos,
+				framesIndex: v.framesIndex,
+				sp:          v.sp,
+				finally:     op == code.OpSetupFinally,
+			})
+
+		case code.OpPopHandler:
+			v.handlers = v.handlers[:len(v.handlers)-1]
+
+		case code.OpThrow:
+			value := v.pop()
+
+			// finally blocks raise the error they received again
+			if err, ok := value.(*object.Error); ok {
+				return nil, err
+			}
+			return nil, evaluator.Throw(value)
+
+		case code.OpClosure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			numFree := int(code.ReadUint8(ins[ip+3:]))
+			f.ip += 3
+
+			if err := v.pushClosure(int(constIndex), numFree); err != nil {
+				return nil, err
+			}
+
+		default:
+			def, err := code.Lookup(byte(op))
+			if err != nil {
+				return nil, newError(object.InternalError, "%s", err)
+			}
+			return nil, newError(object.InternalError, "unhandled opcode %s", def.Name)
+		}
+	}
+}
+
+func (v *vm) executeInfixOperation(op code.Opcode) *object.Error {
+	right := v.pop()
+	left := v.pop()
+
+	// fast path for the most common case, avoids the generic dispatch
+	if leftInt, ok := left.(*object.Integer); ok {
+		if rightInt, ok := right.(*object.Integer); ok {
+			if result, ok := integerInfixOperation(op, leftInt.Value, rightInt.Value); ok {
+				return v.push(result)
+			}
+		}
+	}
+
+	result := evaluator.EvalInfixOperatorWithBudget(infixOperators[op], left, right, v.budget)
+	if err, ok := result.(*object.Error); ok {
+		return err
+	}
+
+	return v.push(result)
+}
+
+// integerInfixOperation returns false for cases that must go through the
+// evaluator, e.g. to report division by zero
+func integerInfixOperation(op code.Opcode, left int64, right int64) (object.Object, bool) {
+	switch op {
+	case code.OpAdd:
+		result, ok := evaluator.CheckedAdd(left, right)
+		return newInteger(result), ok
+	case code.OpSub:
+		result, ok := evaluator.CheckedSub(left, right)
+		return newInteger(result), ok
+	case code.OpMul:
+		result, ok := evaluator.CheckedMul(left, right)
+		return newInteger(result), ok
+	case code.OpBitAnd:
+		return newInteger(left & right), true
+	case code.OpBitOr:
+		return newInteger(left | right), true
+	case code.OpBitXor:
+		return newInteger(left ^ right), true
+	case code.OpEqual:
+		return nativeBoolToBooleanObject(left == right), true
+	case code.OpNotEqual:
+		return nativeBoolToBooleanObject(left != right), true
+	case code.OpGreaterThan:
+		return nativeBoolToBooleanObject(left > right), true
+	case code.OpGreaterEqual:
+		return nativeBoolToBooleanObject(left >= right), true
+	case code.OpLessThan:
+		return nativeBoolToBooleanObject(left < right), true
+	case code.OpLessEqual:
+		return nativeBoolToBooleanObject(left <= right), true
+	default:
+		return nil, false
+	}
+}
+
+func (v *vm) buildHashMap(startIndex int, endIndex int) (object.Object, *object.Error) {
+	hashMap := object.NewHashMap()
+
+	for idx := startIndex; idx < endIndex; idx += 2 {
+		key := v.stack[idx]
+		value := v.stack[idx+1]
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return nil, newError(object.TypeError, "unusable as hash key: %s", key.Type())
+		}
+
+		hashMap.Set(hashKey.Hash(), object.HashPair{Key: key, Value: value})
+	}
+
+	return hashMap, nil
+}
+
+func (v *vm) executeCall(numArgs int) *object.Error {
+	callee := v.stack[v.sp-1-numArgs]
+
+	switch callee := callee.(type) {
+	case *object.Closure:
+		return v.callClosure(callee, numArgs)
+	case *object.Builtin:
+		if err := evaluator.CheckCapability(callee, v.currentFrame().cl.Unit.Context); err != nil {
+			return err
+		}
+		args := v.stack[v.sp-numArgs : v.sp]
+
+		result := callee.Fn(v, args...)
+		v.sp = v.sp - numArgs - 1
+
+		if err, ok := result.(*object.Error); ok {
+			return err
+		}
+		return v.pushAllocated(result)
+	default:
+		return newError(object.TypeError, "not a function: %s", callee.Type())
+	}
+}
+
+// Call runs a function for a builtin, which is then on top of the stack with
+// its arguments below the stack pointer
+func (v *vm) Call(fn object.Object, args ...object.Object) object.Object {
+	depth, sp := v.framesIndex, v.sp
+
+	switch fn := fn.(type) {
+	case *object.Closure:
+		for _, obj := range append([]object.Object{fn}, args...) {
+			if err := v.push(obj); err != nil {
+				v.sp = sp
+				return err
+			}
+		}
+		if err := v.callClosure(fn, len(args)); err != nil {
+			v.sp = sp
+			return err
+		}
+
+		result := v.runUntilReturn(depth)
+		if _, ok := result.(*object.Error); ok {
+			// unwinds the failed call, the builtin returns the error which
+			// is then raised where the builtin was called
+			v.framesIndex, v.sp = depth, sp
+		}
+		return result
+	case *object.Builtin:
+		if err := evaluator.CheckCapability(fn, v.currentFrame().cl.Unit.Context); err != nil {
+			return err
+		}
+		return fn.Fn(v, args...)
+	default:
+		return newError(object.TypeError, "not a function: %s", fn.Type())
+	}
+}
+
+func (v *vm) Streams() *object.Streams {
+	return v.currentFrame().cl.Unit.Context.ProgramStreams()
+}
+
+func (v *vm) CheckAllocation(size int64) *object.Error {
+	if v.budget != nil {
+		return v.budget.CheckAllocation(size)
+	}
+	return nil
+}
+
+func (v *vm) callClosure(cl *object.Closure, numArgs int) *object.Error {
+	fn := cl.Fn
+	if err := evaluator.ArityError(fn.NumRequired, fn.NumParameters, fn.Variadic, numArgs); err != nil {
+		return err
+	}
+
+	// the main function doesn't count as a call
+	if v.framesIndex > v.maxFrames {
+		return newError(object.StackOverflowError, "stack overflow: maximum call depth of %d exceeded", v.maxFrames)
+	}
+
+	basePointer := v.sp - numArgs
+	if err := v.reserve(basePointer + max(fn.NumLocals, numArgs)); err != nil {
+		return err
+	}
+
+	// Parameters without an argument stay unbound until their default is
+	// evaluated, like all other locals until their declaration
+	for idx := numArgs; idx < fn.NumParameters; idx++ {
+		v.stack[basePointer+idx] = nil
+	}
+
+	firstLocal := fn.NumParameters
+	if fn.Variadic {
+		rest := []object.Object{}
+		if numArgs > fn.NumParameters {
+			rest = make([]object.Object, numArgs-fn.NumParameters)
+			copy(rest, v.stack[basePointer+fn.NumParameters:v.sp])
+		}
+		v.stack[basePointer+fn.NumParameters] = &object.Array{Elements: rest}
+		firstLocal++
+	}
+
+	for idx := firstLocal; idx < fn.NumLocals; idx++ {
+		v.stack[basePointer+idx] = nil
+	}
+
+	v.pushFrame(newFrame(cl, basePointer))
+	v.sp = basePointer + fn.NumLocals
+
+	return nil
+}
+
+func (v *vm) pushClosure(constIndex int, numFree int) *object.Error {
+	unit := v.currentFrame().cl.Unit
+
+	function, ok := unit.Constants[constIndex].(*object.CompiledFunction)
+	if !ok {
+		return newError(object.TypeError, "not a function: %s", unit.Constants[constIndex].Type())
+	}
+
+	free := make([]object.Object, numFree)
+	copy(free, v.stack[v.sp-numFree:v.sp])
+	v.sp -= numFree
+
+	return v.push(&object.Closure{Fn: function, Free: free, Unit: unit})
+}
+
+func (v *vm) push(obj object.Object) *object.Error {
+	if err := v.reserve(v.sp + 1); err != nil {
+		return err
+	}
+
+	v.stack[v.sp] = obj
+	v.sp++
+
+	return nil
+}
+
+// reserve grows the stack to hold at least size values. Slices of the stack
+// that builtins hold on to, like their arguments, keep pointing at the old one.
+func (v *vm) reserve(size int) *object.Error {
+	if size <= len(v.stack) {
+		return nil
+	}
+	if size > MaxStackSize {
+		return newError(object.StackOverflowError, "stack overflow")
+	}
+
+	stack := make([]object.Object, min(max(size, 2*len(v.stack)), MaxStackSize))
+	copy(stack, v.stack[:v.sp])
+	v.stack = stack
+	return nil
+}
+
+// pushAllocated pushes an object the run created once its memory is
+// accounted for
+func (v *vm) pushAllocated(obj object.Object) *object.Error {
+	result := evaluator.Allocate(obj, v.budget)
+	if err, ok := result.(*object.Error); ok {
+		return err
+	}
+	return v.push(result)
+}
+
+func (v *vm) pop() object.Object {
+	obj := v.stack[v.sp-1]
+	v.sp--
+	return obj
+}
+
+func (v *vm) currentFrame() *frame {
+	return v.frames[v.framesIndex-1]
+}
+
+func (v *vm) pushFrame(f *frame) {
+	if v.framesIndex == len(v.frames) {
+		v.frames = append(v.frames, f)
+	} else {
+		v.frames[v.framesIndex] = f
+	}
+	v.framesIndex++
+}
+
+func (v *vm) popFrame() *frame {
+	v.framesIndex--
+	return v.frames[v.framesIndex]
+}
+
+func newInteger(value int64) *object.Integer {
+	if value >= minCachedInteger && value <= maxCachedInteger {
+		return integerCache[value-minCachedInteger]
+	}
+	return &object.Integer{Value: value}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return evaluator.TRUE
+	}
+	return evaluator.FALSE
+}
+
+func newError(kind object.ErrorKind, messageTemplate string, args ...any) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(messageTemplate, args...)}
+}
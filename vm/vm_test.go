@@ -0,0 +1,334 @@
+package vm_test
+
+import (
+	"context"
+	"taulang/compiler"
+	"taulang/lexer"
+	"taulang/object"
+	"taulang/parser"
+	"taulang/token"
+	"taulang/vm"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Most of the language is covered by the evaluator tests which are also run
+// against the vm, these cover vm specific behaviour
+func TestVM(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedObject object.Object
+	}{
+		{
+			name: "success - closures see later updates of captured locals",
+			input: `sun_liyo_tau outer ne_bana_diye tau_ka_jugaad() {
+				sun_liyo_tau x ne_bana_diye 1;
+				sun_liyo_tau get ne_bana_diye tau_ka_jugaad() { x };
+				x ne_bana_diye 2;
+				get();
+			};
+			outer();`,
+			expectedObject: &object.Integer{Value: 2},
+		},
+		{
+			name: "success - nested closures capture through free variables",
+			input: `sun_liyo_tau adder ne_bana_diye tau_ka_jugaad(a) {
+				tau_ka_jugaad(b) { tau_ka_jugaad(c) { a + b + c } }
+			};
+			adder(1)(2)(3);`,
+			expectedObject: &object.Integer{Value: 6},
+		},
+		{
+			name: "success - recursion",
+			input: `sun_liyo_tau fib ne_bana_diye tau_ka_jugaad(n) {
+				agar_maan_lo (n < 2) { laadle_ye_le n; };
+				fib(n - 1) + fib(n - 2);
+			};
+			fib(15);`,
+			expectedObject: &object.Integer{Value: 610},
+		},
+		{
+			name: "success - break out of the middle of an expression",
+			input: `sun_liyo_tau i ne_bana_diye 0;
+			jab_tak (saccha) {
+				i ne_bana_diye i + 1;
+				[1, agar_maan_lo (i > 3) { rok_diye; } na_toh { 2 }];
+			};
+			i;`,
+			expectedObject: &object.Integer{Value: 4},
+		},
+		{
+			name: "success - rest parameter with more arguments than locals",
+			input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a, ...rest) { rest };
+			f(1, 2, 3, 4, 5);`,
+			expectedObject: &object.Array{Elements: []object.Object{
+				&object.Integer{Value: 2},
+				&object.Integer{Value: 3},
+				&object.Integer{Value: 4},
+				&object.Integer{Value: 5},
+			}},
+		},
+		{
+			name: "success - default parameter captured by closure in earlier default",
+			input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(get ne_bana_diye tau_ka_jugaad() { b }, b ne_bana_diye 2) { get() };
+			f();`,
+			expectedObject: &object.Integer{Value: 2},
+		},
+		{
+			name:           "failure - arity is checked before the call",
+			input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a, b) { b }; f(1);",
+			expectedObject: &object.Error{Message: "wrong number of arguments: expected 2, got 1"},
+		},
+		{
+			name:           "failure - unbounded recursion",
+			input:          "sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { f() }; f();",
+			expectedObject: &object.Error{Message: "stack overflow: maximum call depth of 10000 exceeded"},
+		},
+		{
+			name: "success - catching unwinds the frames",
+			input: `sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { f() };
+			sun_liyo_tau kind ne_bana_diye koshish_karo { f() } pakad_lo (e) { e.kind };
+			sun_liyo_tau g ne_bana_diye tau_ka_jugaad(x) { x + 1 };
+			[kind, g(1)];`,
+			expectedObject: &object.Array{Elements: []object.Object{
+				&object.String{Value: "StackOverflowError"},
+				&object.Integer{Value: 2},
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := run(t, tc.input)
+
+			if expectedError, ok := tc.expectedObject.(*object.Error); ok {
+				actualError, ok := o.(*object.Error)
+				assert.True(t, ok, "expected error, got %s", o.Inspect())
+				if ok {
+					assert.Equal(t, expectedError.Message, actualError.Message)
+				}
+				return
+			}
+
+			assert.Equal(t, tc.expectedObject, o)
+		})
+	}
+}
+
+func TestVMErrorPositions(t *testing.T) {
+	t.Parallel()
+
+	input := `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(x) {
+	laadle_ye_le -x;
+};
+f("tau");`
+
+	o := run(t, input)
+	assert.Equal(t, &object.Error{
+		Kind:     object.TypeError,
+		Message:  "unknown operator: -STRING",
+		Position: token.Position{Line: 2, Column: 15, Offset: 61},
+		Stack: []object.StackFrame{
+			{Function: "f", Position: token.Position{Line: 2, Column: 15, Offset: 61}},
+			{Position: token.Position{Line: 4, Column: 2, Offset: 69}},
+		},
+	}, o)
+}
+
+func TestVMErrorStack(t *testing.T) {
+	t.Parallel()
+
+	input := `sun_liyo_tau apply ne_bana_diye tau_ka_jugaad(f) { f() };
+sun_liyo_tau outer ne_bana_diye tau_ka_jugaad() { apply(tau_ka_jugaad() { 1 / 0 }) };
+outer();`
+
+	o := run(t, input)
+	assert.Equal(t, &object.Error{
+		Kind:     object.ZeroDivisionError,
+		Message:  "division by zero",
+		Position: token.Position{Line: 2, Column: 77, Offset: 134},
+		Stack: []object.StackFrame{
+			{Position: token.Position{Line: 2, Column: 77, Offset: 134}},
+			{Function: "apply", Position: token.Position{Line: 1, Column: 53, Offset: 52}},
+			{Function: "outer", Position: token.Position{Line: 2, Column: 56, Offset: 113}},
+			{Position: token.Position{Line: 3, Column: 6, Offset: 149}},
+		},
+	}, o)
+}
+
+func TestVMErrorStackOfDefaults(t *testing.T) {
+	t.Parallel()
+
+	input := `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(a ne_bana_diye 1 / 0) { a };
+f();`
+
+	o := run(t, input)
+	assert.Equal(t, &object.Error{
+		Kind:     object.ZeroDivisionError,
+		Message:  "division by zero",
+		Position: token.Position{Line: 1, Column: 60, Offset: 59},
+		Stack: []object.StackFrame{
+			{Function: "f", Position: token.Position{Line: 1, Column: 60, Offset: 59}},
+			{Position: token.Position{Line: 2, Column: 2, Offset: 72}},
+		},
+	}, o)
+}
+
+func TestVMRethrowKeepsPositionAndStack(t *testing.T) {
+	t.Parallel()
+
+	input := `sun_liyo_tau inner ne_bana_diye tau_ka_jugaad(x) { x / 0 };
+sun_liyo_tau outer ne_bana_diye tau_ka_jugaad(x) { koshish_karo { inner(x) } pakad_lo (e) { fenk_do e } };
+outer(1);`
+
+	o := run(t, input)
+	assert.Equal(t, &object.Error{
+		Kind:     object.ZeroDivisionError,
+		Message:  "division by zero",
+		Position: token.Position{Line: 1, Column: 54, Offset: 53},
+		Stack: []object.StackFrame{
+			{Function: "inner", Position: token.Position{Line: 1, Column: 54, Offset: 53}},
+			{Function: "outer", Position: token.Position{Line: 2, Column: 72, Offset: 131}},
+			{Position: token.Position{Line: 3, Column: 6, Offset: 172}},
+		},
+	}, o)
+}
+
+func TestVMBuiltinCallbackErrorStack(t *testing.T) {
+	t.Parallel()
+
+	input := `sun_liyo_tau inv ne_bana_diye tau_ka_jugaad(x) { 1 / x };
+sun_liyo_tau f ne_bana_diye tau_ka_jugaad() { map([1, 0], inv) };
+f();`
+
+	o := run(t, input)
+	assert.Equal(t, &object.Error{
+		Kind:     object.ZeroDivisionError,
+		Message:  "division by zero",
+		Position: token.Position{Line: 1, Column: 52, Offset: 51},
+		Stack: []object.StackFrame{
+			{Function: "inv", Position: token.Position{Line: 1, Column: 52, Offset: 51}},
+			{Function: "f", Position: token.Position{Line: 2, Column: 50, Offset: 107}},
+			{Position: token.Position{Line: 3, Column: 2, Offset: 125}},
+		},
+	}, o)
+}
+
+func TestVMGlobalsState(t *testing.T) {
+	t.Parallel()
+
+	symbolTable := compiler.NewSymbolTable()
+	constants := []object.Object{}
+	globals := make([]object.Object, vm.GlobalsSize)
+
+	var o object.Object
+	for _, input := range []string{"sun_liyo_tau x ne_bana_diye 40;", "x + 2;"} {
+		l, err := lexer.NewLexer(input)
+		assert.NoError(t, err)
+		p := parser.NewParser(l)
+		program := p.Parse()
+		assert.Empty(t, p.Errors())
+
+		c := compiler.NewCompilerWithState(symbolTable, constants)
+		assert.NoError(t, c.Compile(program))
+		bytecode := c.Bytecode()
+		constants = bytecode.Constants
+
+		o = vm.NewVMWithState(bytecode, globals, nil).Run()
+	}
+
+	assert.Equal(t, &object.Integer{Value: 42}, o)
+}
+
+func TestVMLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   object.Limits
+		timeout  time.Duration
+		input    string
+		expected string
+	}{
+		{
+			name:     "timeout can't be caught",
+			timeout:  10 * time.Millisecond,
+			input:    `jab_tak (saccha) { koshish_karo { jab_tak (saccha) { } } pakad_lo (e) { } }`,
+			expected: "execution interrupted: context deadline exceeded",
+		},
+		{
+			name:     "step limit",
+			limits:   object.Limits{MaxSteps: 1000},
+			input:    `jab_tak (saccha) { }`,
+			expected: "step limit of 1000 exceeded",
+		},
+		{
+			name:     "call depth",
+			limits:   object.Limits{MaxDepth: 50},
+			input:    `sun_liyo_tau f ne_bana_diye tau_ka_jugaad(n) { f(n + 1) }; f(0)`,
+			expected: "stack overflow: maximum call depth of 50 exceeded",
+		},
+		{
+			name:     "allocation of arrays",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `sun_liyo_tau a ne_bana_diye []; jab_tak (saccha) { a ne_bana_diye [a, a, a, a]; }`,
+			expected: "allocation limit of 1048576 bytes exceeded",
+		},
+		{
+			name:     "allocation of strings",
+			limits:   object.Limits{MaxAllocation: 1 << 20},
+			input:    `sun_liyo_tau s ne_bana_diye "ab"; jab_tak (saccha) { s += s; }`,
+			expected: "allocation limit of 1048576 bytes exceeded",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			moduleContext := vm.NewModuleContext("")
+			moduleContext.Budget = object.NewBudget(tc.limits)
+
+			ctx := context.Background()
+			if tc.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			bytecode := compile(t, tc.input)
+			o := vm.NewVMWithState(bytecode, make([]object.Object, vm.GlobalsSize), moduleContext).RunContext(ctx)
+
+			err, ok := o.(*object.Error)
+			assert.True(t, ok, "expected error, got %s", o.Inspect())
+			if ok {
+				assert.Equal(t, tc.expected, err.Message)
+			}
+		})
+	}
+}
+
+func run(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	return vm.NewVM(compile(t, input)).Run()
+}
+
+func compile(t *testing.T, input string) *compiler.Bytecode {
+	t.Helper()
+
+	l, err := lexer.NewLexer(input)
+	assert.NoError(t, err)
+
+	p := parser.NewParser(l)
+	program := p.Parse()
+	assert.Empty(t, p.Errors())
+
+	c := compiler.NewCompiler()
+	assert.NoError(t, c.Compile(program))
+
+	return c.Bytecode()
+}